@@ -244,8 +244,8 @@ func composeContainerPrintableTab(ctx context.Context, container containerd.Cont
 		return composeContainerPrintable{}, err
 	}
 	status := formatter.ContainerStatus(ctx, container)
-	if status == "Up" {
-		status = "running" // corresponds to Docker Compose v2.0.1
+	if health, ok := strings.CutPrefix(status, "Up"); ok {
+		status = "running" + health // corresponds to Docker Compose v2.0.1
 	}
 	image, err := container.Image(ctx)
 	if err != nil {
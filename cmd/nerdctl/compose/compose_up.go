@@ -51,6 +51,7 @@ func upCommand() *cobra.Command {
 	cmd.Flags().Bool("no-recreate", false, "Don't recreate containers if they exist, conflict with --force-recreate.")
 	cmd.Flags().StringArray("scale", []string{}, "Scale SERVICE to NUM instances. Overrides the `scale` setting in the Compose file if present.")
 	cmd.Flags().String("pull", "", "Pull image before running (\"always\"|\"missing\"|\"never\")")
+	cmd.Flags().Duration("dependency-timeout", composer.DefaultDependencyTimeout, "Maximum time to wait for the depends_on conditions (service_healthy, service_completed_successfully) of each service. 0 means no timeout.")
 	return cmd
 }
 
@@ -117,6 +118,10 @@ func upAction(cmd *cobra.Command, services []string) error {
 	if err != nil {
 		return err
 	}
+	dependencyTimeout, err := cmd.Flags().GetDuration("dependency-timeout")
+	if err != nil {
+		return err
+	}
 	if forceRecreate && noRecreate {
 		return errors.New("flag --force-recreate and --no-recreate cannot be specified together")
 	}
@@ -162,6 +167,7 @@ func upAction(cmd *cobra.Command, services []string) error {
 		Pull:                 pull,
 		ForceRecreate:        forceRecreate,
 		NoRecreate:           noRecreate,
+		DependencyTimeout:    dependencyTimeout,
 	}
 	return c.Up(ctx, uo, services)
 }
@@ -646,3 +646,75 @@ services:
 	base.Cmd("images").AssertOutNotContains(testutil.CommonImage)
 	base.ComposeCmd("-f", comp.YAMLFullPath(), "up").AssertExitCode(1)
 }
+
+func TestComposeUpDependsOnCompletedSuccessfully(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "sh -c 'sleep 3 && echo done'"
+  app:
+    image: %[1]s
+    command: "sleep infinity"
+    depends_on:
+      init:
+        condition: service_completed_successfully
+`, testutil.AlpineImage)
+
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+	projectName := comp.ProjectName()
+	t.Logf("projectName=%q", projectName)
+
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "up", "-d").AssertOK()
+	defer base.ComposeCmd("-f", comp.YAMLFullPath(), "down", "-v").Run()
+
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "ps", "init", "-a").AssertOutContains("Exited (0)")
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "ps", "app").AssertOutContains("Up")
+}
+
+func TestComposeUpDependsOnFailure(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "false"
+  app:
+    image: %[1]s
+    command: "sleep infinity"
+    depends_on:
+      init:
+        condition: service_completed_successfully
+`, testutil.AlpineImage)
+
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+	defer base.ComposeCmd("-f", comp.YAMLFullPath(), "down", "-v").Run()
+
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "up", "-d").AssertFail()
+
+	var optionalYAML = fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "false"
+  app:
+    image: %[1]s
+    command: "sleep infinity"
+    depends_on:
+      init:
+        condition: service_completed_successfully
+        required: false
+`, testutil.AlpineImage)
+
+	optionalComp := testutil.NewComposeDir(t, optionalYAML)
+	defer optionalComp.CleanUp()
+	defer base.ComposeCmd("-f", optionalComp.YAMLFullPath(), "down", "-v").Run()
+
+	base.ComposeCmd("-f", optionalComp.YAMLFullPath(), "up", "-d").AssertOK()
+	base.ComposeCmd("-f", optionalComp.YAMLFullPath(), "ps", "app").AssertOutContains("Up")
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+)
+
+func TestComposeWatchSync(t *testing.T) {
+	base := testutil.NewBase(t)
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  svc0:
+    image: %s
+    command: ["sleep", "infinity"]
+    develop:
+      watch:
+        - path: ./src
+          action: sync
+          target: /app
+          ignore:
+            - "*.tmp"
+`, testutil.CommonImage)
+
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+	assert.NilError(t, os.Mkdir(filepath.Join(comp.Dir(), "src"), 0755))
+	projectName := comp.ProjectName()
+	t.Logf("projectName=%q", projectName)
+
+	res := base.ComposeCmd("-f", comp.YAMLFullPath(), "watch").Start()
+	defer base.ComposeCmd("-f", comp.YAMLFullPath(), "down").Run()
+	defer res.Cmd.Process.Kill()
+
+	waitFor := func(args []string, expected string) {
+		t.Helper()
+		var out string
+		for i := 0; i < 30; i++ {
+			out = base.ComposeCmd(append([]string{"-f", comp.YAMLFullPath()}, args...)...).Run().Stdout()
+			if strings.Contains(out, expected) {
+				return
+			}
+			time.Sleep(time.Second)
+		}
+		t.Fatalf("expected %q in the output of %v, got %q", expected, args, out)
+	}
+	waitFor([]string{"ps", "svc0"}, "running")
+
+	comp.WriteFile("src/hello.txt", "hello")
+	comp.WriteFile("src/ignored.tmp", "ignored")
+	waitFor([]string{"exec", "svc0", "cat", "/app/hello.txt"}, "hello")
+	base.ComposeCmd("-f", comp.YAMLFullPath(), "exec", "svc0", "ls", "/app").AssertOutNotContains("ignored.tmp")
+}
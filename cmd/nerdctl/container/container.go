@@ -50,10 +50,13 @@ func Command() *cobra.Command {
 		WaitCommand(),
 		UnpauseCommand(),
 		CommitCommand(),
+		ExportCommand(),
 		RenameCommand(),
 		pruneCommand(),
 		StatsCommand(),
 		AttachCommand(),
+		HealthCheckCommand(),
+		CheckpointCommand(),
 	)
 	AddCpCommand(cmd)
 	return cmd
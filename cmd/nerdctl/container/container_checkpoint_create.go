@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func checkpointCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "create [flags] CONTAINER CHECKPOINT",
+		Args:              helpers.IsExactArgs(2),
+		Short:             "Create a checkpoint from a running container",
+		Long:              "Create a checkpoint from a running container with CRIU.\nThe checkpoint is stored as an image, which can be tagged and pushed like any other image.",
+		RunE:              checkpointCreateAction,
+		ValidArgsFunction: checkpointCreateShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().Bool("leave-running", false, "Leave the container running after checkpoint")
+	return cmd
+}
+
+func checkpointCreateOptions(cmd *cobra.Command) (types.ContainerCheckpointCreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerCheckpointCreateOptions{}, err
+	}
+	leaveRunning, err := cmd.Flags().GetBool("leave-running")
+	if err != nil {
+		return types.ContainerCheckpointCreateOptions{}, err
+	}
+	return types.ContainerCheckpointCreateOptions{
+		Stdout:       cmd.OutOrStdout(),
+		GOptions:     globalOptions,
+		LeaveRunning: leaveRunning,
+	}, nil
+}
+
+func checkpointCreateAction(cmd *cobra.Command, args []string) error {
+	options, err := checkpointCreateOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.CheckpointCreate(ctx, client, args[0], args[1], options)
+}
+
+func checkpointCreateShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// show running container names
+	statusFilterFn := func(st containerd.ProcessStatus) bool {
+		return st == containerd.Running
+	}
+	return completion.ContainerNames(cmd, statusFilterFn)
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestContainerCheckpoint(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		nerdtest.Rootful,
+		require.Binary("criu"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-c", "i=0; while true; do echo $i > /tmp/counter; i=$((i+1)); sleep 1; done")
+		helpers.Ensure("container", "checkpoint", "create", data.Identifier(), "cp1")
+		data.Labels().Set("container", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("container", "checkpoint", "rm", data.Identifier(), "cp1")
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "checkpoint stops the container",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.State.Running}}", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("false\n")),
+		},
+		{
+			Description: "checkpoint is listed",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "checkpoint", "ls", "-q", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("cp1\n")),
+		},
+		{
+			Description: "start from checkpoint",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("start", "--checkpoint", "cp1", data.Labels().Get("container"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// The counter file lives in the rootfs, which is restored from the checkpoint
+				return helpers.Command("exec", data.Labels().Get("container"), "cat", "/tmp/counter")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Match(regexp.MustCompile(`^[0-9]+\n$`))),
+		},
+		{
+			Description: "start from checkpoint refuses a running container",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("start", "--checkpoint", "cp1", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("stop it first")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func checkpointListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "ls [flags] CONTAINER",
+		Aliases:           []string{"list"},
+		Args:              helpers.IsExactArgs(1),
+		Short:             "List checkpoints for a container",
+		RunE:              checkpointListAction,
+		ValidArgsFunction: checkpointListShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().BoolP("quiet", "q", false, "Only display checkpoint names")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func checkpointListOptions(cmd *cobra.Command) (types.ContainerCheckpointListOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerCheckpointListOptions{}, err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return types.ContainerCheckpointListOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ContainerCheckpointListOptions{}, err
+	}
+	return types.ContainerCheckpointListOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Quiet:    quiet,
+		Format:   format,
+	}, nil
+}
+
+func checkpointListAction(cmd *cobra.Command, args []string) error {
+	options, err := checkpointListOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.CheckpointList(ctx, client, args[0], options)
+}
+
+func checkpointListShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// show container names
+	return completion.ContainerNames(cmd, nil)
+}
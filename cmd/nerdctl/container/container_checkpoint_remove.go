@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func checkpointRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rm [flags] CONTAINER CHECKPOINT [CHECKPOINT, ...]",
+		Aliases:           []string{"remove"},
+		Args:              cobra.MinimumNArgs(2),
+		Short:             "Remove one or more checkpoints of a container",
+		RunE:              checkpointRemoveAction,
+		ValidArgsFunction: checkpointRemoveShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func checkpointRemoveOptions(cmd *cobra.Command) (types.ContainerCheckpointRemoveOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerCheckpointRemoveOptions{}, err
+	}
+	return types.ContainerCheckpointRemoveOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	}, nil
+}
+
+func checkpointRemoveAction(cmd *cobra.Command, args []string) error {
+	options, err := checkpointRemoveOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.CheckpointRemove(ctx, client, args[0], args[1:], options)
+}
+
+func checkpointRemoveShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// show container names
+	return completion.ContainerNames(cmd, nil)
+}
@@ -229,6 +229,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 			opt.Device = append(opt.Device, device)
 		}
 	}
+	opt.DeviceCgroupRules, err = cmd.Flags().GetStringArray("device-cgroup-rule")
+	if err != nil {
+		return opt, err
+	}
 	// #endregion
 
 	// #region for blkio flags
@@ -445,6 +449,44 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	}
 	// #endregion
 
+	// #region for healthcheck flags
+	opt.HealthCmd, err = cmd.Flags().GetString("health-cmd")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthInterval, err = cmd.Flags().GetDuration("health-interval")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthTimeout, err = cmd.Flags().GetDuration("health-timeout")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthStartPeriod, err = cmd.Flags().GetDuration("health-start-period")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthStartInterval, err = cmd.Flags().GetDuration("health-start-interval")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthRetries, err = cmd.Flags().GetInt("health-retries")
+	if err != nil {
+		return opt, err
+	}
+	opt.NoHealthcheck, err = cmd.Flags().GetBool("no-healthcheck")
+	if err != nil {
+		return opt, err
+	}
+	if opt.NoHealthcheck {
+		for _, f := range []string{"health-cmd", "health-interval", "health-timeout", "health-start-period", "health-start-interval", "health-retries"} {
+			if cmd.Flags().Changed(f) {
+				return opt, fmt.Errorf("--no-healthcheck conflicts with --%s", f)
+			}
+		}
+	}
+	// #endregion
+
 	// #region for image pull and verify options
 	imageVerifyOpt, err := helpers.VerifyOptions(cmd)
 	if err != nil {
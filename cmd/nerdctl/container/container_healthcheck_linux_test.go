@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestContainerHealthCheck(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "healthy",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				// Use a long interval so that the scheduled checks do not interfere with the test
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "echo ok", "--health-interval", "1h",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("container", "healthcheck", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.State.Health.Status}} {{(index .State.Health.Log 0).Output}}", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("healthy ok")),
+		},
+		{
+			Description: "exec form",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				// a shell would expand the variable
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", `["echo", "$HOME"]`, "--health-interval", "1h",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("container", "healthcheck", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{json .Config.Healthcheck.Test}} {{(index .State.Health.Log 0).Output}}", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains(`["CMD","echo","$HOME"] $HOME`)),
+		},
+		{
+			Description: "unhealthy",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "exit 1", "--health-interval", "1h", "--health-retries", "1",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "healthcheck", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeGenericFail,
+					Errors:   []error{errors.New("is unhealthy")},
+					Output: func(stdout string, info string, t *testing.T) {
+						helpers.Command("ps", "--filter", "name="+data.Identifier()).
+							Run(&test.Expected{Output: expect.Contains("(unhealthy)")})
+					},
+				}
+			},
+		},
+		{
+			Description: "no-healthcheck conflicts with health flags",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--no-healthcheck", "--health-cmd", "true", testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("conflicts")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
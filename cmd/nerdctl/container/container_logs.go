@@ -18,6 +18,7 @@ package container
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 
 	"github.com/spf13/cobra"
@@ -27,20 +28,24 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
 )
 
 func LogsCommand() *cobra.Command {
-	const shortUsage = "Fetch the logs of a container. Expected to be used with 'nerdctl run -d'."
-	const longUsage = `Fetch the logs of a container.
+	const shortUsage = "Fetch the logs of containers. Expected to be used with 'nerdctl run -d'."
+	const longUsage = `Fetch the logs of containers.
 
 The following containers are supported:
 - Containers created with 'nerdctl run -d'. The log is currently empty for containers created without '-d'.
 - Containers created with 'nerdctl compose'.
 - Containers created with Kubernetes (EXPERIMENTAL).
+
+When multiple containers are selected, by name or with --filter, their logs are merged in timestamp order
+and prefixed with the container names. With --follow, the selected containers that start later are followed too.
 `
 	var cmd = &cobra.Command{
-		Use:               "logs [flags] CONTAINER",
-		Args:              helpers.IsExactArgs(1),
+		Use:               "logs [flags] [CONTAINER...]",
+		Args:              cobra.ArbitraryArgs,
 		Short:             shortUsage,
 		Long:              longUsage,
 		RunE:              logsAction,
@@ -54,6 +59,18 @@ The following containers are supported:
 	cmd.Flags().String("since", "", "Show logs since timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
 	cmd.Flags().String("until", "", "Show logs before a timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
 	cmd.Flags().Bool("details", false, "Show extra details provided to logs")
+	cmd.Flags().StringSlice("filter", nil, "Show the logs of the containers matching the given conditions, like 'nerdctl ps --filter'")
+	cmd.Flags().Bool("no-color", false, "Produce monochrome output when showing the logs of multiple containers")
+	cmd.Flags().Bool("no-log-prefix", false, "Don't print the container name prefix when showing the logs of multiple containers")
+	cmd.Flags().String("grep", "", "Only show the lines matching the given regular expression")
+	cmd.Flags().String("stream", "", "Only show the given stream (stdout|stderr)")
+	cmd.Flags().String("format", "", "Format the output using the given format (json)")
+	cmd.RegisterFlagCompletionFunc("stream", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"stdout", "stderr"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{logging.FormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
@@ -93,16 +110,55 @@ func logsOptions(cmd *cobra.Command) (types.ContainerLogsOptions, error) {
 	if err != nil {
 		return types.ContainerLogsOptions{}, err
 	}
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	noLogPrefix, err := cmd.Flags().GetBool("no-log-prefix")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	grep, err := cmd.Flags().GetString("grep")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	if _, err := regexp.Compile(grep); err != nil {
+		return types.ContainerLogsOptions{}, fmt.Errorf("invalid `--grep` regular expression %q: %w", grep, err)
+	}
+	stream, err := cmd.Flags().GetString("stream")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	if stream != "" && stream != "stdout" && stream != "stderr" {
+		return types.ContainerLogsOptions{}, fmt.Errorf("invalid `--stream` %q, must be \"stdout\" or \"stderr\"", stream)
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	if format != logging.FormatRaw && format != logging.FormatJSON {
+		return types.ContainerLogsOptions{}, fmt.Errorf("unsupported `--format` %q, only %q is supported", format, logging.FormatJSON)
+	}
 	return types.ContainerLogsOptions{
-		Stdout:     cmd.OutOrStdout(),
-		Stderr:     cmd.OutOrStderr(),
-		GOptions:   globalOptions,
-		Follow:     follow,
-		Timestamps: timestamps,
-		Tail:       tail,
-		Since:      since,
-		Until:      until,
-		Details:    details,
+		Stdout:      cmd.OutOrStdout(),
+		Stderr:      cmd.OutOrStderr(),
+		GOptions:    globalOptions,
+		Follow:      follow,
+		Timestamps:  timestamps,
+		Tail:        tail,
+		Since:       since,
+		Until:       until,
+		Details:     details,
+		Filters:     filters,
+		NoColor:     noColor,
+		NoLogPrefix: noLogPrefix,
+		Grep:        grep,
+		Stream:      stream,
+		Format:      format,
 	}, nil
 }
 
@@ -111,6 +167,10 @@ func logsAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(args) == 0 && len(options.Filters) == 0 {
+		return fmt.Errorf("%q requires at least 1 argument or --filter.\nSee '%s --help'.\n\nUsage:  %s\n\n%s",
+			cmd.CommandPath(), cmd.CommandPath(), cmd.UseLine(), cmd.Short)
+	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
 	if err != nil {
@@ -118,7 +178,10 @@ func logsAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Logs(ctx, client, args[0], options)
+	if len(args) == 1 && len(options.Filters) == 0 {
+		return container.Logs(ctx, client, args[0], options)
+	}
+	return container.MultiLogs(ctx, client, args, options)
 }
 
 func logsShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
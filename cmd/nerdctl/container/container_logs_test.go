@@ -17,6 +17,7 @@
 package container
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -464,3 +465,124 @@ func TestLogsWithStartContainer(t *testing.T) {
 	}
 	testCase.Run(t)
 }
+
+func TestLogsMultipleContainers(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("foo"), "--label", "test="+data.Identifier(), testutil.CommonImage,
+			"sh", "-euc", "echo foo1; sleep 1; echo foo2")
+		helpers.Ensure("run", "-d", "--name", data.Identifier("bar"), "--label", "test="+data.Identifier(), testutil.CommonImage,
+			"sh", "-euc", "sleep 0.5; echo bar1 >&2")
+		data.Labels().Set("foo", data.Identifier("foo"))
+		data.Labels().Set("bar", data.Identifier("bar"))
+		data.Labels().Set("label", "test="+data.Identifier())
+		// Arbitrary, but we need to wait until the logs show up
+		time.Sleep(3 * time.Second)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("foo"), data.Identifier("bar"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "by filter",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--no-color", "--filter", "label="+data.Labels().Get("label"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: 0,
+					Errors:   []error{errors.New(data.Labels().Get("bar") + " |bar1\n")},
+					Output: expect.Equals(
+						data.Labels().Get("foo") + " |foo1\n" +
+							data.Labels().Get("foo") + " |foo2\n"),
+				}
+			},
+		},
+		{
+			Description: "by name, without prefix",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--no-log-prefix", data.Labels().Get("foo"), data.Labels().Get("bar"))
+			},
+			Expected: test.Expects(0, []error{errors.New("bar1\n")}, expect.Equals("foo1\nfoo2\n")),
+		},
+		{
+			Description: "requires a container or a filter",
+			Command:     test.Command("logs"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("requires at least 1 argument")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsGrepStreamFormat(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-euc", `echo foo; echo '{"level":"error"}'; echo bar error >&2`)
+		data.Labels().Set("container", data.Identifier())
+		// Arbitrary, but we need to wait until the logs show up
+		time.Sleep(3 * time.Second)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "grep",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--grep", "error", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(0, []error{errors.New("bar error\n")}, expect.Equals("{\"level\":\"error\"}\n")),
+		},
+		{
+			Description: "stream",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--stream", "stdout", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals("foo\n{\"level\":\"error\"}\n")),
+		},
+		{
+			Description: "json",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--format", "json", "--grep", "level", data.Labels().Get("container"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: 0,
+					Output: func(stdout string, info string, t *testing.T) {
+						var record struct {
+							Time      string
+							Stream    string
+							Container string
+							Message   string
+							Fields    map[string]string
+						}
+						assert.NilError(t, json.Unmarshal([]byte(stdout), &record), info)
+						assert.Equal(t, record.Stream, "stdout", info)
+						assert.Equal(t, record.Container, data.Labels().Get("container"), info)
+						assert.Equal(t, record.Fields["level"], "error", info)
+						assert.Assert(t, record.Time != "", info)
+					},
+				}
+			},
+		},
+		{
+			Description: "invalid stream",
+			Command:     test.Command("logs", "--stream", "stdin", "foo"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("invalid `--stream`")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -129,8 +129,14 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSlice("dns-option", nil, "Set DNS options")
 	// publish is defined as StringSlice, not StringArray, to allow specifying "--publish=80:80,443:443" (compatible with Podman)
 	cmd.Flags().StringSliceP("publish", "p", nil, "Publish a container's port(s) to the host")
+	cmd.Flags().BoolP("publish-all", "P", false, "Publish all exposed ports to random ports on the host")
+	// expose is defined as StringSlice, not StringArray, to allow specifying "--expose=80,443"
+	cmd.Flags().StringSlice("expose", nil, "Expose a port or a range of ports (<port>[-<port>][/<proto>])")
 	cmd.Flags().String("ip", "", "IPv4 address to assign to the container")
 	cmd.Flags().String("ip6", "", "IPv6 address to assign to the container")
+	// network-alias and link are defined as StringSlice, not StringArray, to allow specifying "--network-alias=foo,bar"
+	cmd.Flags().StringSlice("network-alias", nil, "Add network-scoped alias for the container ([<network>:]<alias>)")
+	cmd.Flags().StringSlice("link", nil, "Add link to another container (<name|id>[:<alias>])")
 	cmd.Flags().StringP("hostname", "h", "", "Container host name")
 	cmd.Flags().String("domainname", "", "Container domain name")
 	cmd.Flags().String("mac-address", "", "MAC address to assign to the container")
@@ -170,6 +176,7 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().Uint64("cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
 	// device is defined as StringSlice, not StringArray, to allow specifying "--device=DEV1,DEV2" (compatible with Podman)
 	cmd.Flags().StringSlice("device", nil, "Add a host device to the container")
+	cmd.Flags().StringArray("device-cgroup-rule", nil, `Add a rule to the cgroup allowed devices list (e.g. "c 1:3 rwm")`)
 	// ulimit is defined as StringSlice, not StringArray, to allow specifying "--ulimit=ULIMIT1,ULIMIT2" (compatible with Podman)
 	cmd.Flags().StringSlice("ulimit", nil, "Ulimit options")
 	cmd.Flags().String("rdt-class", "", "Name of the RDT class (or CLOS) to associate the container with")
@@ -271,6 +278,16 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringArray("log-opt", nil, "Log driver options")
 	// #endregion
 
+	// #region healthcheck flags
+	cmd.Flags().String("health-cmd", "", "Command to run to check health")
+	cmd.Flags().Duration("health-interval", 0, "Time between running the check (ms|s|m|h) (default 0s)")
+	cmd.Flags().Duration("health-timeout", 0, "Maximum time to allow one check to run (ms|s|m|h) (default 0s)")
+	cmd.Flags().Duration("health-start-period", 0, "Start period for the container to initialize before starting health-retries countdown (ms|s|m|h) (default 0s)")
+	cmd.Flags().Duration("health-start-interval", 0, "Time between running the check during the start period (ms|s|m|h) (default 0s)")
+	cmd.Flags().Int("health-retries", 0, "Consecutive failures needed to report unhealthy")
+	cmd.Flags().Bool("no-healthcheck", false, "Disable any container-specified HEALTHCHECK")
+	// #endregion
+
 	// shared memory flags
 	cmd.Flags().String("shm-size", "", "Size of /dev/shm")
 	cmd.Flags().String("pidfile", "", "file path to write the task's pid")
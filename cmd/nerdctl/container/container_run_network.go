@@ -17,13 +17,19 @@
 package container
 
 import (
+	"errors"
+	"fmt"
 	"net"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/go-cni"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
@@ -138,6 +144,30 @@ func loadNetworkFlags(cmd *cobra.Command) (types.NetworkOptions, error) {
 	}
 	netOpts.AddHost = addHostFlags
 
+	// --network-alias=[<network>:]<alias> ...
+	aliasSlice, err := cmd.Flags().GetStringSlice("network-alias")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.NetworkAliases, err = parseNetworkAliases(netOpts.NetworkSlice, strutil.DedupeStrSlice(aliasSlice))
+	if err != nil {
+		return netOpts, err
+	}
+
+	// --link=<container>[:<alias>] ...
+	linkSlice, err := cmd.Flags().GetStringSlice("link")
+	if err != nil {
+		return netOpts, err
+	}
+	if len(linkSlice) > 0 {
+		if netType, err := nettype.Detect(netOpts.NetworkSlice); err != nil {
+			return netOpts, err
+		} else if netType != nettype.CNI {
+			return netOpts, errors.New("conflicting options: links are supported only for containers in CNI networks")
+		}
+	}
+	netOpts.Links = strutil.DedupeStrSlice(linkSlice)
+
 	// --uts=<Unix Time Sharing namespace>
 	utsNamespace, err := cmd.Flags().GetString("uts")
 	if err != nil {
@@ -161,5 +191,69 @@ func loadNetworkFlags(cmd *cobra.Command) (types.NetworkOptions, error) {
 	}
 	netOpts.PortMappings = portMappings
 
+	// --expose=8080/tcp ...
+	exposeSlice, err := cmd.Flags().GetStringSlice("expose")
+	if err != nil {
+		return netOpts, err
+	}
+	exposedPorts := []string{}
+	for _, e := range strutil.DedupeStrSlice(exposeSlice) {
+		ports, err := portutil.ParseFlagExpose(e)
+		if err != nil {
+			return netOpts, err
+		}
+		exposedPorts = append(exposedPorts, ports...)
+	}
+	netOpts.ExposedPorts = strutil.DedupeStrSlice(exposedPorts)
+
+	// -P/--publish-all
+	netOpts.PublishAll, err = cmd.Flags().GetBool("publish-all")
+	if err != nil {
+		return netOpts, err
+	}
+
 	return netOpts, nil
 }
+
+// parseNetworkAliases returns the network-scoped aliases by network.
+// An alias applies to all the user-defined networks of the container, unless it is prefixed with a network name.
+func parseNetworkAliases(networks, aliases []string) (map[string][]string, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	if netType, err := nettype.Detect(networks); err != nil {
+		return nil, err
+	} else if netType != nettype.CNI {
+		return nil, errors.New("network-scoped alias is supported only for containers in user defined networks")
+	}
+	result := make(map[string][]string)
+	for _, a := range aliases {
+		var targets []string
+		network, alias, ok := strings.Cut(a, ":")
+		if ok {
+			if !slices.Contains(networks, network) {
+				return nil, fmt.Errorf("invalid network-scoped alias %q: the container is not connected to network %q", a, network)
+			}
+			targets = []string{network}
+		} else {
+			alias = network
+			for _, n := range networks {
+				if n != netutil.DefaultNetworkName {
+					targets = append(targets, n)
+				}
+			}
+		}
+		if alias == "" {
+			return nil, fmt.Errorf("invalid network-scoped alias %q", a)
+		}
+		if len(targets) == 0 || slices.Contains(targets, netutil.DefaultNetworkName) {
+			return nil, errors.New("network-scoped alias is supported only for containers in user defined networks")
+		}
+		for _, target := range targets {
+			if !slices.Contains(result[target], alias) {
+				result[target] = append(result[target], alias)
+			}
+		}
+	}
+	return result, nil
+}
@@ -1016,3 +1016,169 @@ func TestHostNetworkDnsConfigs(t *testing.T) {
 	}
 	testCase.Run(t)
 }
+
+func TestRunEmbeddedDNS(t *testing.T) {
+	nerdtest.Setup()
+	testCase := &test.Case{
+		Require: require.Not(nerdtest.Docker),
+		Setup: func(data test.Data, helpers test.Helpers) {
+			helpers.Ensure("network", "create", "--subnet", "10.5.9.0/24", data.Identifier())
+			helpers.Ensure("run", "-d", "--name", data.Identifier("target"), "--hostname", "svc",
+				"--network", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+			data.Labels().Set("network", data.Identifier())
+			data.Labels().Set("target", data.Identifier("target"))
+			data.Labels().Set("ip", strings.TrimSpace(helpers.Capture("inspect", "--format",
+				"{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}", data.Identifier("target"))))
+		},
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			helpers.Anyhow("rm", "-f", data.Identifier("target"))
+			helpers.Anyhow("network", "rm", data.Identifier())
+		},
+		SubTests: []*test.Case{
+			{
+				Description: "resolv.conf only lists the gateway",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("exec", data.Labels().Get("target"), "cat", "/etc/resolv.conf")
+				},
+				Expected: test.Expects(0, nil, func(stdout string, info string, t *testing.T) {
+					assert.Assert(t, strings.Contains(stdout, "nameserver 10.5.9.1\n"), info)
+					assert.Equal(t, strings.Count(stdout, "nameserver"), 1, info)
+				}),
+			},
+			{
+				Description: "container and host names resolve through DNS",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					// The server is started asynchronously by the OCI hook
+					script := "for i in 1 2 3 4 5; do nslookup %[1]s 10.5.9.1 && nslookup svc 10.5.9.1 && exit 0; sleep 1; done; exit 1"
+					return helpers.Command("run", "--rm", "--network", data.Labels().Get("network"), testutil.CommonImage,
+						"sh", "-c", fmt.Sprintf(script, data.Labels().Get("target")))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains(data.Labels().Get("ip")),
+					}
+				},
+			},
+			{
+				Description: "the default network does not use the embedded DNS server",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("run", "--rm", testutil.CommonImage, "cat", "/etc/resolv.conf")
+				},
+				Expected: test.Expects(0, nil, expect.DoesNotContain("nameserver 10.4.0.1")),
+			},
+		},
+	}
+	testCase.Run(t)
+}
+
+func TestRunNetworkAliasAndLink(t *testing.T) {
+	nerdtest.Setup()
+	testCase := &test.Case{
+		Require: require.Not(nerdtest.Docker),
+		Setup: func(data test.Data, helpers test.Helpers) {
+			helpers.Ensure("network", "create", data.Identifier())
+			helpers.Ensure("run", "-d", "--name", data.Identifier("target"), "--network", data.Identifier(),
+				"--network-alias", "db,database", testutil.CommonImage, "sleep", nerdtest.Infinity)
+			data.Labels().Set("network", data.Identifier())
+			data.Labels().Set("target", data.Identifier("target"))
+			data.Labels().Set("ip", strings.TrimSpace(helpers.Capture("inspect", "--format",
+				"{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}", data.Identifier("target"))))
+		},
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			helpers.Anyhow("rm", "-f", data.Identifier("target"))
+			helpers.Anyhow("network", "rm", data.Identifier())
+		},
+		SubTests: []*test.Case{
+			{
+				Description: "network-scoped aliases are visible from the peers",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("run", "--rm", "--network", data.Labels().Get("network"), testutil.CommonImage,
+						"getent", "hosts", "database")
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains(data.Labels().Get("ip")),
+					}
+				},
+			},
+			{
+				Description: "links are visible from the linking container",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("run", "--rm", "--network", data.Labels().Get("network"),
+						"--link", data.Labels().Get("target")+":kv", testutil.CommonImage, "getent", "hosts", "kv")
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains(data.Labels().Get("ip")),
+					}
+				},
+			},
+			{
+				Description: "links to missing containers are rejected",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("run", "--rm", "--network", data.Labels().Get("network"),
+						"--link", data.Identifier("missing"), testutil.CommonImage, "true")
+				},
+				Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+			},
+			{
+				Description: "network-scoped aliases are rejected on the default network",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("run", "--rm", "--network-alias", "db", testutil.CommonImage, "true")
+				},
+				Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+			},
+		},
+	}
+	testCase.Run(t)
+}
+
+func TestRunPublishAll(t *testing.T) {
+	nerdtest.Setup()
+	testCase := &test.Case{
+		// Random host ports are not allocated in rootless mode
+		Require: require.Not(nerdtest.Rootless),
+		Setup: func(data test.Data, helpers test.Helpers) {
+			helpers.Ensure("run", "-d", "--name", data.Identifier(), "-P", "--expose", "8080", "--expose", "53/udp",
+				testutil.CommonImage, "sleep", nerdtest.Infinity)
+			data.Labels().Set("container", data.Identifier())
+		},
+		Cleanup: func(data test.Data, helpers test.Helpers) {
+			helpers.Anyhow("rm", "-f", data.Identifier())
+		},
+		SubTests: []*test.Case{
+			{
+				Description: "exposed ports are published",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("port", data.Labels().Get("container"))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains("8080/tcp -> 0.0.0.0:", "53/udp -> 0.0.0.0:"),
+					}
+				},
+			},
+			{
+				Description: "exposed ports are shown in inspect",
+				NoParallel:  true,
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("inspect", "--format", "{{json .Config.ExposedPorts}}", data.Labels().Get("container"))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						Output: expect.Contains(`"8080/tcp":{}`, `"53/udp":{}`),
+					}
+				},
+			},
+		},
+	}
+	testCase.Run(t)
+}
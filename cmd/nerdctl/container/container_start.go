@@ -44,6 +44,7 @@ func StartCommand() *cobra.Command {
 	cmd.Flags().BoolP("attach", "a", false, "Attach STDOUT/STDERR and forward signals")
 	cmd.Flags().String("detach-keys", consoleutil.DefaultDetachKeys, "Override the default detach keys")
 	cmd.Flags().BoolP("interactive", "i", false, "Attach container's STDIN")
+	cmd.Flags().String("checkpoint", "", "Restore from this checkpoint (name of a checkpoint of the container, or reference of a checkpoint image)")
 	return cmd
 }
 
@@ -64,12 +65,17 @@ func startOptions(cmd *cobra.Command) (types.ContainerStartOptions, error) {
 	if err != nil {
 		return types.ContainerStartOptions{}, err
 	}
+	checkpoint, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		return types.ContainerStartOptions{}, err
+	}
 	return types.ContainerStartOptions{
 		Stdout:      cmd.OutOrStdout(),
 		GOptions:    globalOptions,
 		Attach:      attach,
 		DetachKeys:  detachKeys,
 		Interactive: interactive,
+		Checkpoint:  checkpoint,
 	}, nil
 }
 
@@ -17,6 +17,9 @@
 package container
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -26,6 +29,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
 )
 
 func StatsCommand() *cobra.Command {
@@ -45,9 +49,19 @@ func StatsCommand() *cobra.Command {
 
 func addStatsFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("all", "a", false, "Show all containers (default shows just running)")
-	cmd.Flags().String("format", "", "Pretty-print images using a Go template, e.g, '{{json .}}'")
+	cmd.Flags().String("format", "", "Pretty-print images using a Go template, e.g, '{{json .}}', 'prometheus'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table", "prometheus"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.Flags().Bool("no-stream", false, "Disable streaming stats and only pull the first result")
 	cmd.Flags().Bool("no-trunc", false, "Do not truncate output")
+	cmd.Flags().Duration("interval", container.DefaultStatsInterval, "Interval between two samples of the stats, refreshes of the output and recorded samples (minimum 100ms)")
+	cmd.Flags().String("record", "", "Record the samples to a JSONL or CSV file")
+	cmd.Flags().String("record-format", "", "Format of the record file (jsonl|csv), guessed from the file extension by default")
+	cmd.RegisterFlagCompletionFunc("record-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{statsutil.RecordFormatJSONL, statsutil.RecordFormatCSV}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("replay", "", "Print the summary of the samples recorded in a file with --record")
 }
 
 func processStatsCommandFlags(cmd *cobra.Command) (types.ContainerStatsOptions, error) {
@@ -76,14 +90,52 @@ func processStatsCommandFlags(cmd *cobra.Command) (types.ContainerStatsOptions,
 		return types.ContainerStatsOptions{}, err
 	}
 
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return types.ContainerStatsOptions{}, err
+	}
+	if interval < container.MinStatsInterval {
+		return types.ContainerStatsOptions{}, fmt.Errorf("invalid interval %s: must be at least %s", interval, container.MinStatsInterval)
+	}
+
+	record, err := cmd.Flags().GetString("record")
+	if err != nil {
+		return types.ContainerStatsOptions{}, err
+	}
+
+	recordFormat, err := cmd.Flags().GetString("record-format")
+	if err != nil {
+		return types.ContainerStatsOptions{}, err
+	}
+	switch recordFormat {
+	case "", statsutil.RecordFormatJSONL, statsutil.RecordFormatCSV:
+	default:
+		return types.ContainerStatsOptions{}, fmt.Errorf("invalid record format %q, must be %q or %q", recordFormat, statsutil.RecordFormatJSONL, statsutil.RecordFormatCSV)
+	}
+	if recordFormat != "" && record == "" {
+		return types.ContainerStatsOptions{}, errors.New("--record-format requires --record")
+	}
+
+	replay, err := cmd.Flags().GetString("replay")
+	if err != nil {
+		return types.ContainerStatsOptions{}, err
+	}
+	if replay != "" && (record != "" || all || noStream) {
+		return types.ContainerStatsOptions{}, errors.New("--replay cannot be combined with --record, --all or --no-stream")
+	}
+
 	return types.ContainerStatsOptions{
-		Stdout:   cmd.OutOrStdout(),
-		Stderr:   cmd.ErrOrStderr(),
-		GOptions: globalOptions,
-		All:      all,
-		Format:   format,
-		NoStream: noStream,
-		NoTrunc:  noTrunc,
+		Stdout:       cmd.OutOrStdout(),
+		Stderr:       cmd.ErrOrStderr(),
+		GOptions:     globalOptions,
+		All:          all,
+		Format:       format,
+		NoStream:     noStream,
+		NoTrunc:      noTrunc,
+		Interval:     interval,
+		Record:       record,
+		RecordFormat: recordFormat,
+		Replay:       replay,
 	}, nil
 }
 
@@ -93,6 +145,13 @@ func statsAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if options.Replay != "" {
+		if len(args) > 0 {
+			return errors.New("--replay cannot be combined with container arguments")
+		}
+		return container.StatsReplay(options)
+	}
+
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
 	if err != nil {
 		return err
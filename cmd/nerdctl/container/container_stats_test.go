@@ -17,6 +17,7 @@
 package container
 
 import (
+	"errors"
 	"runtime"
 	"testing"
 
@@ -54,6 +55,7 @@ func TestStats(t *testing.T) {
 		helpers.Ensure("run", "-d", "--name", data.Identifier("memlimited"), "--memory", "1g", testutil.CommonImage, "sleep", nerdtest.Infinity)
 		helpers.Ensure("run", "--name", data.Identifier("exited"), testutil.CommonImage, "echo", "'exited'")
 		data.Labels().Set("id", data.Identifier("container"))
+		data.Labels().Set("memlimited", data.Identifier("memlimited"))
 	}
 
 	testCase.SubTests = []*test.Case{
@@ -105,6 +107,40 @@ func TestStats(t *testing.T) {
 			},
 			Expected: test.Expects(0, nil, expect.Contains("1GiB")),
 		},
+		{
+			Description: "prometheus format",
+			Require:     require.Not(nerdtest.Docker),
+			Command:     test.Command("stats", "--no-stream", "--format", "prometheus"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						"# TYPE nerdctl_container_cpu_usage_seconds_total counter\n",
+						`nerdctl_container_pids{name="`+data.Labels().Get("id")+`"`,
+						`nerdctl_container_memory_limit_bytes{name="`+data.Labels().Get("memlimited")+`"`,
+						"} 1.073741824e+09\n",
+					),
+				}
+			},
+		},
+		{
+			Description: "interval shorter than the minimum",
+			Require:     require.Not(nerdtest.Docker),
+			Command:     test.Command("stats", "--no-stream", "--interval", "10ms"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, []error{errors.New("must be at least 100ms")}, nil),
+		},
+		{
+			Description: "record and replay",
+			Require:     require.Not(nerdtest.Docker),
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("stats", "--no-stream", "--record", data.Temp().Path("stats.csv"))
+				return helpers.Command("stats", "--no-trunc", "--replay", data.Temp().Path("stats.csv"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains("CPU % MIN / AVG / P95 / MAX", data.Labels().Get("id"), data.Labels().Get("memlimited")),
+				}
+			},
+		},
 	}
 
 	testCase.Run(t)
@@ -22,6 +22,8 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/docker/go-units"
@@ -42,6 +44,8 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
 type updateResourceOptions struct {
@@ -55,6 +59,18 @@ type updateResourceOptions struct {
 	CpusetMems         string
 	PidsLimit          int64
 	BlkioWeight        uint16
+	// BlkioDeviceReadBps, BlkioDeviceWriteBps, BlkioDeviceReadIOps and BlkioDeviceWriteIOps are "<device-path>:<rate>" strings
+	BlkioDeviceReadBps   []string
+	BlkioDeviceWriteBps  []string
+	BlkioDeviceReadIOps  []string
+	BlkioDeviceWriteIOps []string
+	DeviceCgroupRules    []string
+	MemorySwappiness     int64
+	OomKillDisable       bool
+	OomScoreAdj          int
+	Ulimits              []string
+	CPURealtimePeriod    uint64
+	CPURealtimeRuntime   uint64
 }
 
 func UpdateCommand() *cobra.Command {
@@ -85,6 +101,17 @@ func setUpdateFlags(cmd *cobra.Command) {
 	cmd.Flags().String("cpuset-mems", "", "MEMs in which to allow execution (0-3, 0,1)")
 	cmd.Flags().Int64("pids-limit", -1, "Tune container pids limit (set -1 for unlimited)")
 	cmd.Flags().Uint16("blkio-weight", 0, "Block IO (relative weight), between 10 and 1000, or 0 to disable (default 0)")
+	cmd.Flags().StringArray("device-read-bps", []string{}, "Limit read rate (bytes per second) from a device")
+	cmd.Flags().StringArray("device-read-iops", []string{}, "Limit read rate (IO per second) from a device")
+	cmd.Flags().StringArray("device-write-bps", []string{}, "Limit write rate (bytes per second) to a device")
+	cmd.Flags().StringArray("device-write-iops", []string{}, "Limit write rate (IO per second) to a device")
+	cmd.Flags().StringArray("device-cgroup-rule", []string{}, `Add a rule to the cgroup allowed devices list (e.g. "c 1:3 rwm")`)
+	cmd.Flags().Int64("memory-swappiness", -1, "Tune container memory swappiness (0 to 100)")
+	cmd.Flags().Bool("oom-kill-disable", false, "Disable OOM Killer")
+	cmd.Flags().Int("oom-score-adj", 0, "Tune container's OOM preferences (-1000 to 1000, rootless: 100 to 1000)")
+	cmd.Flags().StringSlice("ulimit", nil, "Ulimit options, applied at the next start of the container")
+	cmd.Flags().Uint64("cpu-rt-period", 0, "Limit CPU real-time period in microseconds")
+	cmd.Flags().Uint64("cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
 	cmd.Flags().String("restart", "no", `Restart policy to apply when a container exits (implemented values: "no"|"always|on-failure:n|unless-stopped")`)
 	cmd.RegisterFlagCompletionFunc("restart", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"no", "always", "on-failure", "unless-stopped"}, cobra.ShellCompDirectiveNoFileComp
@@ -224,6 +251,62 @@ func getUpdateOption(cmd *cobra.Command, globalOptions types.GlobalCommandOption
 	if blkioWeight > 0 && blkioWeight < 10 || blkioWeight > 1000 {
 		return options, errors.New("range of blkio weight is from 10 to 1000")
 	}
+	blkioDeviceReadBps, err := cmd.Flags().GetStringArray("device-read-bps")
+	if err != nil {
+		return options, err
+	}
+	blkioDeviceWriteBps, err := cmd.Flags().GetStringArray("device-write-bps")
+	if err != nil {
+		return options, err
+	}
+	blkioDeviceReadIOps, err := cmd.Flags().GetStringArray("device-read-iops")
+	if err != nil {
+		return options, err
+	}
+	blkioDeviceWriteIOps, err := cmd.Flags().GetStringArray("device-write-iops")
+	if err != nil {
+		return options, err
+	}
+	deviceCgroupRules, err := cmd.Flags().GetStringArray("device-cgroup-rule")
+	if err != nil {
+		return options, err
+	}
+	memSwappiness, err := cmd.Flags().GetInt64("memory-swappiness")
+	if err != nil {
+		return options, err
+	}
+	if cmd.Flags().Changed("memory-swappiness") && (memSwappiness < 0 || memSwappiness > 100) {
+		return options, fmt.Errorf("invalid value: %v, valid memory swappiness range is 0-100", memSwappiness)
+	}
+	oomKillDisable, err := cmd.Flags().GetBool("oom-kill-disable")
+	if err != nil {
+		return options, err
+	}
+	oomScoreAdj, err := cmd.Flags().GetInt("oom-score-adj")
+	if err != nil {
+		return options, err
+	}
+	ulimits, err := cmd.Flags().GetStringSlice("ulimit")
+	if err != nil {
+		return options, err
+	}
+	cpuRtPeriod, err := cmd.Flags().GetUint64("cpu-rt-period")
+	if err != nil {
+		return options, err
+	}
+	cpuRtRuntime, err := cmd.Flags().GetUint64("cpu-rt-runtime")
+	if err != nil {
+		return options, err
+	}
+	if cmd.Flags().Changed("cpu-rt-period") || cmd.Flags().Changed("cpu-rt-runtime") {
+		if !infoutil.CPURealtime(globalOptions.CgroupManager) {
+			// CPU realtime scheduling is not supported in cgroup V2
+			return options, errors.New("kernel does not support CPU real-time scheduler")
+		}
+		if cpuRtPeriod != 0 && cpuRtRuntime != 0 && cpuRtRuntime > cpuRtPeriod {
+			return options, errors.New("cpu real-time runtime cannot be higher than cpu real-time period")
+		}
+	}
 
 	if runtime.GOOS == "linux" {
 		options = updateResourceOptions{
@@ -237,6 +320,18 @@ func getUpdateOption(cmd *cobra.Command, globalOptions types.GlobalCommandOption
 			MemorySwapInBytes:  memSwap64,
 			PidsLimit:          pidsLimit,
 			BlkioWeight:        blkioWeight,
+
+			BlkioDeviceReadBps:   blkioDeviceReadBps,
+			BlkioDeviceWriteBps:  blkioDeviceWriteBps,
+			BlkioDeviceReadIOps:  blkioDeviceReadIOps,
+			BlkioDeviceWriteIOps: blkioDeviceWriteIOps,
+			DeviceCgroupRules:    deviceCgroupRules,
+			MemorySwappiness:     memSwappiness,
+			OomKillDisable:       oomKillDisable,
+			OomScoreAdj:          oomScoreAdj,
+			Ulimits:              ulimits,
+			CPURealtimePeriod:    cpuRtPeriod,
+			CPURealtimeRuntime:   cpuRtRuntime,
 		}
 	}
 	return options, nil
@@ -337,6 +432,9 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 				spec.Linux.Resources.Pids.Limit = opts.PidsLimit
 			}
 		}
+		if err := updateSpecLinux(spec, opts, cmd); err != nil {
+			return err
+		}
 	}
 
 	if err := updateContainerSpec(ctx, container, spec); err != nil {
@@ -362,10 +460,15 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 			return err
 		}
 	}
+	if cmd.Flags().Changed("device-cgroup-rule") || cmd.Flags().Changed("ulimit") {
+		if err := updateHostConfigLabel(ctx, container, opts, cmd); err != nil {
+			return err
+		}
+	}
 
 	// If container is not running, only update spec is enough, new resource
 	// limit will be applied when container start.
-	if cStatus != "Up" {
+	if !strings.HasPrefix(cStatus, "Up") {
 		return nil
 	}
 	task, err := container.Task(ctx, nil)
@@ -376,7 +479,40 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 		}
 		return fmt.Errorf("failed to get task:%w", err)
 	}
-	return task.Update(ctx, containerd.WithResources(spec.Linux.Resources))
+	if err := task.Update(ctx, containerd.WithResources(spec.Linux.Resources)); err != nil {
+		return err
+	}
+	return updateTaskLinux(ctx, task, spec, opts, cmd)
+}
+
+// updateHostConfigLabel records the device cgroup rules and the ulimits in the host config label, for `nerdctl inspect`.
+func updateHostConfigLabel(ctx context.Context, container containerd.Container, opts updateResourceOptions, cmd *cobra.Command) error {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	var hostConfigLabel dockercompat.HostConfigLabel
+	if s := l[labels.HostConfigLabel]; s != "" {
+		if err := json.Unmarshal([]byte(s), &hostConfigLabel); err != nil {
+			return err
+		}
+	}
+	for _, rule := range opts.DeviceCgroupRules {
+		if !slices.Contains(hostConfigLabel.DeviceCgroupRules, rule) {
+			hostConfigLabel.DeviceCgroupRules = append(hostConfigLabel.DeviceCgroupRules, rule)
+		}
+	}
+	if cmd.Flags().Changed("ulimit") {
+		if hostConfigLabel.Ulimits, err = nerdctlcontainer.MergeUlimits(hostConfigLabel.Ulimits, opts.Ulimits); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(hostConfigLabel)
+	if err != nil {
+		return err
+	}
+	_, err = container.SetLabels(ctx, map[string]string{labels.HostConfigLabel: string(b)})
+	return err
 }
 
 func updateContainerSpec(ctx context.Context, container containerd.Container, spec *runtimespec.Spec) error {
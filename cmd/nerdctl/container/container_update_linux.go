@@ -0,0 +1,214 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"syscall"
+
+	runtimespec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/cgroups/v3/cgroup1"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	nerdctlcontainer "github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+)
+
+// updateSpecLinux applies the update flags which are not handled by updateContainer to the spec.
+func updateSpecLinux(spec *runtimespec.Spec, opts updateResourceOptions, cmd *cobra.Command) error {
+	resources := spec.Linux.Resources
+
+	throttleFlags := []string{"device-read-bps", "device-write-bps", "device-read-iops", "device-write-iops"}
+	if slices.ContainsFunc(throttleFlags, cmd.Flags().Changed) && resources.BlockIO == nil {
+		resources.BlockIO = &runtimespec.LinuxBlockIO{}
+	}
+	if resources.BlockIO != nil {
+		for _, throttle := range []struct {
+			flag    string
+			vals    []string
+			iops    bool
+			devices *[]runtimespec.LinuxThrottleDevice
+		}{
+			{"device-read-bps", opts.BlkioDeviceReadBps, false, &resources.BlockIO.ThrottleReadBpsDevice},
+			{"device-write-bps", opts.BlkioDeviceWriteBps, false, &resources.BlockIO.ThrottleWriteBpsDevice},
+			{"device-read-iops", opts.BlkioDeviceReadIOps, true, &resources.BlockIO.ThrottleReadIOPSDevice},
+			{"device-write-iops", opts.BlkioDeviceWriteIOps, true, &resources.BlockIO.ThrottleWriteIOPSDevice},
+		} {
+			if !cmd.Flags().Changed(throttle.flag) {
+				continue
+			}
+			devices, err := nerdctlcontainer.ThrottleDevices(throttle.vals, throttle.iops)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", throttle.flag, err)
+			}
+			*throttle.devices = mergeThrottleDevices(*throttle.devices, devices)
+		}
+	}
+
+	if cmd.Flags().Changed("device-cgroup-rule") {
+		for _, r := range opts.DeviceCgroupRules {
+			rule, err := nerdctlcontainer.ParseDeviceCgroupRule(r)
+			if err != nil {
+				return err
+			}
+			if !containsDeviceCgroupRule(resources.Devices, rule) {
+				resources.Devices = append(resources.Devices, rule)
+			}
+		}
+	}
+
+	if cmd.Flags().Changed("memory-swappiness") || cmd.Flags().Changed("oom-kill-disable") {
+		if resources.Memory == nil {
+			resources.Memory = &runtimespec.LinuxMemory{}
+		}
+	}
+	if cmd.Flags().Changed("memory-swappiness") {
+		swappiness := uint64(opts.MemorySwappiness)
+		resources.Memory.Swappiness = &swappiness
+	}
+	if cmd.Flags().Changed("oom-kill-disable") {
+		resources.Memory.DisableOOMKiller = &opts.OomKillDisable
+	}
+
+	if cmd.Flags().Changed("cpu-rt-period") || cmd.Flags().Changed("cpu-rt-runtime") {
+		if resources.CPU == nil {
+			resources.CPU = &runtimespec.LinuxCPU{}
+		}
+	}
+	if cmd.Flags().Changed("cpu-rt-period") {
+		resources.CPU.RealtimePeriod = &opts.CPURealtimePeriod
+	}
+	if cmd.Flags().Changed("cpu-rt-runtime") {
+		runtime := int64(opts.CPURealtimeRuntime)
+		resources.CPU.RealtimeRuntime = &runtime
+	}
+
+	if cmd.Flags().Changed("oom-score-adj") || cmd.Flags().Changed("ulimit") {
+		if spec.Process == nil {
+			spec.Process = &runtimespec.Process{}
+		}
+	}
+	if cmd.Flags().Changed("oom-score-adj") {
+		score, err := nerdctlcontainer.ValidateOOMScoreAdj(opts.OomScoreAdj)
+		if err != nil {
+			return err
+		}
+		spec.Process.OOMScoreAdj = &score
+	}
+	if cmd.Flags().Changed("ulimit") {
+		rlimits, err := nerdctlcontainer.ParseUlimits(opts.Ulimits)
+		if err != nil {
+			return err
+		}
+		for _, rl := range rlimits {
+			replaced := false
+			for i := range spec.Process.Rlimits {
+				if spec.Process.Rlimits[i].Type == rl.Type {
+					spec.Process.Rlimits[i] = rl
+					replaced = true
+				}
+			}
+			if !replaced {
+				spec.Process.Rlimits = append(spec.Process.Rlimits, rl)
+			}
+		}
+	}
+	return nil
+}
+
+// updateTaskLinux applies the updated settings which are not part of the task resources to the running task.
+func updateTaskLinux(ctx context.Context, task containerd.Task, spec *runtimespec.Spec, opts updateResourceOptions, cmd *cobra.Command) error {
+	pid := int(task.Pid())
+	if cmd.Flags().Changed("oom-score-adj") {
+		// like the other resources, the score applies to all the processes of the container, not only to the init process
+		procs, err := task.Pids(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list the processes of the container: %w", err)
+		}
+		score := strconv.Itoa(*spec.Process.OOMScoreAdj)
+		for _, p := range procs {
+			err := os.WriteFile(fmt.Sprintf("/proc/%d/oom_score_adj", p.Pid), []byte(score), 0o644)
+			// the process may have exited in the meantime
+			if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ESRCH) {
+				return fmt.Errorf("failed to update the oom score adj of the process %d of the container: %w", p.Pid, err)
+			}
+		}
+	}
+	if cmd.Flags().Changed("device-cgroup-rule") {
+		// the device cgroup rules are not updated by the runtime.
+		if infoutil.CgroupsVersion() == "1" {
+			// only the new rules are written, as the whole list starts with denying all the devices
+			var rules []runtimespec.LinuxDeviceCgroup
+			for _, r := range opts.DeviceCgroupRules {
+				rule, err := nerdctlcontainer.ParseDeviceCgroupRule(r)
+				if err != nil {
+					return err
+				}
+				rules = append(rules, rule)
+			}
+			cg, err := cgroup1.Load(cgroup1.PidPath(pid))
+			if err != nil {
+				return fmt.Errorf("failed to load the cgroup of the container: %w", err)
+			}
+			if err := cg.Update(&runtimespec.LinuxResources{Devices: rules}); err != nil {
+				return fmt.Errorf("failed to update the device cgroup rules of the container: %w", err)
+			}
+		} else {
+			// on cgroup v2, the rules are enforced by an eBPF program which is only replaced when the container starts.
+			log.G(ctx).Warn("The device cgroup rules will be applied at the next start of the container")
+		}
+	}
+	if cmd.Flags().Changed("ulimit") {
+		log.G(ctx).Warn("The ulimits will be applied at the next start of the container")
+	}
+	return nil
+}
+
+// mergeThrottleDevices replaces the throttle of the devices already present in devices, and appends the others.
+func mergeThrottleDevices(devices, updates []runtimespec.LinuxThrottleDevice) []runtimespec.LinuxThrottleDevice {
+	for _, u := range updates {
+		replaced := false
+		for i := range devices {
+			if devices[i].Major == u.Major && devices[i].Minor == u.Minor {
+				devices[i] = u
+				replaced = true
+			}
+		}
+		if !replaced {
+			devices = append(devices, u)
+		}
+	}
+	return devices
+}
+
+func containsDeviceCgroupRule(rules []runtimespec.LinuxDeviceCgroup, rule runtimespec.LinuxDeviceCgroup) bool {
+	for _, r := range rules {
+		if reflect.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -19,7 +19,12 @@ package container
 import (
 	"testing"
 
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 )
 
 func TestUpdateContainer(t *testing.T) {
@@ -31,3 +36,69 @@ func TestUpdateContainer(t *testing.T) {
 	base.Cmd("update", "--memory", "999999999", "--restart", "123", testContainerName).AssertFail()
 	base.Cmd("inspect", "--mode=native", testContainerName).AssertOutNotContains(`"limit": 999999999,`)
 }
+
+func TestUpdateContainerLinuxSettings(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.CgroupsAccessible,
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// the sleep process is not the init process of the container
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--device-cgroup-rule", "c 1:5 rwm",
+			testutil.CommonImage, "sh", "-c", "sleep "+nerdtest.Infinity+" & wait")
+		helpers.Ensure("update", "--oom-score-adj", "500", "--ulimit", "nofile=2048:4096",
+			"--device-cgroup-rule", "c 1:3 rwm", data.Identifier())
+		data.Labels().Set("container", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "oom score adj is applied to the running container",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("container"), "cat", "/proc/1/oom_score_adj")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("500\n")),
+		},
+		{
+			Description: "oom score adj is applied to the other processes of the running container",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("container"), "sh", "-c", "cat /proc/$(pidof sleep)/oom_score_adj")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("500\n")),
+		},
+		{
+			Description: "settings are reported in inspect",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format",
+					"{{.HostConfig.OomScoreAdj}} {{json .HostConfig.DeviceCgroupRules}} {{json .HostConfig.Ulimits}}", data.Labels().Get("container"))
+			},
+			Expected: test.Expects(0, nil, expect.Contains(
+				// the rules set by run are kept
+				`500 ["c 1:5 rwm","c 1:3 rwm"] `,
+				// only the ulimits set by the user are reported, not the defaults of the runtime
+				`[{"Name":"nofile","Hard":4096,"Soft":2048}]`,
+			)),
+		},
+		{
+			Description: "ulimits are applied after restart",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("restart", data.Labels().Get("container"))
+				return helpers.Command("exec", data.Labels().Get("container"), "sh", "-c", "ulimit -n")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("2048\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
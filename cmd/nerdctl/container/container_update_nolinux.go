@@ -0,0 +1,36 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+
+	runtimespec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/spf13/cobra"
+
+	containerd "github.com/containerd/containerd/v2/client"
+)
+
+func updateSpecLinux(spec *runtimespec.Spec, opts updateResourceOptions, cmd *cobra.Command) error {
+	return nil
+}
+
+func updateTaskLinux(ctx context.Context, task containerd.Task, spec *runtimespec.Spec, opts updateResourceOptions, cmd *cobra.Command) error {
+	return nil
+}
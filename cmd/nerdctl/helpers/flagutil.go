@@ -111,24 +111,29 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	restartSupervisor, err := cmd.Flags().GetString("restart-supervisor")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 
 	return types.GlobalCommandOptions{
-		Debug:            debug,
-		DebugFull:        debugFull,
-		Address:          address,
-		Namespace:        namespace,
-		Snapshotter:      snapshotter,
-		CNIPath:          cniPath,
-		CNINetConfPath:   cniConfigPath,
-		DataRoot:         dataRoot,
-		CgroupManager:    cgroupManager,
-		InsecureRegistry: insecureRegistry,
-		HostsDir:         hostsDir,
-		Experimental:     experimental,
-		HostGatewayIP:    hostGatewayIP,
-		BridgeIP:         bridgeIP,
-		KubeHideDupe:     kubeHideDupe,
-		CDISpecDirs:      cdiSpecDirs,
+		Debug:             debug,
+		DebugFull:         debugFull,
+		Address:           address,
+		Namespace:         namespace,
+		Snapshotter:       snapshotter,
+		CNIPath:           cniPath,
+		CNINetConfPath:    cniConfigPath,
+		DataRoot:          dataRoot,
+		CgroupManager:     cgroupManager,
+		InsecureRegistry:  insecureRegistry,
+		HostsDir:          hostsDir,
+		Experimental:      experimental,
+		HostGatewayIP:     hostGatewayIP,
+		BridgeIP:          bridgeIP,
+		KubeHideDupe:      kubeHideDupe,
+		CDISpecDirs:       cdiSpecDirs,
+		RestartSupervisor: restartSupervisor,
 	}, nil
 }
 
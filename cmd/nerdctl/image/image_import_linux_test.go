@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImportExportedContainer(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Linux
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "--name", data.Identifier("container"), testutil.CommonImage,
+			"sh", "-c", "echo exported > /exported")
+		helpers.Ensure("export", "-o", filepath.Join(data.Temp().Path(), "rootfs.tar"), data.Identifier("container"))
+		data.Labels().Set("rootfs", filepath.Join(data.Temp().Path(), "rootfs.tar"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("container"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "import from file with changes",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("import", "--change", `CMD ["cat", "/exported"]`, "--message", "flat",
+					data.Labels().Get("rootfs"), data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Equals("exported\n"),
+						func(stdout string, info string, t *testing.T) {
+							history := helpers.Capture("history", "--format", "{{.Comment}}", data.Identifier())
+							assert.Equal(t, history, "flat\n", info)
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "import from stdin",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				cmd := helpers.Command("import", "-", data.Identifier())
+				reader, err := os.Open(data.Labels().Get("rootfs"))
+				assert.NilError(t, err, "failed to open rootfs.tar")
+				cmd.Feed(reader)
+				cmd.Run(&test.Expected{})
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", data.Identifier(), "cat", "/exported")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("exported\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -31,6 +31,7 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(
 		newInternalOCIHookCommandCommand(),
+		newInternalDNSServerCommand(),
 	)
 
 	return cmd
@@ -57,10 +57,13 @@ func internalOCIHookAction(cmd *cobra.Command, args []string) error {
 	cniPath := globalOptions.CNIPath
 	cniNetconfpath := globalOptions.CNINetConfPath
 	bridgeIP := globalOptions.BridgeIP
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
 	return ocihook.Run(os.Stdin, os.Stderr, event,
 		dataStore,
 		cniPath,
 		cniNetconfpath,
 		bridgeIP,
+		nerdctlCmd,
+		nerdctlArgs,
 	)
 }
@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/manifest"
+)
+
+func createCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "create [flags] MANIFEST_LIST MANIFEST [MANIFEST...]",
+		Args:              cobra.MinimumNArgs(2),
+		Short:             "Create a local manifest list from local images or remote references",
+		RunE:              createAction,
+		ValidArgsFunction: imageShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().BoolP("amend", "a", false, "Amend an existing manifest list")
+	return cmd
+}
+
+func createOptions(cmd *cobra.Command) (types.ManifestCreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ManifestCreateOptions{}, err
+	}
+	amend, err := cmd.Flags().GetBool("amend")
+	if err != nil {
+		return types.ManifestCreateOptions{}, err
+	}
+	return types.ManifestCreateOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Amend:    amend,
+	}, nil
+}
+
+func createAction(cmd *cobra.Command, args []string) error {
+	options, err := createOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return manifest.Create(ctx, client, args[0], args[1:], options)
+}
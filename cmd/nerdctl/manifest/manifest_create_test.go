@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestManifestCreate(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		data.Labels().Set("list", "example.com/"+data.Identifier("list")+":latest")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("manifest", "rm", data.Labels().Get("list"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "create and inspect",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("manifest", "create", data.Labels().Get("list"), testutil.CommonImage)
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("manifest", "inspect", data.Labels().Get("list"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, func(stdout string, info string, t *testing.T) {
+				var index ocispec.Index
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &index), info)
+				assert.Assert(t, len(index.Manifests) > 0, info)
+				for _, m := range index.Manifests {
+					assert.Assert(t, m.Platform != nil && m.Platform.OS != "unknown", info)
+				}
+			}),
+		},
+		{
+			Description: "create without amend fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("manifest", "create", data.Labels().Get("list"), testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "annotate",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				var index ocispec.Index
+				assert.NilError(helpers.T(), json.Unmarshal([]byte(helpers.Capture("manifest", "inspect", data.Labels().Get("list"))), &index))
+				data.Labels().Set("digest", index.Manifests[0].Digest.String())
+				helpers.Ensure("manifest", "annotate", "--os-version", "10.0.1", data.Labels().Get("list"), data.Labels().Get("digest"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("manifest", "inspect", data.Labels().Get("list"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains(`"os.version": "10.0.1"`)),
+		},
+		{
+			Description: "rm",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("manifest", "rm", data.Labels().Get("list"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("Deleted")),
+		},
+		{
+			Description: "rm refuses images",
+			Command:     test.Command("manifest", "rm", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/manifest"
+)
+
+func removeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rm [flags] MANIFEST_LIST [MANIFEST_LIST...]",
+		Aliases:           []string{"remove"},
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Remove one or more local manifest lists",
+		RunE:              removeAction,
+		ValidArgsFunction: imageShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func removeOptions(cmd *cobra.Command) (types.ManifestRemoveOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ManifestRemoveOptions{}, err
+	}
+	return types.ManifestRemoveOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	}, nil
+}
+
+func removeAction(cmd *cobra.Command, args []string) error {
+	options, err := removeOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return manifest.Remove(ctx, client, args, options)
+}
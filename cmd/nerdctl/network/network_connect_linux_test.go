@@ -0,0 +1,137 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestNetworkConnectDisconnect(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = nerdtest.Rootful
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", data.Identifier())
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		data.Labels().Set("network", data.Identifier())
+		data.Labels().Set("container", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("network", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "connect attaches a new interface",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "connect", data.Labels().Get("network"), data.Labels().Get("container"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("container"), "ip", "addr", "show", "dev", "eth1")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("inet ")),
+		},
+		{
+			Description: "connecting twice fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "connect", data.Labels().Get("network"), data.Labels().Get("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("already connected")}, nil),
+		},
+		{
+			Description: "connection survives a restart",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("restart", data.Labels().Get("container"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("container"), "ip", "addr", "show", "dev", "eth1")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("inet ")),
+		},
+		{
+			Description: "disconnect removes the interface",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "disconnect", data.Labels().Get("network"), data.Labels().Get("container"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("container"), "ip", "addr", "show", "dev", "eth1")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "disconnecting a network that is not connected fails",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "disconnect", data.Labels().Get("network"), data.Labels().Get("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("is not connected")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestNetworkConnectReleasesAddressOnStop(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = nerdtest.Rootful
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", data.Identifier())
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("network", "connect", data.Identifier(), data.Identifier())
+		// e.g., "3: eth1    inet 10.4.1.2/24 brd 10.4.1.255 scope global eth1 ..."
+		fields := strings.Fields(helpers.Capture("exec", data.Identifier(), "ip", "-4", "-o", "addr", "show", "dev", "eth1"))
+		i := slices.Index(fields, "inet")
+		assert.Assert(helpers.T(), i >= 0 && i+1 < len(fields), "no IPv4 address on eth1")
+		data.Labels().Set("ip", strings.Split(fields[i+1], "/")[0])
+		helpers.Ensure("stop", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier(), data.Identifier("second"))
+		helpers.Anyhow("network", "rm", data.Identifier())
+	}
+
+	// The address can only be reused if the connected network was removed when the container stopped
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("run", "--rm", "--name", data.Identifier("second"), "--network", data.Identifier(),
+			"--ip", data.Labels().Get("ip"), testutil.CommonImage, "true")
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeSuccess, nil, nil)
+
+	testCase.Run(t)
+}
@@ -17,6 +17,7 @@
 package network
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
@@ -110,3 +112,52 @@ func TestNetworkCreate(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestNetworkCreateInternal(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", "--internal", data.Identifier())
+		netw := nerdtest.InspectNetwork(helpers, data.Identifier())
+		assert.Assert(t, netw.Internal)
+		data.Labels().Set("network", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("network", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "no default route",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--net", data.Labels().Get("network"), testutil.CommonImage, "ip", "route")
+			},
+			Expected: test.Expects(0, nil, expect.DoesNotContain("default")),
+		},
+		{
+			Description: "no port publishing",
+			Require:     require.Not(nerdtest.Docker),
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--net", data.Labels().Get("network"), "-p", "8080:80", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "no port publishing through network connect",
+			Require:     require.Not(nerdtest.Docker),
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("create", "--name", data.Identifier("container"), "-p", "8080:80", testutil.CommonImage, "true")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("container"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "connect", data.Labels().Get("network"), data.Identifier("container"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("internal network")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -36,6 +36,10 @@ func Command() *cobra.Command {
 		EventsCommand(),
 		InfoCommand(),
 		pruneCommand(),
+		dfCommand(),
+		restartSupervisorCommand(),
+		metricsCommand(),
+		recordEventsCommand(),
 	)
 	return cmd
 }
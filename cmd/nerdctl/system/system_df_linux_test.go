@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemDf(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", data.Identifier())
+		helpers.Ensure("run", "-v", fmt.Sprintf("%s:/volume", data.Identifier()),
+			"--name", data.Identifier(), testutil.CommonImage, "sh", "-c", "echo hello > /volume/hello")
+		data.Labels().Set("volume", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "summary",
+			Command:     test.Command("system", "df"),
+			Expected: test.Expects(expect.ExitCodeSuccess, nil,
+				expect.Contains("TYPE", "RECLAIMABLE", "Images", "Containers", "Local Volumes", "Build Cache")),
+		},
+		{
+			Description: "summary with format",
+			Command:     test.Command("system", "df", "--format", "{{.Type}}={{.TotalCount}}"),
+			Expected: test.Expects(expect.ExitCodeSuccess, nil,
+				expect.Contains("Images=", "Containers=", "Local Volumes=", "Build Cache=")),
+		},
+		{
+			Description: "verbose",
+			Command:     test.Command("system", "df", "-v"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains("Images space usage:", "Containers space usage:", "Local Volumes space usage:", data.Labels().Get("volume")),
+				}
+			},
+		},
+		{
+			Description: "verbose with json format",
+			Command:     test.Command("system", "df", "-v", "--format", "json"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, info string, t *testing.T) {
+						var report struct {
+							Volumes []struct {
+								Name  string
+								Links int
+							}
+						}
+						assert.NilError(t, json.Unmarshal([]byte(stdout), &report), info)
+						found := false
+						for _, v := range report.Volumes {
+							if v.Name == data.Labels().Get("volume") {
+								found = true
+								assert.Equal(t, v.Links, 1, info)
+							}
+						}
+						assert.Assert(t, found, info)
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -27,7 +27,10 @@ import (
 
 func EventsCommand() *cobra.Command {
 	shortHelp := `Get real time events from the server`
-	longHelp := shortHelp + "\nNOTE: The output format is not compatible with Docker."
+	longHelp := shortHelp + `
+The events are recorded in a bounded journal under the data root while they are streamed,
+and the past events are replayed from the journal when --since or --until is specified.
+NOTE: The default output format is not compatible with Docker, use "--format json" for a Docker-compatible output.`
 	var cmd = &cobra.Command{
 		Use:           "events",
 		Args:          cobra.NoArgs,
@@ -42,6 +45,8 @@ func EventsCommand() *cobra.Command {
 		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.Flags().StringSliceP("filter", "f", []string{}, "Filter matches containers based on given conditions")
+	cmd.Flags().String("since", "", "Show all events created since timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
+	cmd.Flags().String("until", "", "Stream events until this timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
 	return cmd
 }
 
@@ -58,11 +63,21 @@ func eventsOptions(cmd *cobra.Command) (types.SystemEventsOptions, error) {
 	if err != nil {
 		return types.SystemEventsOptions{}, err
 	}
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return types.SystemEventsOptions{}, err
+	}
+	until, err := cmd.Flags().GetString("until")
+	if err != nil {
+		return types.SystemEventsOptions{}, err
+	}
 	return types.SystemEventsOptions{
 		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
 		Format:   format,
 		Filters:  filters,
+		Since:    since,
+		Until:    until,
 	}, nil
 }
 
@@ -17,6 +17,7 @@
 package system
 
 import (
+	"os"
 	"testing"
 	"time"
 
@@ -115,6 +116,97 @@ func TestEventFilters(t *testing.T) {
 				"output": "\"Status\":\"unknown\"",
 			}),
 		},
+		{
+			Description: "DockerCompatibleJSON",
+			Command:     testEventFilterExecutor,
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeTimeout,
+					Output:   expect.Contains(`"Type":"container"`, `"Action":"start"`, `"Actor":{"ID":"`),
+				}
+			},
+			Data: test.WithLabels(map[string]string{
+				"filter": "event=start",
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestEventsReplay(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	var recorder test.TestableCommand
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		recorder = helpers.Command("system", "record-events")
+		recorder.WithTimeout(30 * time.Second)
+		recorder.Background()
+		// Let the recorder subscribe to the events
+		time.Sleep(time.Second)
+		helpers.Anyhow("run", "--rm", "--name", data.Identifier(), "-v", data.Identifier()+":/data",
+			testutil.CommonImage, "sh", "-c", "exit 3")
+		// Let the recorder write the events
+		time.Sleep(time.Second)
+		data.Labels().Set("name", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if recorder != nil {
+			recorder.Signal(os.Kill)
+		}
+		helpers.Anyhow("volume", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "die event with exit code",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("events", "--since", "5m", "--until", "0s", "--format", "json", "--filter", "event=die")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						`"Type":"container"`,
+						`"Action":"die"`,
+						`"exitCode":"3"`,
+						`"name":"`+data.Labels().Get("name")+`"`,
+						`"image":"`,
+					),
+				}
+			},
+		},
+		{
+			Description: "volume events",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("events", "--since", "5m", "--until", "0s", "--format", "json", "--filter", "type=volume")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						`"Action":"mount"`,
+						`"Action":"unmount"`,
+						`"ID":"`+data.Labels().Get("name")+`"`,
+						`"destination":"/data"`,
+					),
+				}
+			},
+		},
+		{
+			Description: "nothing after until",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("events", "--until", "5m", "--format", "json", "--filter", "event=die")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.DoesNotContain(`"name":"` + data.Labels().Get("name") + `"`),
+				}
+			},
+		},
 	}
 
 	testCase.Run(t)
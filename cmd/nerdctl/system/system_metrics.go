@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func metricsCommand() *cobra.Command {
+	shortHelp := `Serve the resource usage statistics of the running containers as Prometheus metrics`
+	longHelp := shortHelp + `
+The metrics are served on the "/metrics" path, in the Prometheus text exposition format, and are labeled with the
+name, ID, image, namespace and compose project/service of the containers.
+The same metrics can be printed once with "nerdctl stats --no-stream --format prometheus".`
+	var cmd = &cobra.Command{
+		Use:           "metrics",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          metricsAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("listen", ":9323", "Address to serve the metrics on")
+	return cmd
+}
+
+func metricsAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	listen, err := cmd.Flags().GetString("listen")
+	if err != nil {
+		return err
+	}
+	options := types.SystemMetricsOptions{
+		GOptions: globalOptions,
+		Listen:   listen,
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return system.Metrics(ctx, client, options)
+}
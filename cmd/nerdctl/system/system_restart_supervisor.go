@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func restartSupervisorCommand() *cobra.Command {
+	shortHelp := `Enforce the restart policies of the containers created with --restart-supervisor=nerdctl`
+	longHelp := shortHelp + `
+The supervisor runs in the foreground, typically as a systemd service, and restarts the containers of the namespace
+with Docker's exponential backoff (100ms, doubled after each restart up to 1 minute, reset after 10 seconds of run).
+Stopped containers that have to be running are started when the supervisor starts.`
+	var cmd = &cobra.Command{
+		Use:           "restart-supervisor",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          restartSupervisorAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	return cmd
+}
+
+func restartSupervisorAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	options := types.SystemRestartSupervisorOptions{
+		GOptions: globalOptions,
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	return system.RestartSupervisor(ctx, client, options)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func createCommand() *cobra.Command {
@@ -38,6 +39,8 @@ func createCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().StringArray("label", nil, "Set a label on the volume")
+	cmd.Flags().StringP("driver", "d", "local", "Volume driver to use")
+	cmd.Flags().StringArrayP("opt", "o", nil, "Set driver specific options (type, device, o, size)")
 	return cmd
 }
 
@@ -55,10 +58,20 @@ func createOptions(cmd *cobra.Command) (types.VolumeCreateOptions, error) {
 			return types.VolumeCreateOptions{}, fmt.Errorf("labels cannot be empty (%w)", errdefs.ErrInvalidArgument)
 		}
 	}
+	driver, err := cmd.Flags().GetString("driver")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+	opts, err := cmd.Flags().GetStringArray("opt")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
 
 	return types.VolumeCreateOptions{
 		GOptions: globalOptions,
 		Labels:   labels,
+		Driver:   driver,
+		Options:  strutil.ConvertKVStringsToMap(opts),
 		Stdout:   cmd.OutOrStdout(),
 	}, nil
 }
@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestVolumeCreateWithOptions(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Rootless)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "tmpfs volume with size",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("volume", "create", "--opt", "type=tmpfs", "--opt", "device=tmpfs", "--opt", "o=size=16m", data.Identifier())
+				vol := nerdtest.InspectVolume(helpers, data.Identifier())
+				assert.Equal(t, vol.Driver, "local")
+				assert.DeepEqual(t, vol.Options, map[string]string{"type": "tmpfs", "device": "tmpfs", "o": "size=16m"})
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier()+":/mnt", testutil.CommonImage, "df", "-k", "/mnt")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("tmpfs", "16384")),
+		},
+		{
+			Description: "bind volume",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				device := data.Temp().Dir("device")
+				data.Temp().Save("hello", "device", "hello.txt")
+				helpers.Ensure("volume", "create", "--opt", "type=none", "--opt", "device="+device, "--opt", "o=bind", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "-v", data.Identifier()+":/mnt", testutil.CommonImage, "cat", "/mnt/hello.txt")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("hello")),
+		},
+		{
+			Description: "size is only supported for tmpfs",
+			Require:     require.Not(nerdtest.Docker),
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "create", "--opt", "type=ext4", "--opt", "device=/dev/null", "--opt", "size=16m", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -44,6 +44,7 @@ require (
 	github.com/ipfs/go-cid v0.5.0
 	github.com/klauspost/compress v1.18.0
 	github.com/mattn/go-isatty v0.0.20 //gomodjail:unconfined
+	github.com/moby/patternmatcher v0.6.0
 	github.com/moby/sys/mount v0.3.4
 	github.com/moby/sys/signal v0.7.1
 	github.com/moby/sys/user v0.4.0 //gomodjail:unconfined
@@ -68,6 +69,8 @@ require (
 	golang.org/x/sys v0.33.0 //gomodjail:unconfined
 	golang.org/x/term v0.32.0 //gomodjail:unconfined
 	golang.org/x/text v0.25.0
+	google.golang.org/grpc v1.72.0 //gomodjail:unconfined
+	google.golang.org/protobuf v1.36.6 //gomodjail:unconfined
 	gopkg.in/yaml.v3 v3.0.1
 	gotest.tools/v3 v3.5.2
 	tags.cncf.io/container-device-interface v1.0.1 //gomodjail:unconfined
@@ -141,10 +144,6 @@ require (
 	golang.org/x/exp v0.0.0-20241108190413-2d47ceb2692f // indirect
 	golang.org/x/mod v0.24.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
-	//gomodjail:unconfined
-	google.golang.org/grpc v1.72.0 // indirect
-	//gomodjail:unconfined
-	google.golang.org/protobuf v1.36.6 // indirect
 	lukechampine.com/blake3 v1.3.0 // indirect
 	sigs.k8s.io/yaml v1.4.0 // indirect
 	tags.cncf.io/container-device-interface/specs-go v1.0.0 // indirect
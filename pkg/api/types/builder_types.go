@@ -87,3 +87,12 @@ type BuilderPruneOptions struct {
 	// Force will not prompt for confirmation.
 	Force bool
 }
+
+// BuilderDiskUsageOptions specifies options for querying the disk usage of the build cache.
+type BuilderDiskUsageOptions struct {
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// BuildKitHost is the buildkit host
+	BuildKitHost string
+}
@@ -46,4 +46,12 @@ type NetworkOptions struct {
 	UTSNamespace string
 	// PortMappings specifies a list of ports to publish from the container to the host
 	PortMappings []cni.PortMapping
+	// ExposedPorts specifies a list of ports to expose without publishing, as "<port>/<proto>"
+	ExposedPorts []string
+	// PublishAll publishes all the exposed ports to random ports on the host
+	PublishAll bool
+	// NetworkAliases maps the networks to the network-scoped aliases of the container
+	NetworkAliases map[string][]string
+	// Links specifies the containers to link to, as "<name|id>[:<alias>]"
+	Links []string
 }
@@ -32,6 +32,8 @@ type ContainerStartOptions struct {
 	DetachKeys string
 	// Attach stdin
 	Interactive bool
+	// Checkpoint is the name of a checkpoint of the container, or the reference of a checkpoint image, to restore from
+	Checkpoint string
 }
 
 // ContainerKillOptions specifies options for `nerdctl (container) kill`.
@@ -154,6 +156,8 @@ type ContainerCreateOptions struct {
 	Device []string
 	// CDIDevices specifies the CDI devices to add to the container
 	CDIDevices []string
+	// DeviceCgroupRules specifies the rules to add to the cgroup allowed devices list (e.g. "c 1:3 rwm")
+	DeviceCgroupRules []string
 	// #endregion
 
 	// #region for blkio related flags
@@ -283,6 +287,23 @@ type ContainerCreateOptions struct {
 	IPFSAddress string
 	// #endregion
 
+	// #region for healthcheck flags
+	// HealthCmd specifies the command to run to check health
+	HealthCmd string
+	// HealthInterval specifies the time between running the check (default 30s)
+	HealthInterval time.Duration
+	// HealthTimeout specifies the maximum time to allow one check to run (default 30s)
+	HealthTimeout time.Duration
+	// HealthStartPeriod specifies the start period for the container to initialize before starting health-retries countdown
+	HealthStartPeriod time.Duration
+	// HealthStartInterval specifies the time between running the check during the start period (default 5s)
+	HealthStartInterval time.Duration
+	// HealthRetries specifies the consecutive failures needed to report unhealthy (default 3)
+	HealthRetries int
+	// NoHealthcheck disables any container-specified HEALTHCHECK
+	NoHealthcheck bool
+	// #endregion
+
 	// ImagePullOpt specifies image pull options which holds the ImageVerifyOptions for verifying the image.
 	ImagePullOpt ImagePullOptions
 
@@ -290,6 +311,40 @@ type ContainerCreateOptions struct {
 	UserNS string
 }
 
+// ContainerCheckpointCreateOptions specifies options for `nerdctl container checkpoint create`.
+type ContainerCheckpointCreateOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// LeaveRunning leaves the container running after the checkpoint
+	LeaveRunning bool
+}
+
+// ContainerCheckpointListOptions specifies options for `nerdctl container checkpoint ls`.
+type ContainerCheckpointListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Quiet only prints the checkpoint names
+	Quiet bool
+	// Format the output using the given go template
+	Format string
+}
+
+// ContainerCheckpointRemoveOptions specifies options for `nerdctl container checkpoint rm`.
+type ContainerCheckpointRemoveOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
+// ContainerHealthCheckOptions specifies options for `nerdctl container healthcheck`.
+type ContainerHealthCheckOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
 // ContainerStopOptions specifies options for `nerdctl (container) stop`.
 type ContainerStopOptions struct {
 	Stdout io.Writer
@@ -387,6 +442,13 @@ type ContainerCommitOptions struct {
 	Pause bool
 }
 
+// ContainerExportOptions specifies options for `nerdctl (container) export`.
+type ContainerExportOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
 // ContainerDiffOptions specifies options for `nerdctl (container) diff`.
 type ContainerDiffOptions struct {
 	Stdout io.Writer
@@ -413,6 +475,19 @@ type ContainerLogsOptions struct {
 	Until string
 	// Details specifies whether to show extra details provided to logs
 	Details bool
+	// Filters selects the containers to show the logs of, like `nerdctl ps --filter`.
+	// The logs of multiple containers are merged in timestamp order and prefixed with the container names.
+	Filters []string
+	// NoColor disables the colors of the container name prefixes
+	NoColor bool
+	// NoLogPrefix disables the container name prefixes
+	NoLogPrefix bool
+	// Grep is a regular expression that the lines have to match to be shown
+	Grep string
+	// Stream is the only stream to show, "stdout" or "stderr"
+	Stream string
+	// Format is the output format, "" for the raw lines or "json" for one JSON record per line
+	Format string
 }
 
 // ContainerWaitOptions specifies options for `nerdctl (container) wait`.
@@ -501,4 +576,12 @@ type ContainerStatsOptions struct {
 	NoStream bool
 	// Do not truncate output.
 	NoTrunc bool
+	// Interval between two refreshes of the output and two recorded samples (default 500ms).
+	Interval time.Duration
+	// Record the samples to the given file.
+	Record string
+	// RecordFormat is the format of the record file, "jsonl" or "csv" (default: guessed from the file extension).
+	RecordFormat string
+	// Replay summarizes the samples recorded in the given file instead of collecting stats.
+	Replay string
 }
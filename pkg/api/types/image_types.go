@@ -255,6 +255,23 @@ type ImageSaveOptions struct {
 	Platform []string
 }
 
+// ImageImportOptions specifies options for `nerdctl (image) import`.
+type ImageImportOptions struct {
+	Stdout   io.Writer
+	Stdin    io.Reader
+	GOptions GlobalCommandOptions
+	// Source is the path or the URL of the tarball to import, or "-" to read from STDIN
+	Source string
+	// Reference is the name of the image to create (optional)
+	Reference string
+	// Apply Dockerfile instruction to the created image (supported directives: [CMD, ENTRYPOINT])
+	Change []string
+	// Message is the commit message of the imported image
+	Message string
+	// Platform is the platform of the imported image (e.g., "linux/arm64")
+	Platform string
+}
+
 // ImageSignOptions contains options for signing an image. It contains options from
 // all providers. The `provider` field determines which provider is used.
 type ImageSignOptions struct {
@@ -35,6 +35,8 @@ type NetworkCreateOptions struct {
 	IPRange     string
 	Labels      []string
 	IPv6        bool
+	// Internal restricts external access to the network
+	Internal bool
 }
 
 // NetworkInspectOptions specifies options for `nerdctl network inspect`.
@@ -80,3 +82,25 @@ type NetworkRemoveOptions struct {
 	// Networks are the networks to be removed
 	Networks []string
 }
+
+// NetworkConnectOptions specifies options for `nerdctl network connect`.
+type NetworkConnectOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Network is the network to connect the container to
+	Network string
+	// Container is the container to be connected
+	Container string
+}
+
+// NetworkDisconnectOptions specifies options for `nerdctl network disconnect`.
+type NetworkDisconnectOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Network is the network to disconnect the container from
+	Network string
+	// Container is the container to be disconnected
+	Container string
+	// Force disconnects the container even if it is not recorded as connected to the network
+	Force bool
+}
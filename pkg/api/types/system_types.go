@@ -39,6 +39,16 @@ type SystemEventsOptions struct {
 	Format string
 	// Filter events based on given conditions
 	Filters []string
+	// Since replays the events recorded since the given timestamp or relative time
+	Since string
+	// Until stops streaming the events at the given timestamp or relative time
+	Until string
+}
+
+// SystemRecordEventsOptions specifies options for `nerdctl system record-events`.
+type SystemRecordEventsOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
 }
 
 // SystemPruneOptions specifies options for `nerdctl system prune`.
@@ -56,3 +66,31 @@ type SystemPruneOptions struct {
 	// NetworkDriversToKeep the network drivers which need to keep
 	NetworkDriversToKeep []string
 }
+
+// SystemDfOptions specifies options for `nerdctl system df`.
+type SystemDfOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Verbose shows detailed information on space usage
+	Verbose bool
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+	// BuildKitHost the address of BuildKit host
+	BuildKitHost string
+}
+
+// SystemRestartSupervisorOptions specifies options for `nerdctl system restart-supervisor`.
+type SystemRestartSupervisorOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
+// SystemMetricsOptions specifies options for `nerdctl system metrics`.
+type SystemMetricsOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Listen is the address to serve the metrics on, e.g., ":9323"
+	Listen string
+}
@@ -24,6 +24,10 @@ type VolumeCreateOptions struct {
 	GOptions GlobalCommandOptions
 	// Labels are the volume labels
 	Labels []string
+	// Driver is the volume driver. Only "local" is supported.
+	Driver string
+	// Options are the driver specific options, e.g., {"type": "tmpfs", "device": "tmpfs", "o": "uid=1000"}
+	Options map[string]string
 }
 
 // VolumeInspectOptions specifies options for `nerdctl volume inspect`.
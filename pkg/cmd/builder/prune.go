@@ -40,9 +40,25 @@ func Prune(ctx context.Context, options types.BuilderPruneOptions) ([]buildkitut
 	if options.All {
 		buildctlArgs = append(buildctlArgs, "--all")
 	}
+	return runBuildctlUsageInfo(ctx, buildctlBinary, buildctlArgs, options.Stderr)
+}
+
+// DiskUsage returns the build cache records.
+func DiskUsage(ctx context.Context, options types.BuilderDiskUsageOptions) ([]buildkitutil.UsageInfo, error) {
+	buildctlBinary, err := buildkitutil.BuildctlBinary()
+	if err != nil {
+		return nil, err
+	}
+	buildctlArgs := buildkitutil.BuildctlBaseArgs(options.BuildKitHost)
+	buildctlArgs = append(buildctlArgs, "du", "--format={{json .}}")
+	return runBuildctlUsageInfo(ctx, buildctlBinary, buildctlArgs, options.Stderr)
+}
+
+// runBuildctlUsageInfo runs buildctl and decodes the usage records it prints as JSON.
+func runBuildctlUsageInfo(ctx context.Context, buildctlBinary string, buildctlArgs []string, stderr io.Writer) ([]buildkitutil.UsageInfo, error) {
 	buildctlCmd := exec.Command(buildctlBinary, buildctlArgs...)
 	log.G(ctx).Debugf("running %v", buildctlCmd.Args)
-	buildctlCmd.Stderr = options.Stderr
+	buildctlCmd.Stderr = stderr
 	stdout, err := buildctlCmd.StdoutPipe()
 	if err != nil {
 		return nil, fmt.Errorf("faild to get stdout piper for %v: %w", buildctlCmd.Args, err)
@@ -30,6 +30,7 @@ import (
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/composer"
 	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
@@ -87,6 +88,11 @@ func New(client *containerd.Client, globalOptions types.GlobalCommandOptions, op
 	// FIXME: this is racy. See note in up_volume.go
 	options.VolumeExists = volStore.Exists
 
+	options.DataStore, err = clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+
 	options.ImageExists = func(ctx context.Context, rawRef string) (bool, error) {
 		parsedReference, err := referenceutil.Parse(rawRef)
 		if err != nil {
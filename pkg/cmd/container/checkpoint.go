@@ -0,0 +1,364 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	runcoptions "github.com/containerd/containerd/api/types/runc/options"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// checkpointRepositoryPrefix is the prefix of the repository of the images storing the checkpoints of a container.
+// The "localhost" domain prevents a checkpoint from being pushed by accident: it has to be tagged first.
+const checkpointRepositoryPrefix = "localhost/nerdctl-checkpoint/"
+
+// checkpointNameRegexp matches valid checkpoint names, which are used as image tags.
+var checkpointNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+func checkpointImageName(containerID, checkpoint string) string {
+	return checkpointRepositoryPrefix + containerID + ":" + checkpoint
+}
+
+// CheckpointCreate checkpoints the running container with CRIU, and stores the checkpoint as an image.
+func CheckpointCreate(ctx context.Context, client *containerd.Client, req, checkpoint string, options types.ContainerCheckpointCreateOptions) error {
+	if !checkpointNameRegexp.MatchString(checkpoint) {
+		return fmt.Errorf("invalid checkpoint name %q: must match %s", checkpoint, checkpointNameRegexp)
+	}
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			img, err := createCheckpoint(ctx, found.Container, checkpoint, options.LeaveRunning)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(options.Stdout, img.Name())
+			return err
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+func createCheckpoint(ctx context.Context, container containerd.Container, checkpoint string, leaveRunning bool) (_ containerd.Image, retErr error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, fmt.Errorf("container %s is not running", container.ID())
+		}
+		return nil, err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status.Status != containerd.Running {
+		return nil, fmt.Errorf("container %s is not running", container.ID())
+	}
+
+	opts := []containerd.CheckpointOpts{
+		// The network namespace is not dumped: on restore, it is set up again by the OCI hook.
+		withCheckpointEmptyNamespace("network"),
+		containerd.WithCheckpointRuntime,
+		containerd.WithCheckpointRW,
+	}
+	if !leaveRunning {
+		// Prevent the restart manager from restarting the container after the checkpoint.
+		// The labels have to be set before the task exits, and are restored if the checkpoint fails.
+		hasPolicy, err := setCheckpointStoppedLabels(ctx, container)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if retErr == nil {
+				return
+			}
+			if err := restoreCheckpointStoppedLabels(ctx, container, hasPolicy); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to restore the labels of container %s", container.ID())
+			}
+		}()
+		opts = append(opts, containerd.WithCheckpointTaskExit)
+	}
+	opts = append(opts, containerd.WithCheckpointTask)
+
+	img, err := container.Checkpoint(ctx, checkpointImageName(container.ID(), checkpoint), opts...)
+	if err != nil {
+		if errdefs.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("checkpoint %s already exists for container %s", checkpoint, container.ID())
+		}
+		return nil, err
+	}
+	return img, nil
+}
+
+// setCheckpointStoppedLabels marks the container as explicitly stopped, and as stopped if it has a restart policy,
+// which is returned.
+func setCheckpointStoppedLabels(ctx context.Context, container containerd.Container) (bool, error) {
+	if err := containerutil.UpdateExplicitlyStoppedLabel(ctx, container, true); err != nil {
+		return false, err
+	}
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := l[restart.PolicyLabel]; !ok {
+		return false, nil
+	}
+	return true, containerutil.UpdateStatusLabel(ctx, container, containerd.Stopped)
+}
+
+// restoreCheckpointStoppedLabels reverts setCheckpointStoppedLabels, for a container that is still running.
+func restoreCheckpointStoppedLabels(ctx context.Context, container containerd.Container, hasPolicy bool) error {
+	if err := containerutil.UpdateExplicitlyStoppedLabel(ctx, container, false); err != nil {
+		return err
+	}
+	if !hasPolicy {
+		return nil
+	}
+	return containerutil.UpdateStatusLabel(ctx, container, containerd.Running)
+}
+
+func withCheckpointEmptyNamespace(ns string) containerd.CheckpointOpts {
+	return func(ctx context.Context, client *containerd.Client, c *containers.Container, index *ocispec.Index, copts *runcoptions.CheckpointOptions) error {
+		copts.EmptyNamespaces = append(copts.EmptyNamespaces, ns)
+		return nil
+	}
+}
+
+type checkpointPrintable struct {
+	Name      string
+	Image     string
+	CreatedAt string
+}
+
+// CheckpointList lists the checkpoints of a container.
+func CheckpointList(ctx context.Context, client *containerd.Client, req string, options types.ContainerCheckpointListOptions) error {
+	var tmpl *template.Template
+	w := options.Stdout
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "CHECKPOINT NAME\tIMAGE\tCREATED")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	var pp []checkpointPrintable
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			var err error
+			pp, err = listCheckpoints(ctx, client, found.Container.ID())
+			return err
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+
+	for _, p := range pp {
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.Name)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Image, p.CreatedAt)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func listCheckpoints(ctx context.Context, client *containerd.Client, containerID string) ([]checkpointPrintable, error) {
+	imgs, err := client.ImageService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo := checkpointRepositoryPrefix + containerID + ":"
+	var pp []checkpointPrintable
+	for _, img := range imgs {
+		name, ok := strings.CutPrefix(img.Name, repo)
+		if !ok {
+			continue
+		}
+		pp = append(pp, checkpointPrintable{
+			Name:      name,
+			Image:     img.Name,
+			CreatedAt: formatter.TimeSinceInHuman(img.CreatedAt),
+		})
+	}
+	sort.Slice(pp, func(i, j int) bool {
+		return pp[i].Name < pp[j].Name
+	})
+	return pp, nil
+}
+
+// CheckpointRemove removes checkpoints of a container.
+func CheckpointRemove(ctx context.Context, client *containerd.Client, req string, checkpoints []string, options types.ContainerCheckpointRemoveOptions) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			var errs []error
+			for _, checkpoint := range checkpoints {
+				err := client.ImageService().Delete(ctx, checkpointImageName(found.Container.ID(), checkpoint))
+				if err != nil {
+					if errdefs.IsNotFound(err) {
+						err = fmt.Errorf("no such checkpoint %s for container %s", checkpoint, found.Req)
+					}
+					errs = append(errs, err)
+					continue
+				}
+				fmt.Fprintln(options.Stdout, checkpoint)
+			}
+			return errors.Join(errs...)
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+// ensureRestorable returns an error unless the container has no task, or a stopped one.
+// The rootfs changes of a checkpoint must never be applied to the rootfs of a live container.
+func ensureRestorable(ctx context.Context, container containerd.Container) error {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Status != containerd.Stopped {
+		return fmt.Errorf("cannot restore container %s from a checkpoint: the container is %s, stop it first", container.ID(), status.Status)
+	}
+	return nil
+}
+
+// checkpointTaskOpts returns the options to restore the task of the container from a checkpoint.
+// checkpoint is either the name of a checkpoint of the container, or the reference of a checkpoint image
+// (e.g., a checkpoint of another container, possibly pulled from a registry).
+// The rootfs changes stored in the checkpoint are applied to the rootfs of the container.
+func checkpointTaskOpts(ctx context.Context, client *containerd.Client, container containerd.Container, checkpoint string) ([]containerd.NewTaskOpts, error) {
+	img, err := client.GetImage(ctx, checkpointImageName(container.ID(), checkpoint))
+	if errdefs.IsNotFound(err) {
+		parsed, parseErr := referenceutil.Parse(checkpoint)
+		if parseErr != nil {
+			return nil, fmt.Errorf("no such checkpoint %s for container %s", checkpoint, container.ID())
+		}
+		img, err = client.GetImage(ctx, parsed.String())
+		if errdefs.IsNotFound(err) {
+			return nil, fmt.Errorf("no such checkpoint %s for container %s", checkpoint, container.ID())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := content.ReadBlob(ctx, client.ContentStore(), img.Target())
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(p, &index); err != nil {
+		return nil, fmt.Errorf("image %s is not a checkpoint: %w", img.Name(), err)
+	}
+	for _, desc := range index.Manifests {
+		if desc.MediaType != images.MediaTypeContainerd1RW {
+			continue
+		}
+		info, err := container.Info(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mounts, err := client.SnapshotService(info.Snapshotter).Mounts(ctx, info.SnapshotKey)
+		if err != nil {
+			return nil, err
+		}
+		log.G(ctx).Debugf("applying the rootfs changes of checkpoint %s", img.Name())
+		if _, err := client.DiffService().Apply(ctx, desc, mounts); err != nil {
+			return nil, fmt.Errorf("failed to apply the rootfs changes of checkpoint %s: %w", img.Name(), err)
+		}
+	}
+	return []containerd.NewTaskOpts{containerd.WithTaskCheckpoint(img)}, nil
+}
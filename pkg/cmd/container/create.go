@@ -26,10 +26,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 
 	dockercliopts "github.com/docker/cli/opts"
+	"github.com/docker/go-units"
 	"github.com/opencontainers/runtime-spec/specs-go"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -56,8 +58,10 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/logging"
 	"github.com/containerd/nerdctl/v2/pkg/maputil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/store"
@@ -65,7 +69,7 @@ import (
 )
 
 // Create will create a container.
-func Create(ctx context.Context, client *containerd.Client, args []string, netManager containerutil.NetworkOptionsManager, options types.ContainerCreateOptions) (containerd.Container, func(), error) {
+func Create(ctx context.Context, client *containerd.Client, args []string, netManager containerutil.NetworkOptionsManager, options types.ContainerCreateOptions) (_ containerd.Container, _ func(), retErr error) {
 	// Acquire an exclusive lock on the volume store until we are done to avoid being raced by any other
 	// volume operations (or any other operation involving volume manipulation)
 	volStore, err := volume.Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
@@ -76,6 +80,14 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	if err != nil {
 		return nil, nil, err
 	}
+	// The volumes created with driver options are mounted while parsing the mount flags, so, they have to be
+	// unmounted if the container could not be created.
+	// This runs after releasing the volume store, which is locked again by volume.UnmountUnused.
+	defer func() {
+		if retErr != nil {
+			unmountUnusedVolumes(ctx, client, volStore)
+		}
+	}()
 	defer volStore.Release()
 
 	// simulate the behavior of double dash
@@ -190,6 +202,11 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		internalLabels.user = options.User
 	}
 
+	internalLabels.healthcheck, err = generateHealthcheckLabel(ctx, ensuredImage, options)
+	if err != nil {
+		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+	}
+
 	rootfsOpts, rootfsCOpts, err := generateRootfsOpts(args, id, ensuredImage, options)
 	if err != nil {
 		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
@@ -268,7 +285,7 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		internalLabels.logConfig.Driver = "json-file"
 	}
 
-	restartOpts, err := generateRestartOpts(ctx, client, options.Restart, logConfig.LogURI, options.InRun)
+	restartOpts, err := generateRestartOpts(ctx, client, options.Restart, logConfig.LogURI, options.InRun, options.GOptions.RestartSupervisor)
 	if err != nil {
 		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
 	}
@@ -295,6 +312,17 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 
 	internalLabels.loadNetOpts(netLabelOpts)
 
+	internalLabels.exposedPorts, err = generateExposedPorts(ensuredImage, netLabelOpts.ExposedPorts)
+	if err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), err
+	}
+	if netLabelOpts.PublishAll {
+		internalLabels.ports, err = portutil.PublishAll(internalLabels.exposedPorts, internalLabels.ports)
+		if err != nil {
+			return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("failed to publish exposed ports: %w", err)
+		}
+	}
+
 	// NOTE: OCI hooks are currently not supported on Windows so we skip setting them altogether.
 	// The OCI hooks we define (whose logic can be found in pkg/ocihook) primarily
 	// perform network setup and teardown when using CNI networking.
@@ -679,10 +707,13 @@ type internalLabels struct {
 	ipAddress            string
 	ip6Address           string
 	ports                []cni.PortMapping
+	exposedPorts         []string
 	macAddress           string
 	dnsServers           []string
 	dnsSearchDomains     []string
 	dnsResolvConfOptions []string
+	networkAliases       map[string][]string
+	links                []string
 	// volume
 	mountPoints []*mountutil.Processed
 	anonVolumes []string
@@ -705,7 +736,16 @@ type internalLabels struct {
 	// label for device mapping set by the --device flag
 	deviceMapping []dockercompat.DeviceMapping
 
+	// label for the ulimits set by the --ulimit flag
+	ulimits []*units.Ulimit
+
+	// label for the rules set by the --device-cgroup-rule flag
+	deviceCgroupRules []string
+
 	user string
+
+	// label for the health check configuration set by the image or the --health-* flags
+	healthcheck string
 }
 
 // WithInternalLabels sets the internal labels for a container.
@@ -730,6 +770,20 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		return nil, err
 	}
 	m[labels.Networks] = string(networksJSON)
+	if len(internalLabels.networkAliases) > 0 {
+		networkAliasesJSON, err := json.Marshal(internalLabels.networkAliases)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.NetworkAliases] = string(networkAliasesJSON)
+	}
+	if len(internalLabels.links) > 0 {
+		linksJSON, err := json.Marshal(internalLabels.links)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.Links] = string(linksJSON)
+	}
 	if len(internalLabels.ports) > 0 {
 		portsJSON, err := json.Marshal(internalLabels.ports)
 		if err != nil {
@@ -737,6 +791,13 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		}
 		m[labels.Ports] = string(portsJSON)
 	}
+	if len(internalLabels.exposedPorts) > 0 {
+		exposedPortsJSON, err := json.Marshal(internalLabels.exposedPorts)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.ExposedPorts] = string(exposedPortsJSON)
+	}
 	if internalLabels.logURI != "" {
 		m[labels.LogURI] = internalLabels.logURI
 		logConfigJSON, err := json.Marshal(internalLabels.logConfig)
@@ -815,6 +876,9 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		hostConfigLabel.Devices = append(hostConfigLabel.Devices, internalLabels.deviceMapping...)
 	}
 
+	hostConfigLabel.Ulimits = internalLabels.ulimits
+	hostConfigLabel.DeviceCgroupRules = internalLabels.deviceCgroupRules
+
 	hostConfigJSON, err := json.Marshal(hostConfigLabel)
 	if err != nil {
 		return nil, err
@@ -831,6 +895,10 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		m[labels.User] = internalLabels.user
 	}
 
+	if internalLabels.healthcheck != "" {
+		m[labels.HealthCheck] = internalLabels.healthcheck
+	}
+
 	return containerd.WithAdditionalContainerLabels(m), nil
 }
 
@@ -846,6 +914,25 @@ func (il *internalLabels) loadNetOpts(opts types.NetworkOptions) {
 	il.dnsServers = opts.DNSServers
 	il.dnsSearchDomains = opts.DNSSearchDomains
 	il.dnsResolvConfOptions = opts.DNSResolvConfOptions
+	il.networkAliases = opts.NetworkAliases
+	il.links = opts.Links
+}
+
+// generateExposedPorts merges the ports exposed by the image with the ports specified by `--expose`.
+func generateExposedPorts(ensuredImage *imgutil.EnsuredImage, exposed []string) ([]string, error) {
+	var ports []string
+	if ensuredImage != nil {
+		for p := range ensuredImage.ImageConfig.ExposedPorts {
+			parsed, err := portutil.ParseFlagExpose(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exposed port %q in the image config: %w", p, err)
+			}
+			ports = append(ports, parsed...)
+		}
+	}
+	ports = append(ports, exposed...)
+	slices.Sort(ports)
+	return slices.Compact(ports), nil
 }
 
 func dockercompatMounts(mountPoints []*mountutil.Processed) []dockercompat.MountPoint {
@@ -978,6 +1065,25 @@ func generateRemoveStateDirFunc(ctx context.Context, id string, internalLabels i
 	}
 }
 
+// unmountUnusedVolumes unmounts the volumes created with driver options that are not used by any container - soft failure.
+// All these volumes are considered, as creating a container may fail before the mount points are known.
+func unmountUnusedVolumes(ctx context.Context, client *containerd.Client, volStore volumestore.VolumeStore) {
+	vols, err := volStore.List(false)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to list volumes")
+		return
+	}
+	var names []string
+	for name, vol := range vols {
+		if len(vol.Options) > 0 {
+			names = append(names, name)
+		}
+	}
+	if err := volume.UnmountUnused(ctx, client, volStore, names); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to unmount volumes %v", names)
+	}
+}
+
 func generateRemoveOrphanedDirsFunc(ctx context.Context, id, dataStore string, internalLabels internalLabels) func() {
 	return func() {
 		if rmErr := os.RemoveAll(internalLabels.stateDir); rmErr != nil {
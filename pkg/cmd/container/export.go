@@ -0,0 +1,72 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/archive"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+// Export exports the filesystem of a container as a tar archive.
+func Export(ctx context.Context, client *containerd.Client, req string, options types.ContainerExportOptions) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return exportContainer(ctx, client, found.Container, options.Stdout)
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+// exportContainer mounts the snapshot of the container read-only, and writes its whole content as a tar archive.
+// Volumes and bind mounts are not part of the snapshot, so they are not exported, like Docker.
+func exportContainer(ctx context.Context, client *containerd.Client, container containerd.Container, w io.Writer) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if info.SnapshotKey == "" {
+		return fmt.Errorf("container %s has no rootfs snapshot", container.ID())
+	}
+	mounts, err := client.SnapshotService(info.Snapshotter).Mounts(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+	// The read-only temp mount turns the upper dir of overlayfs into a lower dir,
+	// so it is safe to export a running container.
+	return mount.WithReadonlyTempMount(ctx, mounts, func(root string) error {
+		// An empty "a" directory makes WriteDiff write all the files of root
+		return archive.WriteDiff(ctx, w, "", root)
+	})
+}
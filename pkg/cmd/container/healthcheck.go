@@ -0,0 +1,189 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+)
+
+// HealthCheck runs the health check of the container once, and records the result.
+// An error is returned if the container is unhealthy after the check.
+func HealthCheck(ctx context.Context, client *containerd.Client, req string, options types.ContainerHealthCheckOptions) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return runHealthCheck(ctx, client, found.Container, options)
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+func runHealthCheck(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerHealthCheckOptions) error {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	hc, err := healthcheck.ParseLabel(l[labels.HealthCheck])
+	if err != nil {
+		return err
+	}
+	if hc.Disabled() {
+		return fmt.Errorf("container %s has no health check configured", container.ID())
+	}
+	args, err := hc.Args()
+	if err != nil {
+		return err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Status != containerd.Running {
+		return fmt.Errorf("container %s is not running", container.ID())
+	}
+
+	stateDir := l[labels.StateDir]
+	var startedAt time.Time
+	if lf, err := state.New(stateDir); err == nil {
+		if err := lf.Load(); err == nil {
+			startedAt = lf.StartedAt
+		}
+	}
+
+	res := execHealthCheck(ctx, container, task, hc, args)
+
+	var prev, cur string
+	err = healthcheck.UpdateHealth(stateDir, func(h *healthcheck.Health) error {
+		prev = h.Status
+		h.Apply(hc, res, startedAt)
+		cur = h.Status
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if prev != cur {
+		ev := &eventstypes.ContainerUpdate{
+			ID:     container.ID(),
+			Labels: map[string]string{"health_status": cur},
+		}
+		if err := client.EventService().Publish(ctx, healthcheck.EventTopic, ev); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to publish the health status event")
+		}
+	}
+
+	if options.Stdout != nil && res.Output != "" {
+		fmt.Fprint(options.Stdout, res.Output)
+	}
+	if cur == healthcheck.Unhealthy {
+		return fmt.Errorf("container %s is unhealthy", container.ID())
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("health check failed with exit code %d", res.ExitCode)
+	}
+	return nil
+}
+
+// execHealthCheck executes the test in the container, and returns its result.
+// Failures to execute the test are reported as a failed check, like Docker does.
+func execHealthCheck(ctx context.Context, container containerd.Container, task containerd.Task, hc *healthcheck.Healthcheck, args []string) *healthcheck.Result {
+	res := &healthcheck.Result{Start: time.Now(), ExitCode: -1}
+	defer func() {
+		res.End = time.Now()
+	}()
+
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		res.Output = err.Error()
+		return res
+	}
+	pspec := spec.Process
+	pspec.Terminal = false
+	pspec.Args = args
+
+	var buf bytes.Buffer
+	process, err := task.Exec(ctx, "healthcheck-"+idgen.GenerateID(), pspec, cio.NewCreator(cio.WithStreams(nil, &buf, &buf)))
+	if err != nil {
+		res.Output = err.Error()
+		return res
+	}
+	defer process.Delete(ctx, containerd.WithProcessKill)
+
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		res.Output = err.Error()
+		return res
+	}
+	if err := process.Start(ctx); err != nil {
+		res.Output = err.Error()
+		return res
+	}
+
+	timer := time.NewTimer(hc.GetTimeout())
+	defer timer.Stop()
+	select {
+	case status := <-statusC:
+		code, _, err := status.Result()
+		if err != nil {
+			res.Output = err.Error()
+			return res
+		}
+		// Wait for the IO to be fully copied before reading the output
+		if pio := process.IO(); pio != nil {
+			pio.Wait()
+		}
+		res.ExitCode = int(code)
+		res.Output = buf.String()
+	case <-timer.C:
+		if err := process.Kill(ctx, syscall.SIGKILL); err != nil && !errors.Is(err, context.Canceled) {
+			log.G(ctx).WithError(err).Warn("failed to kill the health check process")
+		}
+		<-statusC
+		res.Output = fmt.Sprintf("Health check exceeded timeout (%s)", hc.GetTimeout())
+	}
+	return res
+}
@@ -21,11 +21,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/moby/sys/signal"
 
+	eventstypes "github.com/containerd/containerd/api/events"
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/errdefs"
@@ -34,6 +36,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
@@ -69,6 +72,7 @@ func Kill(ctx context.Context, client *containerd.Client, reqs []string, options
 				}
 				return err
 			}
+			publishKillEvent(ctx, client, found.Container.ID(), parsedSignal)
 			_, err := fmt.Fprintln(options.Stdout, found.Container.ID())
 			return err
 		},
@@ -77,6 +81,17 @@ func Kill(ctx context.Context, client *containerd.Client, reqs []string, options
 	return walker.WalkAll(ctx, reqs, true)
 }
 
+// publishKillEvent publishes the event rendered as a "kill" event by `nerdctl events`.
+func publishKillEvent(ctx context.Context, client *containerd.Client, id string, sig syscall.Signal) {
+	ev := &eventstypes.ContainerUpdate{
+		ID:     id,
+		Labels: map[string]string{eventutil.SignalEventLabel: strconv.Itoa(int(sig))},
+	}
+	if err := client.EventService().Publish(ctx, eventutil.KillEventTopic, ev); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to publish the kill event")
+	}
+}
+
 func killContainer(ctx context.Context, container containerd.Container, signal syscall.Signal) (err error) {
 	defer func() {
 		if err != nil {
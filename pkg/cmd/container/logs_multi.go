@@ -0,0 +1,477 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+)
+
+const taskStartTopic = "/tasks/start"
+
+// mergeWindow is the maximum time a log line is held back, waiting for the lines of the other containers
+// that may have an earlier timestamp.
+const mergeWindow = 200 * time.Millisecond
+
+// MultiLogs prints the logs of the containers passed as arguments and/or matching options.Filters,
+// merged in timestamp order and prefixed with the container names.
+// In follow mode, the matching containers that start later are followed too, until a signal is received.
+func MultiLogs(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerLogsOptions) error {
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		walker := &containerwalker.ContainerWalker{
+			Client: client,
+			OnFound: func(ctx context.Context, found containerwalker.Found) error {
+				if found.MatchCount > 1 {
+					return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+				}
+				return nil
+			},
+		}
+		n, err := walker.Walk(ctx, req)
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("no such container %s", req)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if options.Format == logging.FormatJSON {
+		// the records carry the container name
+		options.NoLogPrefix = true
+	}
+	ml := &multiLogs{
+		client:    client,
+		dataStore: dataStore,
+		reqs:      reqs,
+		options:   options,
+		merger:    &logMerger{notify: make(chan struct{}, 1)},
+		followed:  make(map[string]*followedContainer),
+	}
+
+	// Subscribe before listing the containers, so that no start is missed
+	var (
+		eventsCh <-chan *events.Envelope
+		errCh    <-chan error
+	)
+	if options.Follow {
+		eventsCh, errCh = client.EventService().Subscribe(ctx, fmt.Sprintf("topic==%q,namespace==%q", taskStartTopic, options.GOptions.Namespace))
+	}
+
+	containers, names, err := ml.matchingContainers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if l := len(names[c.ID()]) + 1; l > ml.width {
+			ml.width = l
+		}
+	}
+	for _, c := range containers {
+		ml.follow(ctx, c, names[c.ID()], time.Time{})
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if options.Follow {
+		go ml.watch(ctx, eventsCh, errCh)
+	}
+	ml.merger.run(ctx, options.Stdout, options.Stderr, !options.Follow)
+	ml.stop()
+	if options.Follow {
+		return nil
+	}
+	ml.wg.Wait()
+	return errors.Join(ml.errs...)
+}
+
+type followedContainer struct {
+	stopChannel chan os.Signal
+	// startedAt is the time at which the logs started to be followed
+	startedAt time.Time
+	// restartedAt is set when the container is started again while its previous logs are still being read
+	restartedAt time.Time
+}
+
+type multiLogs struct {
+	client    *containerd.Client
+	dataStore string
+	reqs      []string
+	options   types.ContainerLogsOptions
+	merger    *logMerger
+
+	mu       sync.Mutex
+	followed map[string]*followedContainer // key: container ID
+	width    int
+	errs     []error
+	wg       sync.WaitGroup
+}
+
+// matchingContainers returns the containers matching the requests and the filters, and their names.
+func (ml *multiLogs) matchingContainers(ctx context.Context) ([]containerd.Container, map[string]string, error) {
+	containers, err := ml.client.Containers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	filterCtx, err := foldContainerFilters(ctx, containers, ml.options.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	var matching []containerd.Container
+	names := make(map[string]string)
+	for _, c := range filterCtx.MatchesFilters(ctx) {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			// the container was removed in the meantime
+			continue
+		}
+		name := containerutil.GetContainerName(l)
+		if !ml.requested(c.ID(), name) {
+			continue
+		}
+		if name == "" {
+			name = idgen.TruncateID(c.ID())
+		}
+		matching = append(matching, c)
+		names[c.ID()] = name
+	}
+	return matching, names, nil
+}
+
+func (ml *multiLogs) requested(id, name string) bool {
+	if len(ml.reqs) == 0 {
+		return true
+	}
+	for _, req := range ml.reqs {
+		if req == name || strings.HasPrefix(id, req) {
+			return true
+		}
+	}
+	return false
+}
+
+// follow starts reading the logs of a container, since the given time if not zero.
+func (ml *multiLogs) follow(ctx context.Context, c containerd.Container, name string, since time.Time) {
+	ml.mu.Lock()
+	if fc, ok := ml.followed[c.ID()]; ok {
+		if since.After(fc.startedAt) {
+			// read the logs of the new run once the previous ones have been read
+			fc.restartedAt = since
+		}
+		ml.mu.Unlock()
+		return
+	}
+	fc := &followedContainer{
+		stopChannel: make(chan os.Signal, 1),
+		startedAt:   time.Now(),
+	}
+	ml.followed[c.ID()] = fc
+	width := -1
+	if !ml.options.NoLogPrefix {
+		if l := len(name) + 1; l > ml.width {
+			ml.width = l
+		}
+		width = ml.width
+	}
+	ml.mu.Unlock()
+
+	tagger := pipetagger.New(nil, nil, name, width, ml.options.NoColor)
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	stdoutStream := ml.merger.add(tagger, false)
+	stderrStream := ml.merger.add(tagger, true)
+	go stdoutStream.consume(stdoutR, ml.options.Timestamps)
+	go stderrStream.consume(stderrR, ml.options.Timestamps)
+
+	// The timestamps are needed to merge the logs, they are stripped later unless requested
+	options := ml.options
+	options.Timestamps = true
+	if !since.IsZero() {
+		options.Since = since.Format(time.RFC3339Nano)
+		options.Tail = 0
+	}
+	ml.wg.Add(1)
+	go func() {
+		defer ml.wg.Done()
+		err := printContainerLogs(ctx, c, ml.dataStore, options, stdoutW, stderrW, fc.stopChannel)
+		stdoutW.Close()
+		stderrW.Close()
+
+		ml.mu.Lock()
+		if err != nil {
+			if ml.options.Follow {
+				log.G(ctx).WithError(err).Warnf("failed to read the logs of container %s", name)
+			} else {
+				ml.errs = append(ml.errs, fmt.Errorf("failed to read the logs of container %s: %w", name, err))
+			}
+		}
+		delete(ml.followed, c.ID())
+		restartedAt := fc.restartedAt
+		ml.mu.Unlock()
+		if !restartedAt.IsZero() && ctx.Err() == nil {
+			ml.follow(ctx, c, name, restartedAt)
+		}
+	}()
+}
+
+// watch follows the matching containers as they start.
+func (ml *multiLogs) watch(ctx context.Context, eventsCh <-chan *events.Envelope, errCh <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-errCh:
+			if err != nil && ctx.Err() == nil {
+				log.G(ctx).WithError(err).Warn("failed to watch the container starts, new containers will not be followed")
+			}
+			return
+		case e := <-eventsCh:
+			v, err := typeurl.UnmarshalAny(e.Event)
+			if err != nil {
+				log.G(ctx).WithError(err).Warn("cannot unmarshal an event from Any")
+				continue
+			}
+			ev, ok := v.(*eventstypes.TaskStart)
+			if !ok {
+				continue
+			}
+			containers, names, err := ml.matchingContainers(ctx)
+			if err != nil {
+				log.G(ctx).WithError(err).Warn("failed to list the containers")
+				continue
+			}
+			for _, c := range containers {
+				if c.ID() == ev.ContainerID {
+					ml.follow(ctx, c, names[c.ID()], e.Timestamp)
+				}
+			}
+		}
+	}
+}
+
+// stop stops reading the logs of all the containers.
+func (ml *multiLogs) stop() {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	for _, fc := range ml.followed {
+		select {
+		case fc.stopChannel <- os.Interrupt:
+		default:
+		}
+	}
+}
+
+type logLine struct {
+	time    time.Time
+	arrival time.Time
+	text    string
+}
+
+// logStream is a stream of log lines in timestamp order, typically the stdout or the stderr of a container.
+type logStream struct {
+	merger *logMerger
+	tagger *pipetagger.PipeTagger
+	stderr bool
+	queue  []logLine
+	done   bool
+}
+
+// consume reads the lines of r, prefixed with their timestamp, until r is closed.
+func (s *logStream) consume(r io.Reader, timestamps bool) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			l := logLine{arrival: time.Now(), text: strings.TrimSuffix(line, "\n")}
+			if ts, rest, ok := strings.Cut(l.text, " "); ok {
+				if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+					l.time = t
+					if !timestamps {
+						l.text = rest
+					}
+				}
+			}
+			if l.time.IsZero() && strings.HasPrefix(l.text, "{") {
+				// a record of the "json" format
+				var record logging.LogRecord
+				if err := json.Unmarshal([]byte(l.text), &record); err == nil {
+					l.time, _ = time.Parse(time.RFC3339Nano, record.Time)
+				}
+			}
+			if l.time.IsZero() {
+				// e.g. journald does not support timestamps
+				l.time = l.arrival
+			}
+			s.merger.push(s, l)
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.merger.finish(s)
+}
+
+type mergedLine struct {
+	stream *logStream
+	line   logLine
+}
+
+// logMerger merges log streams in timestamp order.
+// Lines are written as soon as all the streams have a pending line, or after mergeWindow.
+type logMerger struct {
+	mu      sync.Mutex
+	streams []*logStream
+	notify  chan struct{}
+}
+
+func (m *logMerger) add(tagger *pipetagger.PipeTagger, stderr bool) *logStream {
+	s := &logStream{merger: m, tagger: tagger, stderr: stderr}
+	m.mu.Lock()
+	m.streams = append(m.streams, s)
+	m.mu.Unlock()
+	return s
+}
+
+func (m *logMerger) push(s *logStream, l logLine) {
+	m.mu.Lock()
+	s.queue = append(s.queue, l)
+	m.mu.Unlock()
+	m.wake()
+}
+
+func (m *logMerger) finish(s *logStream) {
+	m.mu.Lock()
+	s.done = true
+	m.mu.Unlock()
+	m.wake()
+}
+
+func (m *logMerger) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the lines that can be written at the given time, in timestamp order,
+// and the time to wait before the next line can be written.
+// The streams that are done and empty are removed.
+func (m *logMerger) pop(now time.Time) ([]mergedLine, time.Duration) {
+	var res []mergedLine
+	for {
+		var next *logStream
+		allPending := true
+		streams := m.streams[:0]
+		for _, s := range m.streams {
+			if len(s.queue) == 0 {
+				if s.done {
+					continue
+				}
+				allPending = false
+			} else if next == nil || s.queue[0].time.Before(next.queue[0].time) {
+				next = s
+			}
+			streams = append(streams, s)
+		}
+		m.streams = streams
+		if next == nil {
+			return res, time.Minute
+		}
+		head := next.queue[0]
+		if held := now.Sub(head.arrival); !allPending && held < mergeWindow {
+			return res, mergeWindow - held
+		}
+		res = append(res, mergedLine{stream: next, line: head})
+		next.queue = next.queue[1:]
+	}
+}
+
+// run writes the merged lines until ctx is done, or until all the streams are done if untilDone is set.
+func (m *logMerger) run(ctx context.Context, stdout, stderr io.Writer, untilDone bool) {
+	write := func(lines []mergedLine) {
+		for _, l := range lines {
+			w := stdout
+			if l.stream.stderr {
+				w = stderr
+			}
+			fmt.Fprintln(w, l.stream.tagger.TagLine(l.line.text))
+		}
+	}
+	for {
+		m.mu.Lock()
+		lines, wait := m.pop(time.Now())
+		finished := len(m.streams) == 0
+		m.mu.Unlock()
+		write(lines)
+		if untilDone && finished {
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// flush the pending lines
+			m.mu.Lock()
+			lines, _ := m.pop(time.Now().Add(mergeWindow))
+			m.mu.Unlock()
+			write(lines)
+			return
+		case <-m.notify:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
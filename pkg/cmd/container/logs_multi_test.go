@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
+)
+
+func TestLogMerger(t *testing.T) {
+	m := &logMerger{notify: make(chan struct{}, 1)}
+	foo := pipetagger.New(nil, nil, "foo", 4, true)
+	bar := pipetagger.New(nil, nil, "bar", 4, true)
+	streams := []struct {
+		stream *logStream
+		input  string
+	}{
+		{
+			stream: m.add(foo, false),
+			input:  "2024-07-12T03:09:24.1Z foo1\n2024-07-12T03:09:24.3Z foo2\n",
+		},
+		{
+			stream: m.add(bar, false),
+			input:  "2024-07-12T03:09:24.2Z bar1\n2024-07-12T03:09:24.4Z bar2\n",
+		},
+		{
+			stream: m.add(bar, true),
+			input:  "2024-07-12T03:09:24.25Z bar-err\n",
+		},
+	}
+	for _, s := range streams {
+		go s.stream.consume(strings.NewReader(s.input), false)
+	}
+
+	var stdout, stderr bytes.Buffer
+	m.run(context.Background(), &stdout, &stderr, true)
+	assert.Equal(t, stdout.String(), "foo |foo1\nbar |bar1\nfoo |foo2\nbar |bar2\n")
+	assert.Equal(t, stderr.String(), "bar |bar-err\n")
+}
+
+func TestLogMergerTimestamps(t *testing.T) {
+	m := &logMerger{notify: make(chan struct{}, 1)}
+	s := m.add(pipetagger.New(nil, nil, "foo", -1, true), false)
+	go s.consume(strings.NewReader("2024-07-12T03:09:24.1Z foo1\nno timestamp\n"), true)
+
+	var stdout bytes.Buffer
+	m.run(context.Background(), &stdout, &stdout, true)
+	assert.Equal(t, stdout.String(), "2024-07-12T03:09:24.1Z foo1\nno timestamp\n")
+}
@@ -32,11 +32,13 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
 	"github.com/containerd/nerdctl/v2/pkg/store"
@@ -256,6 +258,17 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 				}
 			}
 		}
+
+		// Unmount the volumes created with driver options, if this container was the last one using them - soft failure
+		var namedVolumes []string
+		for _, v := range containerutil.GetContainerVolumes(containerLabels) {
+			if v.Type == mountutil.Volume && v.Name != "" {
+				namedVolumes = append(namedVolumes, v.Name)
+			}
+		}
+		if err = volume.UnmountUnused(ctx, client, volStore, namedVolumes); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to unmount volumes %v", namedVolumes)
+		}
 	}()
 
 	// Get the task.
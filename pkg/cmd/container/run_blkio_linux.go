@@ -259,6 +259,20 @@ func BlkioOCIOpts(options types.ContainerCreateOptions) ([]oci.SpecOpts, error)
 	return opts, nil
 }
 
+// ThrottleDevices parses "<device-path>:<rate>" strings into OCI throttle devices.
+// The rate is a number of bytes per second (with an optional unit) when iops is false, a number of IO per second otherwise.
+func ThrottleDevices(vals []string, iops bool) ([]specs.LinuxThrottleDevice, error) {
+	validate := validateThrottleBpsDevices
+	if iops {
+		validate = validateThrottleIOpsDevices
+	}
+	devs, err := validate(vals)
+	if err != nil {
+		return nil, err
+	}
+	return toOCIThrottleDevices(devs)
+}
+
 // validateWeightDevices validates an array of device-weight strings
 //
 // from https://github.com/docker/cli/blob/master/opts/weightdevice.go#L15
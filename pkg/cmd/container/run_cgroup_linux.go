@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/docker/go-units"
@@ -224,6 +226,19 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions, interna
 		internalLabels.deviceMapping = append(internalLabels.deviceMapping, deviceMap)
 	}
 
+	if len(options.DeviceCgroupRules) > 0 {
+		var rules []specs.LinuxDeviceCgroup
+		for _, r := range options.DeviceCgroupRules {
+			rule, err := ParseDeviceCgroupRule(r)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		opts = append(opts, withDeviceCgroupRules(rules))
+		internalLabels.deviceCgroupRules = options.DeviceCgroupRules
+	}
+
 	return opts, nil
 }
 
@@ -311,6 +326,42 @@ func validateDeviceMode(mode string) error {
 	return nil
 }
 
+// deviceCgroupRuleRegexp matches the syntax of the device cgroup rules of Docker, e.g. "c 1:3 mr" or "a *:* rwm"
+var deviceCgroupRuleRegexp = regexp.MustCompile(`^([acb]) ([0-9]+|\*):([0-9]+|\*) ([rwm]{1,3})$`)
+
+// ParseDeviceCgroupRule parses a device cgroup rule such as "c 1:3 mr" into an allow rule.
+func ParseDeviceCgroupRule(rule string) (specs.LinuxDeviceCgroup, error) {
+	m := deviceCgroupRuleRegexp.FindStringSubmatch(rule)
+	if m == nil {
+		return specs.LinuxDeviceCgroup{}, fmt.Errorf("invalid device cgroup rule %q, must be formatted as \"TYPE MAJOR:MINOR ACCESS\", e.g. \"c 1:3 rwm\"", rule)
+	}
+	number := func(s string) (*int64, error) {
+		if s == "*" {
+			return nil, nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device cgroup rule %q: %w", rule, err)
+		}
+		return &n, nil
+	}
+	major, err := number(m[2])
+	if err != nil {
+		return specs.LinuxDeviceCgroup{}, err
+	}
+	minor, err := number(m[3])
+	if err != nil {
+		return specs.LinuxDeviceCgroup{}, err
+	}
+	return specs.LinuxDeviceCgroup{
+		Allow:  true,
+		Type:   m[1],
+		Major:  major,
+		Minor:  minor,
+		Access: m[4],
+	}, nil
+}
+
 func withUnified(unified map[string]string) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) (err error) {
 		if unified == nil {
@@ -324,6 +375,20 @@ func withUnified(unified map[string]string) oci.SpecOpts {
 	}
 }
 
+// withDeviceCgroupRules appends the rules to the cgroup allowed devices list.
+func withDeviceCgroupRules(rules []specs.LinuxDeviceCgroup) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		s.Linux.Resources.Devices = append(s.Linux.Resources.Devices, rules...)
+		return nil
+	}
+}
+
 func withCustomMemoryResources(memoryOptions customMemoryOptions) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		if s.Linux != nil {
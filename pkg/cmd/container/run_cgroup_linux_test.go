@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseDeviceCgroupRule(t *testing.T) {
+	t.Parallel()
+	i64 := func(i int64) *int64 { return &i }
+	tests := []struct {
+		rule     string
+		expected specs.LinuxDeviceCgroup
+		err      string
+	}{
+		{
+			rule:     "c 1:3 mr",
+			expected: specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: i64(1), Minor: i64(3), Access: "mr"},
+		},
+		{
+			rule:     "b 7:* rwm",
+			expected: specs.LinuxDeviceCgroup{Allow: true, Type: "b", Major: i64(7), Access: "rwm"},
+		},
+		{
+			rule:     "a *:* rwm",
+			expected: specs.LinuxDeviceCgroup{Allow: true, Type: "a", Access: "rwm"},
+		},
+		{
+			rule: "c 1:3",
+			err:  "invalid device cgroup rule",
+		},
+		{
+			rule: "x 1:3 rwm",
+			err:  "invalid device cgroup rule",
+		},
+		{
+			rule: "c 1:3 rwx",
+			err:  "invalid device cgroup rule",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.rule, func(t *testing.T) {
+			t.Parallel()
+			rule, err := ParseDeviceCgroupRule(tc.rule)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, rule, tc.expected)
+		})
+	}
+}
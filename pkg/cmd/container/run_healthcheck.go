@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+)
+
+// generateHealthcheckLabel returns the value of the "nerdctl/healthcheck" label,
+// merging the health check of the image config with the --health-* flags.
+// An empty string is returned when the container has no health check.
+func generateHealthcheckLabel(ctx context.Context, ensuredImage *imgutil.EnsuredImage, options types.ContainerCreateOptions) (string, error) {
+	var hc *healthcheck.Healthcheck
+	if options.NoHealthcheck {
+		hc = &healthcheck.Healthcheck{Test: []string{healthcheck.TestNone}}
+	} else {
+		var imageHC *healthcheck.Healthcheck
+		if ensuredImage != nil {
+			var err error
+			imageHC, err = imgutil.ReadImageHealthcheck(ctx, ensuredImage.Image)
+			if err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to read the healthcheck of image %s", ensuredImage.Ref)
+			}
+		}
+		flagsHC := &healthcheck.Healthcheck{
+			Interval:      options.HealthInterval,
+			Timeout:       options.HealthTimeout,
+			StartPeriod:   options.HealthStartPeriod,
+			StartInterval: options.HealthStartInterval,
+			Retries:       options.HealthRetries,
+		}
+		if options.HealthCmd != "" {
+			flagsHC.Test = healthCmdToTest(options.HealthCmd)
+		}
+		hc = flagsHC.Merge(imageHC)
+	}
+	if err := hc.Validate(); err != nil {
+		return "", err
+	}
+	if len(hc.Test) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(hc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// healthCmdToTest converts the value of --health-cmd to a health check test.
+// Like the HEALTHCHECK instruction of Dockerfile, a JSON array of strings is the exec form,
+// and anything else is the shell form.
+func healthCmdToTest(healthCmd string) []string {
+	var args []string
+	if strings.HasPrefix(strings.TrimSpace(healthCmd), "[") {
+		if err := json.Unmarshal([]byte(healthCmd), &args); err == nil && len(args) > 0 {
+			return append([]string{healthcheck.TestCmd}, args...)
+		}
+	}
+	return []string{healthcheck.TestCmdShell, healthCmd}
+}
@@ -88,6 +88,9 @@ func setPlatformOptions(ctx context.Context, client *containerd.Client, id, uts
 	if err != nil {
 		return nil, err
 	}
+	if internalLabels.ulimits, err = MergeUlimits(nil, options.Ulimit); err != nil {
+		return nil, err
+	}
 
 	// If without any ulimitOpts, we need to reset the default value from spec
 	// which has 1024 as file limit. Make this behavior same as containerd/cri.
@@ -232,9 +235,20 @@ func setOOMScoreAdj(opts []oci.SpecOpts, oomScoreAdjChanged bool, oomScoreAdj in
 	if !oomScoreAdjChanged {
 		return opts, nil
 	}
+	oomScoreAdj, err := ValidateOOMScoreAdj(oomScoreAdj)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, withOOMScoreAdj(oomScoreAdj))
+	return opts, nil
+}
+
+// ValidateOOMScoreAdj checks the range of an OOM score adjustment, and returns the value which can actually be set.
+func ValidateOOMScoreAdj(oomScoreAdj int) (int, error) {
 	// score=0 means literally zero, not "unchanged"
 	if oomScoreAdj < -1000 || oomScoreAdj > 1000 {
-		return nil, fmt.Errorf("invalid value %d, range for oom score adj is [-1000, 1000]", oomScoreAdj)
+		return 0, fmt.Errorf("invalid value %d, range for oom score adj is [-1000, 1000]", oomScoreAdj)
 	}
 
 	if userns.RunningInUserNS() {
@@ -250,9 +264,7 @@ func setOOMScoreAdj(opts []oci.SpecOpts, oomScoreAdjChanged bool, oomScoreAdj in
 			oomScoreAdj = minimum
 		}
 	}
-
-	opts = append(opts, withOOMScoreAdj(oomScoreAdj))
-	return opts, nil
+	return oomScoreAdj, nil
 }
 
 func withOOMScoreAdj(score int) oci.SpecOpts {
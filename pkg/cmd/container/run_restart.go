@@ -24,6 +24,8 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/runtime/restart"
 
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -51,10 +53,24 @@ func checkRestartCapabilities(ctx context.Context, client *containerd.Client, re
 	return true, nil
 }
 
-func generateRestartOpts(ctx context.Context, client *containerd.Client, restartFlag, logURI string, inRun bool) ([]containerd.NewContainerOpts, error) {
+func generateRestartOpts(ctx context.Context, client *containerd.Client, restartFlag, logURI string, inRun bool, supervisor string) ([]containerd.NewContainerOpts, error) {
 	if restartFlag == "" || restartFlag == "no" {
 		return nil, nil
 	}
+	switch supervisor {
+	case "", "containerd":
+	case "nerdctl":
+		// The policy is enforced by `nerdctl system restart-supervisor`, the containerd restart monitor is not involved
+		policy, err := restartsupervisor.ParsePolicy(restartFlag)
+		if err != nil {
+			return nil, err
+		}
+		return []containerd.NewContainerOpts{
+			containerd.WithAdditionalContainerLabels(map[string]string{labels.RestartPolicy: policy.String()}),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown restart supervisor %q, must be \"containerd\" or \"nerdctl\"", supervisor)
+	}
 	if _, err := checkRestartCapabilities(ctx, client, restartFlag); err != nil {
 		return nil, err
 	}
@@ -76,6 +92,20 @@ func generateRestartOpts(ctx context.Context, client *containerd.Client, restart
 
 // UpdateContainerRestartPolicyLabel updates the restart policy label of the container.
 func UpdateContainerRestartPolicyLabel(ctx context.Context, client *containerd.Client, container containerd.Container, restartFlag string) error {
+	lables, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := lables[labels.RestartPolicy]; ok {
+		policy, err := restartsupervisor.ParsePolicy(restartFlag)
+		if err != nil {
+			return err
+		}
+		return container.Update(ctx, containerd.UpdateContainerOpts(containerd.WithAdditionalContainerLabels(map[string]string{
+			labels.RestartPolicy: policy.String(),
+		})))
+	}
+
 	if _, err := checkRestartCapabilities(ctx, client, restartFlag); err != nil {
 		return err
 	}
@@ -86,10 +116,6 @@ func UpdateContainerRestartPolicyLabel(ctx context.Context, client *containerd.C
 
 	updateOpts := []containerd.UpdateContainerOpts{restart.WithPolicy(policy)}
 
-	lables, err := container.Labels(ctx)
-	if err != nil {
-		return err
-	}
 	_, statusLabelExist := lables[restart.StatusLabel]
 	if !statusLabelExist {
 		task, err := container.Task(ctx, nil)
@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/docker/go-units"
+
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// MergeUlimits returns current with the "<type>=<soft limit>[:<hard limit>]" ulimits added, or replacing the ones of the same type.
+// The result is recorded in the host config label, so that `nerdctl inspect` reports only the ulimits set by the user.
+func MergeUlimits(current []*units.Ulimit, ulimits []string) ([]*units.Ulimit, error) {
+	res := append([]*units.Ulimit{}, current...)
+	for _, ulimit := range strutil.DedupeStrSlice(ulimits) {
+		l, err := units.ParseUlimit(ulimit)
+		if err != nil {
+			return nil, err
+		}
+		replaced := false
+		for i := range res {
+			if res[i].Name == l.Name {
+				res[i] = l
+				replaced = true
+			}
+		}
+		if !replaced {
+			res = append(res, l)
+		}
+	}
+	return res, nil
+}
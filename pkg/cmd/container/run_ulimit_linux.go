@@ -31,25 +31,33 @@ import (
 
 func generateUlimitsOpts(ulimits []string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
-	ulimits = strutil.DedupeStrSlice(ulimits)
-	if len(ulimits) > 0 {
-		var rlimits []specs.POSIXRlimit
-		for _, ulimit := range ulimits {
-			l, err := units.ParseUlimit(ulimit)
-			if err != nil {
-				return nil, err
-			}
-			rlimits = append(rlimits, specs.POSIXRlimit{
-				Type: "RLIMIT_" + strings.ToUpper(l.Name),
-				Hard: uint64(l.Hard),
-				Soft: uint64(l.Soft),
-			})
-		}
+	rlimits, err := ParseUlimits(ulimits)
+	if err != nil {
+		return nil, err
+	}
+	if len(rlimits) > 0 {
 		opts = append(opts, withRlimits(rlimits))
 	}
 	return opts, nil
 }
 
+// ParseUlimits parses "<type>=<soft limit>[:<hard limit>]" strings into OCI rlimits.
+func ParseUlimits(ulimits []string) ([]specs.POSIXRlimit, error) {
+	var rlimits []specs.POSIXRlimit
+	for _, ulimit := range strutil.DedupeStrSlice(ulimits) {
+		l, err := units.ParseUlimit(ulimit)
+		if err != nil {
+			return nil, err
+		}
+		rlimits = append(rlimits, specs.POSIXRlimit{
+			Type: "RLIMIT_" + strings.ToUpper(l.Name),
+			Hard: uint64(l.Hard),
+			Soft: uint64(l.Soft),
+		})
+	}
+	return rlimits, nil
+}
+
 func withRlimits(rlimits []specs.POSIXRlimit) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		s.Process.Rlimits = rlimits
@@ -0,0 +1,37 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/docker/go-units"
+	"gotest.tools/v3/assert"
+)
+
+func TestMergeUlimits(t *testing.T) {
+	res, err := MergeUlimits(nil, []string{"nofile=1024:2048"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}})
+
+	res, err = MergeUlimits(res, []string{"nproc=100", "nofile=2048:4096"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, []*units.Ulimit{{Name: "nofile", Soft: 2048, Hard: 4096}, {Name: "nproc", Soft: 100, Hard: 100}})
+
+	_, err = MergeUlimits(res, []string{"foo=1"})
+	assert.ErrorContains(t, err, "invalid ulimit type")
+}
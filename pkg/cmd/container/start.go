@@ -32,6 +32,9 @@ func Start(ctx context.Context, client *containerd.Client, reqs []string, option
 	if options.Attach && len(reqs) > 1 {
 		return fmt.Errorf("you cannot start and attach multiple containers at once")
 	}
+	if options.Checkpoint != "" && len(reqs) > 1 {
+		return fmt.Errorf("you cannot restore multiple containers from a checkpoint at once")
+	}
 
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
@@ -40,7 +43,17 @@ func Start(ctx context.Context, client *containerd.Client, reqs []string, option
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
-			if err := containerutil.Start(ctx, found.Container, options.Attach, options.Interactive, client, options.DetachKeys); err != nil {
+			var taskOpts []containerd.NewTaskOpts
+			if options.Checkpoint != "" {
+				if err := ensureRestorable(ctx, found.Container); err != nil {
+					return err
+				}
+				taskOpts, err = checkpointTaskOpts(ctx, client, found.Container, options.Checkpoint)
+				if err != nil {
+					return err
+				}
+			}
+			if err := containerutil.Start(ctx, found.Container, options.Attach, options.Interactive, client, options.GOptions, options.DetachKeys, taskOpts...); err != nil {
 				return err
 			}
 			if !options.Attach {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"text/tabwriter"
@@ -42,21 +43,53 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/statsutil"
 )
 
+// StatsFormatPrometheus is the `nerdctl stats --format` value for the Prometheus text exposition format.
+const StatsFormatPrometheus = "prometheus"
+
+const (
+	// DefaultStatsInterval is the default period of the collection of the stats.
+	DefaultStatsInterval = 500 * time.Millisecond
+	// MinStatsInterval is the shortest period of the collection of the stats.
+	MinStatsInterval = 100 * time.Millisecond
+)
+
 type stats struct {
-	mu sync.Mutex
-	cs []*statsutil.Stats
+	mu      sync.Mutex
+	cs      []*statsutil.Stats
+	cancels map[string]context.CancelFunc
+
+	globalOptions types.GlobalCommandOptions
+	noStream      bool
+	// interval is the period of the collection of the stats
+	interval time.Duration
+	// waitFirst is a WaitGroup to wait first stat data's reach for each container
+	waitFirst sync.WaitGroup
+	// errCh receives the errors of the event subscription
+	errCh chan error
+}
+
+func newStats(globalOptions types.GlobalCommandOptions, noStream bool, interval time.Duration) *stats {
+	return &stats{
+		cancels:       make(map[string]context.CancelFunc),
+		globalOptions: globalOptions,
+		noStream:      noStream,
+		interval:      interval,
+		errCh:         make(chan error, 1),
+	}
 }
 
 // add is from https://github.com/docker/cli/blob/3fb4fb83dfb5db0c0753a8316f21aea54dab32c5/cli/command/container/stats_helpers.go#L26-L34
-func (s *stats) add(cs *statsutil.Stats) bool {
+func (s *stats) add(cs *statsutil.Stats, cancel context.CancelFunc) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if _, exists := s.isKnownContainer(cs.ID); !exists {
 		s.cs = append(s.cs, cs)
+		s.cancels[cs.ID] = cancel
 		return true
 	}
 	return false
@@ -67,6 +100,8 @@ func (s *stats) remove(id string) {
 	s.mu.Lock()
 	if i, exists := s.isKnownContainer(id); exists {
 		s.cs = append(s.cs[:i], s.cs[i+1:]...)
+		s.cancels[id]()
+		delete(s.cancels, id)
 	}
 	s.mu.Unlock()
 }
@@ -81,16 +116,176 @@ func (s *stats) isKnownContainer(cid string) (int, bool) {
 	return -1, false
 }
 
-// Stats displays a live stream of container(s) resource usage statistics.
-func Stats(ctx context.Context, client *containerd.Client, containerIDs []string, options types.ContainerStatsOptions) error {
+// snapshot returns the stats of the known containers.
+func (s *stats) snapshot() []*statsutil.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*statsutil.Stats(nil), s.cs...)
+}
+
+// start starts collecting the stats of the container in the background, unless it is already known.
+func (s *stats) start(ctx context.Context, container containerd.Container) {
+	// if an error occurs when getting the container info, the ID alone is sufficient for the stats screen.
+	info, _ := container.Info(ctx, containerd.WithoutRefreshedMetadata)
+	cs := statsutil.NewStats(container.ID(), containerutil.GetContainerName(info.Labels))
+	cs.Metadata = statsutil.Metadata{
+		Namespace:      s.globalOptions.Namespace,
+		Image:          info.Image,
+		ComposeProject: info.Labels[labels.ComposeProject],
+		ComposeService: info.Labels[labels.ComposeService],
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	if !s.add(cs, cancel) {
+		cancel()
+		return
+	}
+	s.waitFirst.Add(1)
+	go collect(cctx, s.globalOptions, cs, &s.waitFirst, container.ID(), !s.noStream, s.interval)
+}
+
+// watch starts collecting the stats of the containers of the namespace, including the ones created later.
+// Unless all is set, only the running containers are collected, like `docker stats`.
+// Errors of the event subscription are sent to s.errCh.
+func (s *stats) watch(ctx context.Context, client *containerd.Client, all bool) {
+	topics := []string{"/containers/delete"}
+	if all {
+		topics = append(topics, "/containers/create")
+	} else {
+		topics = append(topics, "/tasks/start", "/tasks/exit")
+	}
+	var filters []string
+	for _, topic := range topics {
+		filters = append(filters, fmt.Sprintf("topic==%q,namespace==%q", topic, s.globalOptions.Namespace))
+	}
+
+	startByID := func(id string) {
+		container, err := client.LoadContainer(ctx, id)
+		if err != nil {
+			// just skip
+			return
+		}
+		s.start(ctx, container)
+	}
+
+	eh := eventutil.InitEventHandler()
+	eh.Handle("/containers/create", func(e events.Envelope) {
+		if v, ok := unmarshalEvent(e).(*eventstypes.ContainerCreate); ok {
+			startByID(v.ID)
+		}
+	})
+	eh.Handle("/containers/delete", func(e events.Envelope) {
+		if v, ok := unmarshalEvent(e).(*eventstypes.ContainerDelete); ok {
+			s.remove(v.ID)
+		}
+	})
+	eh.Handle("/tasks/start", func(e events.Envelope) {
+		if v, ok := unmarshalEvent(e).(*eventstypes.TaskStart); ok {
+			startByID(v.ContainerID)
+		}
+	})
+	eh.Handle("/tasks/exit", func(e events.Envelope) {
+		// exits of exec processes are not relevant
+		if v, ok := unmarshalEvent(e).(*eventstypes.TaskExit); ok && v.ID == v.ContainerID {
+			s.remove(v.ContainerID)
+		}
+	})
+
+	eventsCh, errCh := client.EventService().Subscribe(ctx, filters...)
+	eventChan := make(chan *events.Envelope)
+	go eh.Watch(eventChan)
+	go func() {
+		defer close(eventChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-eventsCh:
+				eventChan <- event
+			case err := <-errCh:
+				if err != nil && ctx.Err() == nil {
+					s.fail(err)
+				}
+				return
+			}
+		}
+	}()
+
+	// retrieve the initial list of containers stats.
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	for _, c := range containers {
+		if !all {
+			if cStatus := formatter.ContainerStatus(ctx, c); !strings.HasPrefix(cStatus, "Up") {
+				continue
+			}
+		}
+		s.start(ctx, c)
+	}
+}
+
+// fail reports err on s.errCh, unless an error is already pending.
+func (s *stats) fail(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+func unmarshalEvent(e events.Envelope) interface{} {
+	if e.Event == nil {
+		return nil
+	}
+	anydata, err := typeurl.UnmarshalAny(e.Event)
+	if err != nil {
+		return nil
+	}
+	return anydata
+}
+
+func checkStatsSupported() error {
 	// NOTE: rootless container does not rely on cgroupv1.
 	// more details about possible ways to resolve this concern: #223
 	if rootlessutil.IsRootless() && infoutil.CgroupsVersion() == "1" {
 		return errors.New("stats requires cgroup v2 for rootless containers, see https://rootlesscontaine.rs/getting-started/common/cgroup2/")
 	}
+	return nil
+}
+
+// StatsCollector keeps collecting the stats of the running containers of a namespace in the background.
+type StatsCollector struct {
+	stats *stats
+}
+
+// NewStatsCollector starts collecting the stats of the running containers of the namespace, until ctx is done.
+func NewStatsCollector(ctx context.Context, client *containerd.Client, globalOptions types.GlobalCommandOptions) (*StatsCollector, error) {
+	if err := checkStatsSupported(); err != nil {
+		return nil, err
+	}
+	s := newStats(globalOptions, false, DefaultStatsInterval)
+	s.watch(ctx, client, false)
+	return &StatsCollector{stats: s}, nil
+}
+
+// Stats returns the latest stats of the running containers.
+func (c *StatsCollector) Stats() []*statsutil.Stats {
+	return c.stats.snapshot()
+}
+
+// Err returns a channel receiving the error which stopped the tracking of the containers, if any.
+func (c *StatsCollector) Err() <-chan error {
+	return c.stats.errCh
+}
+
+// Stats displays a live stream of container(s) resource usage statistics.
+func Stats(ctx context.Context, client *containerd.Client, containerIDs []string, options types.ContainerStatsOptions) error {
+	if err := checkStatsSupported(); err != nil {
+		return err
+	}
 
 	showAll := len(containerIDs) == 0
-	closeChan := make(chan error)
 
 	var err error
 	w := options.Stdout
@@ -100,6 +295,7 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 		w = tabwriter.NewWriter(options.Stdout, 10, 1, 3, ' ', 0)
 	case "raw":
 		return errors.New("unsupported format: \"raw\"")
+	case StatsFormatPrometheus:
 	default:
 		tmpl, err = formatter.ParseTemplate(options.Format)
 		if err != nil {
@@ -107,138 +303,39 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 		}
 	}
 
-	// waitFirst is a WaitGroup to wait first stat data's reach for each container
-	waitFirst := &sync.WaitGroup{}
-	cStats := stats{}
-
-	monitorContainerEvents := func(started chan<- struct{}, c chan *events.Envelope, closeEventChan chan struct{}) {
-		eventsClient := client.EventService()
-		eventsCh, errCh := eventsClient.Subscribe(ctx)
-
-		// Whether we successfully subscribed to eventsCh or not, we can now
-		// unblock the main goroutine.
-		close(started)
-
-		for {
-			select {
-			case <-closeEventChan:
-				// c is closed, so we can stop monitoring events
-				return
-			case event := <-eventsCh:
-				c <- event
-			case err = <-errCh:
-				closeChan <- err
-				return
-			}
-		}
-	}
-
-	// getContainerList get all existing containers (only used when calling `nerdctl stats` without arguments).
-	getContainerList := func() {
-		containers, err := client.Containers(ctx)
+	var recorder *statsutil.Recorder
+	if options.Record != "" {
+		f, err := os.Create(options.Record)
 		if err != nil {
-			closeChan <- err
+			return err
 		}
-
-		for _, c := range containers {
-			cStatus := formatter.ContainerStatus(ctx, c)
-			if !options.All {
-				if !strings.HasPrefix(cStatus, "Up") {
-					continue
-				}
-			}
-			// if an error occurs when getting labels, the ID alone is sufficient for the stats screen.
-			clabels, _ := c.Labels(ctx)
-			s := statsutil.NewStats(c.ID(), containerutil.GetContainerName(clabels))
-			if cStats.add(s) {
-				waitFirst.Add(1)
-				go collect(ctx, options.GOptions, s, waitFirst, c.ID(), !options.NoStream)
-			}
+		defer f.Close()
+		recorder, err = statsutil.NewRecorder(f, recordFormat(options))
+		if err != nil {
+			return err
 		}
 	}
 
-	if showAll {
-		started := make(chan struct{})
-		var (
-			datacc *eventstypes.ContainerCreate
-			datacd *eventstypes.ContainerDelete
-		)
-
-		eh := eventutil.InitEventHandler()
-		eh.Handle("/containers/create", func(e events.Envelope) {
-			if e.Event != nil {
-				anydata, err := typeurl.UnmarshalAny(e.Event)
-				if err != nil {
-					// just skip
-					return
-				}
-				switch v := anydata.(type) {
-				case *eventstypes.ContainerCreate:
-					datacc = v
-				default:
-					// just skip
-					return
-				}
-			}
-			// Load the container to get its ID for retrieving the container name from Labels.
-			// if an error occurs, the ID alone is sufficient for the stats screen.
-			container, _ := client.LoadContainer(ctx, datacc.ID)
-			clabels, _ := container.Labels(ctx)
-			s := statsutil.NewStats(datacc.ID, containerutil.GetContainerName(clabels))
-			if cStats.add(s) {
-				waitFirst.Add(1)
-				go collect(ctx, options.GOptions, s, waitFirst, datacc.ID, !options.NoStream)
-			}
-		})
-
-		eh.Handle("/containers/delete", func(e events.Envelope) {
-			if e.Event != nil {
-				anydata, err := typeurl.UnmarshalAny(e.Event)
-				if err != nil {
-					// just skip
-					return
-				}
-				switch v := anydata.(type) {
-				case *eventstypes.ContainerDelete:
-					datacd = v
-				default:
-					// just skip
-					return
-				}
-			}
-			cStats.remove(datacd.ID)
-		})
-
-		eventChan := make(chan *events.Envelope)
-		closeEventChan := make(chan struct{})
-
-		go eh.Watch(eventChan)
-		go monitorContainerEvents(started, eventChan, closeEventChan)
-
-		defer func() {
-			closeEventChan <- struct{}{}
-			close(eventChan)
-		}()
-
-		<-started
-
-		// Start a goroutine to retrieve the initial list of containers stats.
-		getContainerList()
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+	if interval < MinStatsInterval {
+		return fmt.Errorf("invalid interval %s: must be at least %s", interval, MinStatsInterval)
+	}
 
-		// make sure each container get at least one valid stat data
-		waitFirst.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the stats are collected at the same period as they are displayed and recorded, so that each tick gets a fresh sample
+	cStats := newStats(options.GOptions, options.NoStream, interval)
 
+	if showAll {
+		cStats.watch(ctx, client, options.All)
 	} else {
 		walker := &containerwalker.ContainerWalker{
 			Client: client,
 			OnFound: func(ctx context.Context, found containerwalker.Found) error {
-				// if an error occurs when getting labels, the ID alone is sufficient for the stats screen.
-				clabels, _ := found.Container.Labels(ctx)
-				s := statsutil.NewStats(found.Container.ID(), containerutil.GetContainerName(clabels))
-				if cStats.add(s) {
-					waitFirst.Add(1)
-					go collect(ctx, options.GOptions, s, waitFirst, found.Container.ID(), !options.NoStream)
-				}
+				cStats.start(ctx, found.Container)
 				return nil
 			},
 		}
@@ -246,11 +343,9 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 		if err := walker.WalkAll(ctx, containerIDs, false); err != nil {
 			return err
 		}
-
-		// make sure each container get at least one valid stat data
-		waitFirst.Wait()
-
 	}
+	// make sure each container get at least one valid stat data
+	cStats.waitFirst.Wait()
 
 	cleanScreen := func() {
 		if !options.NoStream {
@@ -259,7 +354,7 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 		}
 	}
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// firstTick is for creating distant CPU readings.
@@ -267,21 +362,32 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 	firstTick := true
 	for range ticker.C {
 		cleanScreen()
+		cs := cStats.snapshot()
 		ccstats := []statsutil.StatsEntry{}
-		cStats.mu.Lock()
-		for _, c := range cStats.cs {
+		for _, c := range cs {
 			if err := c.GetError(); err != nil {
 				fmt.Fprintf(options.Stderr, "unable to get stat entry: %s\n", err)
 			}
 			ccstats = append(ccstats, c.GetStatistics())
 		}
-		cStats.mu.Unlock()
+
+		if !firstTick && recorder != nil {
+			if err := recorder.Record(time.Now(), ccstats); err != nil {
+				return err
+			}
+		}
 
 		if !firstTick {
 			// print header for every tick
 			if options.Format == "" || options.Format == "table" {
 				fmt.Fprintln(w, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
 			}
+			if options.Format == StatsFormatPrometheus {
+				if err := statsutil.WritePrometheus(options.Stdout, cs); err != nil {
+					return err
+				}
+				ccstats = nil
+			}
 		}
 
 		for _, c := range ccstats {
@@ -318,19 +424,15 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 			f.Flush()
 		}
 
-		if len(cStats.cs) == 0 && !showAll {
+		if len(cs) == 0 && !showAll {
 			break
 		}
 		if options.NoStream && !firstTick {
 			break
 		}
 		select {
-		case err, ok := <-closeChan:
-			if ok {
-				if err != nil {
-					return err
-				}
-			}
+		case err := <-cStats.errCh:
+			return err
 		default:
 			// just skip
 		}
@@ -340,7 +442,7 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 	return err
 }
 
-func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *statsutil.Stats, waitFirst *sync.WaitGroup, id string, noStream bool) {
+func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *statsutil.Stats, waitFirst *sync.WaitGroup, id string, noStream bool, interval time.Duration) {
 	log.G(ctx).Debugf("collecting stats for %s", s.ID)
 	var (
 		getFirst = true
@@ -372,50 +474,28 @@ func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *s
 		previousStats := new(statsutil.ContainerStats)
 		firstSet := true
 		for {
-			// task is in the for loop to avoid nil task just after Container creation
-			task, err := container.Task(ctx, nil)
-			if err != nil {
-				u <- err
-				continue
-			}
-
-			metric, err := task.Metrics(ctx)
-			if err != nil {
-				u <- err
-				continue
-			}
-			anydata, err := typeurl.UnmarshalAny(metric.Data)
-			if err != nil {
-				u <- err
-				continue
-			}
-
-			netNS, err := containerinspector.InspectNetNS(ctx, int(task.Pid()))
-			if err != nil {
-				u <- err
-				continue
-			}
-
-			// when (firstSet == true), we only set container stats without rendering stat entry
-			statsEntry, err := setContainerStatsAndRenderStatsEntry(previousStats, firstSet, anydata, int(task.Pid()), netNS.Interfaces)
-			if err != nil {
-				u <- err
-				continue
+			statsEntry, err := sampleStats(ctx, container, previousStats, firstSet)
+			if err == nil {
+				if firstSet {
+					firstSet = false
+				} else {
+					s.SetStatistics(statsEntry)
+				}
 			}
-
-			if firstSet {
-				firstSet = false
-			} else {
-				s.SetStatistics(statsEntry)
+			select {
+			case u <- err:
+			case <-ctx.Done():
+				return
 			}
-			u <- nil
 			// sleep to create distant CPU readings
-			time.Sleep(500 * time.Millisecond)
+			time.Sleep(interval)
 		}
 	}()
 	for {
 		select {
-		case <-time.After(6 * time.Second):
+		case <-ctx.Done():
+			return
+		case <-time.After(max(6*time.Second, 2*interval)):
 			// zero out the values if we have not received an update within
 			// the specified duration.
 			s.SetErrorAndReset(errors.New("timeout waiting for stats"))
@@ -439,3 +519,29 @@ func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *s
 		}
 	}
 }
+
+// sampleStats reads the cgroup metrics of the container task.
+// when (firstSet == true), we only set container stats without rendering stat entry
+func sampleStats(ctx context.Context, container containerd.Container, previousStats *statsutil.ContainerStats, firstSet bool) (statsutil.StatsEntry, error) {
+	// task is in the for loop to avoid nil task just after Container creation
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+	anydata, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	netNS, err := containerinspector.InspectNetNS(ctx, int(task.Pid()))
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	return setContainerStatsAndRenderStatsEntry(previousStats, firstSet, anydata, int(task.Pid()), netNS.Interfaces)
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
+)
+
+// recordFormat returns the format of the `nerdctl stats --record` file, guessed from its extension when not set.
+func recordFormat(options types.ContainerStatsOptions) string {
+	if options.RecordFormat != "" {
+		return options.RecordFormat
+	}
+	if strings.EqualFold(filepath.Ext(options.Record), ".csv") {
+		return statsutil.RecordFormatCSV
+	}
+	return statsutil.RecordFormatJSONL
+}
+
+// StatsReplay prints the summary of the samples recorded with `nerdctl stats --record`, for each container:
+// min/avg/p95/max of the CPU and memory usage, peak memory and total I/O during the recording.
+func StatsReplay(options types.ContainerStatsOptions) error {
+	f, err := os.Open(options.Replay)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	samples, err := statsutil.ReadSamples(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", options.Replay, err)
+	}
+	summaries := statsutil.Summarize(samples)
+
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w := tabwriter.NewWriter(options.Stdout, 10, 1, 3, ' ', 0)
+		fmt.Fprintln(w, "CONTAINER ID\tNAME\tSAMPLES\tDURATION\tCPU % MIN / AVG / P95 / MAX\tMEM USAGE MIN / AVG / P95 / MAX\tPEAK MEM / LIMIT\tNET I/O\tBLOCK I/O\tPIDS MAX")
+		for _, s := range summaries {
+			rs := statsutil.RenderSummary(&s, options.NoTrunc)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				rs.ID,
+				rs.Name,
+				rs.Samples,
+				rs.Duration,
+				rs.CPUPerc,
+				rs.MemUsage,
+				rs.PeakMemory,
+				rs.NetIO,
+				rs.BlockIO,
+				rs.PIDs,
+			)
+		}
+		return w.Flush()
+	case "raw", StatsFormatPrometheus:
+		return fmt.Errorf("unsupported format with --replay: %q", options.Format)
+	default:
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+	// the template is applied to the raw summaries, e.g. `{{.Memory.Max}}`
+	for _, s := range summaries {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, s); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(options.Stdout, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
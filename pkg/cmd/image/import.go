@@ -0,0 +1,270 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Import creates a single-layer image from a tarball of a root filesystem (e.g., created by `nerdctl export`).
+// The tarball may be compressed, and may be read from a file, an URL, or STDIN.
+func Import(ctx context.Context, client *containerd.Client, options types.ImageImportOptions) error {
+	changes, err := imgutil.ParseChanges(options.Change)
+	if err != nil {
+		return err
+	}
+
+	platform := platforms.DefaultSpec()
+	if options.Platform != "" {
+		platform, err = platforms.Parse(options.Platform)
+		if err != nil {
+			return err
+		}
+		platform = platforms.Normalize(platform)
+	}
+
+	name := ""
+	if options.Reference != "" {
+		parsedReference, err := referenceutil.Parse(options.Reference)
+		if err != nil {
+			return err
+		}
+		name = parsedReference.String()
+	}
+
+	src, err := openImportSource(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	cs := client.ContentStore()
+	layerDesc, diffID, err := writeImportLayer(ctx, cs, src)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", options.Source, err)
+	}
+
+	comment := options.Message
+	if comment == "" {
+		comment = "Imported from " + options.Source
+	}
+	created := time.Now()
+	config := ocispec.Image{
+		Created:  &created,
+		Platform: platform,
+		RootFS: ocispec.RootFS{
+			Type:    "layers",
+			DiffIDs: []digest.Digest{diffID},
+		},
+		History: []ocispec.History{
+			{
+				Created: &created,
+				Comment: comment,
+			},
+		},
+	}
+	if changes.CMD != nil {
+		config.Config.Cmd = changes.CMD
+	}
+	if changes.Entrypoint != nil {
+		config.Config.Entrypoint = changes.Entrypoint
+	}
+
+	snapshotter := options.GOptions.Snapshotter
+	manifestDesc, configDigest, err := writeImportManifest(ctx, cs, snapshotter, config, layerDesc)
+	if err != nil {
+		return err
+	}
+
+	// Like Docker, an image imported without a reference is dangling.
+	// Naming it after its config digest makes `nerdctl images` show it as "<none>".
+	if name == "" {
+		name = configDigest.String()
+	}
+	img := images.Image{
+		Name:      name,
+		Target:    manifestDesc,
+		CreatedAt: created,
+	}
+	if _, err := client.ImageService().Update(ctx, img); err != nil {
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+		if _, err := client.ImageService().Create(ctx, img); err != nil {
+			return fmt.Errorf("failed to create new image %s: %w", name, err)
+		}
+	}
+
+	cimg := containerd.NewImageWithPlatform(client, img, platforms.Only(platform))
+	if err := cimg.Unpack(ctx, snapshotter); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(options.Stdout, configDigest)
+	return err
+}
+
+// openImportSource opens the tarball to import, which is either "-" (STDIN), an http(s) URL, or a file path.
+func openImportSource(ctx context.Context, options types.ImageImportOptions) (io.ReadCloser, error) {
+	switch {
+	case options.Source == "-":
+		return io.NopCloser(options.Stdin), nil
+	case strings.HasPrefix(options.Source, "http://"), strings.HasPrefix(options.Source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, options.Source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to download %s: %s", options.Source, resp.Status)
+		}
+		log.G(ctx).Debugf("downloading %s", options.Source)
+		return resp.Body, nil
+	default:
+		return os.Open(options.Source)
+	}
+}
+
+// writeImportLayer writes the (possibly compressed) tarball read from r into the content store as a gzip layer.
+// It returns the descriptor of the layer and its diffID, i.e. the digest of the uncompressed tarball.
+func writeImportLayer(ctx context.Context, cs content.Store, r io.Reader) (ocispec.Descriptor, digest.Digest, error) {
+	decompressed, err := compression.DecompressStream(r)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer decompressed.Close()
+
+	ref := fmt.Sprintf("import-%d", time.Now().UnixNano())
+	w, err := content.OpenWriter(ctx, cs, content.WithRef(ref))
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer w.Close()
+
+	diffIDDigester := digest.Canonical.Digester()
+	gz := gzip.NewWriter(w)
+	if _, err := io.Copy(gz, io.TeeReader(decompressed, diffIDDigester.Hash())); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	diffID := diffIDDigester.Digest()
+	labels := map[string]string{
+		"containerd.io/uncompressed": diffID.String(),
+	}
+	if err := w.Commit(ctx, status.Offset, "", content.WithLabels(labels)); err != nil && !errdefs.IsAlreadyExists(err) {
+		return ocispec.Descriptor{}, "", err
+	}
+	return ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2LayerGzip,
+		Digest:    w.Digest(),
+		Size:      status.Offset,
+	}, diffID, nil
+}
+
+// writeImportManifest writes the image config and the manifest of the imported image into the content store.
+func writeImportManifest(ctx context.Context, cs content.Store, snapshotter string, config ocispec.Image, layerDesc ocispec.Descriptor) (ocispec.Descriptor, digest.Digest, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	configDesc := ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2Config,
+		Digest:    digest.FromBytes(configJSON),
+		Size:      int64(len(configJSON)),
+	}
+
+	manifest := struct {
+		MediaType string `json:"mediaType,omitempty"`
+		ocispec.Manifest
+	}{
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Manifest: ocispec.Manifest{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2,
+			},
+			Config: configDesc,
+			Layers: []ocispec.Descriptor{layerDesc},
+		},
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Digest:    digest.FromBytes(manifestJSON),
+		Size:      int64(len(manifestJSON)),
+	}
+
+	// The manifest references the layer and the config, the config references the unpacked snapshot
+	manifestLabels := content.WithLabels(map[string]string{
+		"containerd.io/gc.ref.content.0": configDesc.Digest.String(),
+		"containerd.io/gc.ref.content.1": layerDesc.Digest.String(),
+	})
+	if err := content.WriteBlob(ctx, cs, manifestDesc.Digest.String(), bytes.NewReader(manifestJSON), manifestDesc, manifestLabels); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	configLabels := content.WithLabels(map[string]string{
+		fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snapshotter): identity.ChainID(config.RootFS.DiffIDs).String(),
+	})
+	if err := content.WriteBlob(ctx, cs, configDesc.Digest.String(), bytes.NewReader(configJSON), configDesc, configLabels); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	return manifestDesc, configDesc.Digest, nil
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Annotate updates the platform of the entry of the manifest list listRef matching manifestRef.
+// manifestRef is either a digest, or a reference resolving to a single manifest.
+func Annotate(ctx context.Context, client *containerd.Client, listRef, manifestRef string, options types.ManifestAnnotateOptions) error {
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	img, index, err := getManifestList(ctx, client, listRef)
+	if err != nil {
+		return err
+	}
+
+	dgst, err := digest.Parse(manifestRef)
+	if err != nil {
+		descs, err := resolveManifests(ctx, client, manifestRef, options.GOptions)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", manifestRef, err)
+		}
+		if len(descs) != 1 {
+			return fmt.Errorf("%s resolves to %d manifests, specify the digest of the manifest to annotate", manifestRef, len(descs))
+		}
+		dgst = descs[0].Digest
+	}
+
+	found := false
+	for i, m := range index.Manifests {
+		if m.Digest != dgst {
+			continue
+		}
+		found = true
+		if m.Platform == nil {
+			m.Platform = &ocispec.Platform{}
+		}
+		if options.OS != "" {
+			m.Platform.OS = options.OS
+		}
+		if options.Arch != "" {
+			m.Platform.Architecture = options.Arch
+		}
+		if options.Variant != "" {
+			m.Platform.Variant = options.Variant
+		}
+		if options.OSVersion != "" {
+			m.Platform.OSVersion = options.OSVersion
+		}
+		if len(options.OSFeatures) > 0 {
+			m.Platform.OSFeatures = options.OSFeatures
+		}
+		index.Manifests[i] = m
+	}
+	if !found {
+		return fmt.Errorf("manifest %s is not found in manifest list %s", manifestRef, listRef)
+	}
+	_, err = writeManifestList(ctx, client, img.Name, index.Manifests)
+	return err
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Create creates a local manifest list named listRef, referencing the manifests of refs.
+// refs are either local images or remote references.
+func Create(ctx context.Context, client *containerd.Client, listRef string, refs []string, options types.ManifestCreateOptions) error {
+	parsedReference, err := referenceutil.Parse(listRef)
+	if err != nil {
+		return err
+	}
+	name := parsedReference.String()
+
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	var manifests []ocispec.Descriptor
+	_, index, err := getManifestList(ctx, client, listRef)
+	switch {
+	case err == nil:
+		if !options.Amend {
+			return fmt.Errorf("refusing to amend an existing manifest list with no --amend flag")
+		}
+		manifests = index.Manifests
+	case errdefs.IsNotFound(err):
+	default:
+		return err
+	}
+
+	for _, ref := range refs {
+		descs, err := resolveManifests(ctx, client, ref, options.GOptions)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		for _, desc := range descs {
+			manifests = putManifest(manifests, desc)
+		}
+	}
+	if _, err := writeManifestList(ctx, client, name, manifests); err != nil {
+		return err
+	}
+	fmt.Fprintf(options.Stdout, "Created manifest list %s\n", name)
+	return nil
+}
+
+// putManifest appends desc to manifests, replacing any entry with the same digest.
+func putManifest(manifests []ocispec.Descriptor, desc ocispec.Descriptor) []ocispec.Descriptor {
+	for i, m := range manifests {
+		if m.Digest == desc.Digest {
+			manifests[i] = desc
+			return manifests
+		}
+	}
+	return append(manifests, desc)
+}
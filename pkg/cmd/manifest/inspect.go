@@ -0,0 +1,121 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// verboseEntry is an entry of the verbose output of `nerdctl manifest inspect`.
+type verboseEntry struct {
+	Ref        string
+	Descriptor ocispec.Descriptor
+	Manifest   json.RawMessage
+}
+
+// Inspect prints the manifest list, or the manifest, of rawRef.
+// rawRef is either a local manifest list or image, or a remote reference.
+func Inspect(ctx context.Context, client *containerd.Client, rawRef string, options types.ManifestInspectOptions) error {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	cs := client.ContentStore()
+	var target ocispec.Descriptor
+	img, err := client.ImageService().Get(ctx, parsedReference.String())
+	switch {
+	case err == nil:
+		target = img.Target
+	case errdefs.IsNotFound(err):
+		target, err = fetchManifests(ctx, client, parsedReference, options.GOptions)
+		if err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	if !options.Verbose {
+		data, err := content.ReadBlob(ctx, cs, target)
+		if err != nil {
+			return err
+		}
+		return printJSON(options, data)
+	}
+
+	var descs []ocispec.Descriptor
+	if images.IsIndexType(target.MediaType) {
+		index, err := readIndex(ctx, cs, target)
+		if err != nil {
+			return err
+		}
+		descs = index.Manifests
+	} else {
+		descs = []ocispec.Descriptor{target}
+	}
+	entries := make([]verboseEntry, 0, len(descs))
+	for _, desc := range descs {
+		data, err := content.ReadBlob(ctx, cs, desc)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, verboseEntry{
+			Ref:        fmt.Sprintf("%s@%s", parsedReference.Name(), desc.Digest),
+			Descriptor: desc,
+			Manifest:   data,
+		})
+	}
+	var v any = entries
+	if !images.IsIndexType(target.MediaType) {
+		v = entries[0]
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return printJSON(options, data)
+}
+
+func printJSON(options types.ManifestInspectOptions, data []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "    "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(options.Stdout)
+	return err
+}
@@ -0,0 +1,318 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package manifest implements `nerdctl manifest`.
+// A manifest list is stored as a local image, which target is an OCI image index (or a Docker manifest list)
+// referencing the manifests of the images it is created from.
+// The manifests and the image configs fetched from remote references are stored in the content store,
+// but their layers are not fetched: they are expected to exist in the registry when the manifest list is pushed.
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/errutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// getManifestList returns the local manifest list named rawRef.
+func getManifestList(ctx context.Context, client *containerd.Client, rawRef string) (images.Image, *ocispec.Index, error) {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return images.Image{}, nil, err
+	}
+	img, err := client.ImageService().Get(ctx, parsedReference.String())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return images.Image{}, nil, fmt.Errorf("no such manifest list: %s: %w", rawRef, errdefs.ErrNotFound)
+		}
+		return images.Image{}, nil, err
+	}
+	if !images.IsIndexType(img.Target.MediaType) {
+		return images.Image{}, nil, fmt.Errorf("%s is not a manifest list", rawRef)
+	}
+	index, err := readIndex(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return images.Image{}, nil, err
+	}
+	return img, index, nil
+}
+
+func readIndex(ctx context.Context, provider content.Provider, desc ocispec.Descriptor) (*ocispec.Index, error) {
+	data, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// writeManifestList writes an index referencing manifests, and points the local image name to it.
+// The index is a Docker manifest list if all the manifests are Docker manifests, an OCI image index otherwise.
+func writeManifestList(ctx context.Context, client *containerd.Client, name string, manifests []ocispec.Descriptor) (images.Image, error) {
+	mediaType := images.MediaTypeDockerSchema2ManifestList
+	for _, m := range manifests {
+		if m.MediaType != images.MediaTypeDockerSchema2Manifest {
+			mediaType = ocispec.MediaTypeImageIndex
+			break
+		}
+	}
+	index := ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		MediaType: mediaType,
+		Manifests: manifests,
+	}
+	data, err := json.MarshalIndent(index, "", "   ")
+	if err != nil {
+		return images.Image{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	labels := make(map[string]string)
+	for i, m := range manifests {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.m.%d", i)] = m.Digest.String()
+	}
+	if err := content.WriteBlob(ctx, client.ContentStore(), desc.Digest.String(), bytes.NewReader(data), desc, content.WithLabels(labels)); err != nil {
+		return images.Image{}, err
+	}
+
+	img := images.Image{
+		Name:      name,
+		Target:    desc,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := client.ImageService().Update(ctx, img); err != nil {
+		if !errdefs.IsNotFound(err) {
+			return images.Image{}, err
+		}
+		if _, err := client.ImageService().Create(ctx, img); err != nil {
+			return images.Image{}, err
+		}
+	}
+	return img, nil
+}
+
+// resolveManifests returns the descriptors of the manifests of rawRef, with their platform.
+// rawRef is either a local image, or a remote reference.
+// The manifests and the configs of a remote reference (or of a local index lacking some of them) are fetched.
+// The caller is expected to hold a lease.
+func resolveManifests(ctx context.Context, client *containerd.Client, rawRef string, options types.GlobalCommandOptions) ([]ocispec.Descriptor, error) {
+	parsedReference, err := referenceutil.Parse(rawRef)
+	if err != nil {
+		return nil, err
+	}
+	cs := client.ContentStore()
+	img, err := client.ImageService().Get(ctx, parsedReference.String())
+	if err == nil {
+		descs, err := manifestDescriptors(ctx, cs, img.Target)
+		if err == nil {
+			return descs, nil
+		}
+		if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+		log.G(ctx).WithError(err).Debugf("some manifests of %s are missing, fetching them", rawRef)
+	} else if !errdefs.IsNotFound(err) {
+		return nil, err
+	}
+
+	target, err := fetchManifests(ctx, client, parsedReference, options)
+	if err != nil {
+		return nil, err
+	}
+	return manifestDescriptors(ctx, cs, target)
+}
+
+// manifestDescriptors returns the descriptors of the manifests of target, with their platform.
+// Manifests which are not images (e.g., attestations) are skipped.
+func manifestDescriptors(ctx context.Context, provider content.Provider, target ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	switch {
+	case images.IsIndexType(target.MediaType):
+		index, err := readIndex(ctx, provider, target)
+		if err != nil {
+			return nil, err
+		}
+		var descs []ocispec.Descriptor
+		for _, m := range index.Manifests {
+			if !images.IsManifestType(m.MediaType) || (m.Platform != nil && m.Platform.OS == "unknown") {
+				continue
+			}
+			if m.Platform == nil {
+				platform, err := manifestPlatform(ctx, provider, m)
+				if err != nil {
+					return nil, err
+				}
+				m.Platform = platform
+			} else if _, err := content.ReadBlob(ctx, provider, m); err != nil {
+				return nil, err
+			}
+			descs = append(descs, m)
+		}
+		return descs, nil
+	case images.IsManifestType(target.MediaType):
+		platform, err := manifestPlatform(ctx, provider, target)
+		if err != nil {
+			return nil, err
+		}
+		desc := ocispec.Descriptor{
+			MediaType: target.MediaType,
+			Digest:    target.Digest,
+			Size:      target.Size,
+			Platform:  platform,
+		}
+		return []ocispec.Descriptor{desc}, nil
+	default:
+		return nil, fmt.Errorf("unsupported media type %q", target.MediaType)
+	}
+}
+
+// manifestPlatform reads the platform of a manifest from its image config.
+func manifestPlatform(ctx context.Context, provider content.Provider, desc ocispec.Descriptor) (*ocispec.Platform, error) {
+	data, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	data, err = content.ReadBlob(ctx, provider, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	platform := config.Platform
+	return &platform, nil
+}
+
+// fetchManifests fetches the index, the manifests, and the configs of a remote reference.
+// The layers are not fetched.
+func fetchManifests(ctx context.Context, client *containerd.Client, parsedReference *referenceutil.ImageReference, options types.GlobalCommandOptions) (ocispec.Descriptor, error) {
+	var target ocispec.Descriptor
+	cs := client.ContentStore()
+	err := withResolver(ctx, parsedReference.Domain, options, nil, func(resolver remotes.Resolver) error {
+		name, desc, err := resolver.Resolve(ctx, parsedReference.String())
+		if err != nil {
+			return err
+		}
+		fetcher, err := resolver.Fetcher(ctx, name)
+		if err != nil {
+			return err
+		}
+		childrenHandler := images.ChildrenHandler(cs)
+		withoutLayers := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			children, err := childrenHandler(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			var res []ocispec.Descriptor
+			for _, c := range children {
+				if images.IsIndexType(c.MediaType) || images.IsManifestType(c.MediaType) || images.IsConfigType(c.MediaType) {
+					res = append(res, c)
+				}
+			}
+			return res, nil
+		})
+		handler := images.Handlers(
+			remotes.FetchHandler(cs, fetcher),
+			images.SetChildrenLabels(cs, withoutLayers),
+		)
+		if err := images.Dispatch(ctx, handler, nil, desc); err != nil {
+			return err
+		}
+		target = desc
+		return nil
+	})
+	return target, err
+}
+
+// withResolver calls fn with a resolver for the registry domain.
+// When the registry does not support HTTPS, fn is called again with a plain HTTP resolver if --insecure-registry is set.
+func withResolver(ctx context.Context, domain string, options types.GlobalCommandOptions, tracker docker.StatusTracker, fn func(remotes.Resolver) error) error {
+	resolver, err := newResolver(ctx, domain, options, tracker, false)
+	if err != nil {
+		return err
+	}
+	err = fn(resolver)
+	// In some circumstance (e.g. people just use 80 port to support pure http), the error will contain message like "dial tcp <port>: connection refused"
+	if err == nil || (!errors.Is(err, http.ErrSchemeMismatch) && !errutil.IsErrConnectionRefused(err)) {
+		return err
+	}
+	if !options.InsecureRegistry {
+		log.G(ctx).WithError(err).Errorf("server %q does not seem to support HTTPS", domain)
+		log.G(ctx).Info("Hint: you may want to try --insecure-registry to allow plain HTTP (if you are in a trusted network)")
+		return err
+	}
+	log.G(ctx).WithError(err).Warnf("server %q does not seem to support HTTPS, falling back to plain HTTP", domain)
+	resolver, err = newResolver(ctx, domain, options, tracker, true)
+	if err != nil {
+		return err
+	}
+	return fn(resolver)
+}
+
+func newResolver(ctx context.Context, domain string, options types.GlobalCommandOptions, tracker docker.StatusTracker, plainHTTP bool) (remotes.Resolver, error) {
+	var dOpts []dockerconfigresolver.Opt
+	if options.InsecureRegistry {
+		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", domain)
+		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
+	}
+	if plainHTTP {
+		dOpts = append(dOpts, dockerconfigresolver.WithPlainHTTP(true))
+	}
+	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(options.HostsDir))
+	ho, err := dockerconfigresolver.NewHostOptions(ctx, domain, dOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewResolver(docker.ResolverOptions{
+		Tracker: tracker,
+		Hosts:   dockerconfig.ConfigureHosts(ctx, *ho),
+	}), nil
+}
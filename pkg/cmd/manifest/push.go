@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/push"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+)
+
+// Push pushes the local manifest list listRef to the registry.
+// The layers of the manifests fetched from remote references are not available locally,
+// so they have to exist in the destination repository already.
+func Push(ctx context.Context, client *containerd.Client, listRef string, options types.ManifestPushOptions) error {
+	img, _, err := getManifestList(ctx, client, listRef)
+	if err != nil {
+		return err
+	}
+	parsedReference, err := referenceutil.Parse(img.Name)
+	if err != nil {
+		return err
+	}
+
+	pushTracker := docker.NewInMemoryTracker()
+	err = withResolver(ctx, parsedReference.Domain, options.GOptions, pushTracker, func(resolver remotes.Resolver) error {
+		return push.Push(ctx, client, resolver, pushTracker, options.Stdout, img.Name, img.Name, platforms.All, false, options.Quiet)
+	})
+	if err != nil {
+		return err
+	}
+
+	if options.Purge {
+		if err := client.ImageService().Delete(ctx, img.Name, images.SynchronousDelete()); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(options.Stdout, img.Target.Digest)
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Remove removes local manifest lists.
+// Images which are not manifest lists are not removed.
+func Remove(ctx context.Context, client *containerd.Client, listRefs []string, options types.ManifestRemoveOptions) error {
+	var errs []error
+	for _, listRef := range listRefs {
+		img, _, err := getManifestList(ctx, client, listRef)
+		if err == nil {
+			err = client.ImageService().Delete(ctx, img.Name, images.SynchronousDelete())
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(options.Stdout, "Deleted: %s\n", img.Name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+)
+
+// Connect connects a container to a network.
+// When the container is running, the network is attached to its network namespace right away.
+// The network is also recorded in the container labels and spec annotations, so that it is set up again on restart.
+func Connect(ctx context.Context, client *containerd.Client, options types.NetworkConnectOptions) error {
+	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath,
+		netutil.WithNamespace(options.GOptions.Namespace), netutil.WithDefaultNetwork(options.GOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+	netw, err := cniEnv.NetworkByNameOrID(options.Network)
+	if err != nil {
+		return err
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return connectContainer(ctx, client, found.Container, cniEnv, netw, dataStore, options.GOptions)
+		},
+	}
+	if n, err := walker.Walk(ctx, options.Container); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", options.Container)
+	}
+	return nil
+}
+
+func connectContainer(ctx context.Context, client *containerd.Client, container containerd.Container, cniEnv *netutil.CNIEnv, netw *netutil.NetworkConfig,
+	dataStore string, globalOptions types.GlobalCommandOptions) (err error) {
+	networks, err := containerNetworks(ctx, container)
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		if n == netw.Name || (netw.NerdctlID != nil && n == *netw.NerdctlID) {
+			return fmt.Errorf("container %s is already connected to network %s", container.ID(), netw.Name)
+		}
+	}
+	netType, err := nettype.Detect(networks)
+	if err != nil {
+		return err
+	}
+	if netType != nettype.CNI {
+		return fmt.Errorf("container %s is not using a CNI network (network mode %q), cannot connect it to network %s",
+			container.ID(), networks[0], netw.Name)
+	}
+
+	if netw.Internal() {
+		l, err := container.Labels(ctx)
+		if err != nil {
+			return err
+		}
+		ports, err := portutil.ParsePortsLabel(l)
+		if err != nil {
+			return err
+		}
+		if len(ports) > 0 {
+			return fmt.Errorf("conflicting options: ports cannot be published on internal network %q", netw.Name)
+		}
+	}
+
+	running, err := isRunning(ctx, container)
+	if err != nil {
+		return err
+	}
+	if running {
+		if err = attachNetwork(ctx, container, cniEnv, netw, dataStore, globalOptions); err != nil {
+			return fmt.Errorf("failed to connect container %s to network %s: %w", container.ID(), netw.Name, err)
+		}
+		defer func() {
+			if err != nil {
+				if detachErr := detachNetwork(ctx, container, cniEnv, netw, dataStore, globalOptions); detachErr != nil {
+					log.G(ctx).WithError(detachErr).Warnf("failed to roll back the connection of container %s to network %s", container.ID(), netw.Name)
+				}
+			}
+		}()
+	}
+
+	if err = updateContainerNetworks(ctx, container, append(networks, netw.Name)); err != nil {
+		return err
+	}
+	if running {
+		publishNetworkEvent(ctx, client, eventutil.NetworkConnectEventTopic, container.ID(), netw.Name)
+	}
+	return nil
+}
+
+// publishNetworkEvent publishes the event rendered as a "connect" or "disconnect" network event by `nerdctl events`.
+func publishNetworkEvent(ctx context.Context, client *containerd.Client, topic, id, network string) {
+	ev := &eventstypes.ContainerUpdate{
+		ID:     id,
+		Labels: map[string]string{eventutil.NetworkEventLabel: network},
+	}
+	if err := client.EventService().Publish(ctx, topic, ev); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to publish the network event")
+	}
+}
+
+// containerNetworks returns the networks recorded in the container labels.
+func containerNetworks(ctx context.Context, container containerd.Container) ([]string, error) {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var networks []string
+	if networksJSON, ok := l[labels.Networks]; ok {
+		if err := json.Unmarshal([]byte(networksJSON), &networks); err != nil {
+			return nil, err
+		}
+	}
+	return networks, nil
+}
+
+// updateContainerNetworks records the networks in both the container labels and the spec annotations.
+// The spec annotations are consumed by the OCI hook when the container is (re)started.
+func updateContainerNetworks(ctx context.Context, container containerd.Container, networks []string) error {
+	networksJSON, err := json.Marshal(networks)
+	if err != nil {
+		return err
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	if spec.Annotations == nil {
+		spec.Annotations = make(map[string]string)
+	}
+	spec.Annotations[labels.Networks] = string(networksJSON)
+	return container.Update(ctx,
+		containerd.UpdateContainerOpts(containerd.WithAdditionalContainerLabels(map[string]string{
+			labels.Networks: string(networksJSON),
+		})),
+		containerd.UpdateContainerOpts(containerd.WithSpec(spec)),
+	)
+}
+
+func isRunning(ctx context.Context, container containerd.Container) (bool, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+	switch status.Status {
+	case containerd.Running, containerd.Paused, containerd.Pausing:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
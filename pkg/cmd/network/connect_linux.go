@@ -0,0 +1,225 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/containernetworking/cni/libcni"
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/plugins/pkg/ns"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/lockutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// attachNetwork calls CNI ADD for netw against the network namespace of the running container,
+// and records the result in the hostsstore so that /etc/hosts of all the containers is refreshed.
+func attachNetwork(ctx context.Context, container containerd.Container, cniEnv *netutil.CNIEnv, netw *netutil.NetworkConfig,
+	dataStore string, globalOptions types.GlobalCommandOptions) error {
+	nsPath, err := netNSPath(ctx, container)
+	if err != nil {
+		return err
+	}
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	hs, err := hostsstore.New(dataStore, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	meta, err := hs.GetMeta(container.ID())
+	if err != nil {
+		return err
+	}
+	if meta.Networks == nil {
+		meta.Networks = make(map[string]*types100.Result)
+	}
+	if meta.Interfaces == nil {
+		meta.Interfaces = make(map[string]string)
+	}
+	ifName, err := nextInterfaceName(nsPath)
+	if err != nil {
+		return err
+	}
+
+	rt := &libcni.RuntimeConf{
+		ContainerID: globalOptions.Namespace + "-" + container.ID(),
+		NetNS:       nsPath,
+		IfName:      ifName,
+		Args: [][2]string{
+			{"IgnoreUnknown", "1"},
+			{"NERDCTL_CNI_DHCP_HOSTNAME", l[labels.Hostname]},
+		},
+	}
+	cniConfig := libcni.NewCNIConfig([]string{cniEnv.Path}, nil)
+	var result *types100.Result
+	err = withCNILock(cniEnv, func() error {
+		res, err := cniConfig.AddNetworkList(ctx, netw.NetworkConfigList, rt)
+		if err != nil {
+			return err
+		}
+		result, err = types100.NewResultFromResult(res)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The interface is recorded so that the OCI hook removes exactly this attachment when the container stops
+	meta.Networks[netw.Name] = result
+	meta.Interfaces[netw.Name] = ifName
+	if err := hs.UpdateNetworks(container.ID(), meta.Networks, meta.Interfaces); err != nil {
+		if delErr := withCNILock(cniEnv, func() error {
+			return cniConfig.DelNetworkList(ctx, netw.NetworkConfigList, rt)
+		}); delErr != nil {
+			log.G(ctx).WithError(delErr).Warnf("failed to call CNI DEL for network %s", netw.Name)
+		}
+		return err
+	}
+	// Like the OCI hook, (re-)install the isolation rules after the CNI plugins inserted theirs on the top of the FORWARD chain
+	if err := netw.EnsureIsolation(); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to isolate network %q", netw.Name)
+	}
+	return nil
+}
+
+// detachNetwork calls CNI DEL for netw against the network namespace of the running container,
+// and removes the network from the hostsstore.
+func detachNetwork(ctx context.Context, container containerd.Container, cniEnv *netutil.CNIEnv, netw *netutil.NetworkConfig,
+	dataStore string, globalOptions types.GlobalCommandOptions) error {
+	nsPath, err := netNSPath(ctx, container)
+	if err != nil {
+		return err
+	}
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	hs, err := hostsstore.New(dataStore, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	meta, err := hs.GetMeta(container.ID())
+	if err != nil {
+		return err
+	}
+
+	var (
+		key    string
+		ifName string
+	)
+	for name, res := range meta.Networks {
+		if name != netw.Name && (netw.NerdctlID == nil || name != *netw.NerdctlID) {
+			continue
+		}
+		key = name
+		if recorded, ok := meta.Interfaces[name]; ok {
+			ifName = recorded
+			break
+		}
+		for _, intf := range res.Interfaces {
+			if intf.Sandbox != "" {
+				ifName = intf.Name
+				break
+			}
+		}
+	}
+	if ifName == "" {
+		return fmt.Errorf("no interface found for network %s", netw.Name)
+	}
+
+	rt := &libcni.RuntimeConf{
+		ContainerID: globalOptions.Namespace + "-" + container.ID(),
+		NetNS:       nsPath,
+		IfName:      ifName,
+		// the same arguments as the CNI ADD of attachNetwork
+		Args: [][2]string{
+			{"IgnoreUnknown", "1"},
+			{"NERDCTL_CNI_DHCP_HOSTNAME", l[labels.Hostname]},
+		},
+	}
+	cniConfig := libcni.NewCNIConfig([]string{cniEnv.Path}, nil)
+	err = withCNILock(cniEnv, func() error {
+		return cniConfig.DelNetworkList(ctx, netw.NetworkConfigList, rt)
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(meta.Networks, key)
+	delete(meta.Interfaces, key)
+	return hs.UpdateNetworks(container.ID(), meta.Networks, meta.Interfaces)
+}
+
+// withCNILock runs fn while holding the same lock as the OCI hook, in the detached netns if any.
+func withCNILock(cniEnv *netutil.CNIEnv, fn func() error) error {
+	return lockutil.WithDirLock(filepath.Join(cniEnv.NetconfPath, ".nerdctl.lock"), func() error {
+		return rootlessutil.WithDetachedNetNSIfAny(fn)
+	})
+}
+
+// netNSPath returns the network namespace path of a running or paused container.
+func netNSPath(ctx context.Context, container containerd.Container) (string, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/proc/%d/ns/net", task.Pid()), nil
+}
+
+// nextInterfaceName returns the lowest "ethN" interface name that does not exist in the network namespace.
+// The interfaces of the namespace are used rather than the recorded networks, as an interface name may still
+// be in use after a disconnect, e.g., when go-cni associates it with another network index.
+func nextInterfaceName(nsPath string) (string, error) {
+	used := make(map[string]struct{})
+	err := ns.WithNetNSPath(nsPath, func(_ ns.NetNS) error {
+		intf, err := net.Interfaces()
+		if err != nil {
+			return err
+		}
+		for _, f := range intf {
+			used[f.Name] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list the interfaces of %s: %w", nsPath, err)
+	}
+	return lowestUnusedInterfaceName(used), nil
+}
+
+// lowestUnusedInterfaceName returns the lowest "ethN" interface name that is not in used.
+func lowestUnusedInterfaceName(used map[string]struct{}) string {
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("eth%d", i)
+		if _, ok := used[name]; !ok {
+			return name
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+)
+
+// Disconnect disconnects a container from a network.
+// When the container is running, the network is detached from its network namespace right away.
+func Disconnect(ctx context.Context, client *containerd.Client, options types.NetworkDisconnectOptions) error {
+	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath,
+		netutil.WithNamespace(options.GOptions.Namespace), netutil.WithDefaultNetwork(options.GOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+	netw, err := cniEnv.NetworkByNameOrID(options.Network)
+	if err != nil {
+		return err
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return disconnectContainer(ctx, client, found.Container, cniEnv, netw, dataStore, options)
+		},
+	}
+	if n, err := walker.Walk(ctx, options.Container); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", options.Container)
+	}
+	return nil
+}
+
+func disconnectContainer(ctx context.Context, client *containerd.Client, container containerd.Container, cniEnv *netutil.CNIEnv, netw *netutil.NetworkConfig,
+	dataStore string, options types.NetworkDisconnectOptions) error {
+	networks, err := containerNetworks(ctx, container)
+	if err != nil {
+		return err
+	}
+	var (
+		remaining []string
+		found     bool
+	)
+	for _, n := range networks {
+		if n == netw.Name || (netw.NerdctlID != nil && n == *netw.NerdctlID) {
+			found = true
+			continue
+		}
+		remaining = append(remaining, n)
+	}
+	if !found && !options.Force {
+		return fmt.Errorf("container %s is not connected to network %s", container.ID(), netw.Name)
+	}
+	if found && len(remaining) == 0 {
+		return fmt.Errorf("container %s must remain connected to at least one network", container.ID())
+	}
+
+	running, err := isRunning(ctx, container)
+	if err != nil {
+		return err
+	}
+	if running {
+		if err := detachNetwork(ctx, container, cniEnv, netw, dataStore, options.GOptions); err != nil {
+			if !options.Force {
+				return fmt.Errorf("failed to disconnect container %s from network %s: %w", container.ID(), netw.Name, err)
+			}
+			log.G(ctx).WithError(err).Warnf("failed to disconnect container %s from network %s, ignoring (--force)", container.ID(), netw.Name)
+		} else {
+			publishNetworkEvent(ctx, client, eventutil.NetworkDisconnectEventTopic, container.ID(), netw.Name)
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return updateContainerNetworks(ctx, container, remaining)
+}
@@ -0,0 +1,564 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/containerdutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+)
+
+type dfSummaryPrintable struct {
+	Type        string
+	TotalCount  int
+	Active      int
+	Size        string
+	Reclaimable string
+}
+
+type dfImagePrintable struct {
+	Repository   string
+	Tag          string
+	ID           string
+	CreatedSince string
+	Size         string
+	SharedSize   string
+	UniqueSize   string
+	Containers   int
+}
+
+type dfContainerPrintable struct {
+	ID           string
+	Image        string
+	Command      string
+	LocalVolumes int
+	Size         string
+	CreatedSince string
+	Status       string
+	Names        string
+}
+
+type dfVolumePrintable struct {
+	Name  string
+	Links int
+	Size  string
+}
+
+type dfBuildCachePrintable struct {
+	ID           string
+	CacheType    string
+	Size         string
+	CreatedSince string
+	LastUsedAt   string
+	UsageCount   int
+	Shared       bool
+}
+
+// dfVerbosePrintable is the object passed to the --format template in verbose mode.
+type dfVerbosePrintable struct {
+	Images     []dfImagePrintable
+	Containers []dfContainerPrintable
+	Volumes    []dfVolumePrintable
+	BuildCache []dfBuildCachePrintable
+}
+
+// dfUsage is the disk usage of a type of objects.
+type dfUsage struct {
+	total       int
+	active      int
+	size        int64
+	reclaimable int64
+}
+
+func (u dfUsage) printable(typ string) dfSummaryPrintable {
+	reclaimable := units.HumanSize(float64(u.reclaimable))
+	if u.size > 0 {
+		reclaimable = fmt.Sprintf("%s (%d%%)", reclaimable, u.reclaimable*100/u.size)
+	}
+	return dfSummaryPrintable{
+		Type:        typ,
+		TotalCount:  u.total,
+		Active:      u.active,
+		Size:        units.HumanSize(float64(u.size)),
+		Reclaimable: reclaimable,
+	}
+}
+
+// Df shows the disk usage of images, containers, local volumes and build cache.
+func Df(ctx context.Context, client *containerd.Client, options types.SystemDfOptions) error {
+	containers, containerUsage, err := containersDiskUsage(ctx, client, options)
+	if err != nil {
+		return err
+	}
+	usedImages := make(map[string]int)
+	usedVolumes := make(map[string]int)
+	for _, c := range containers {
+		usedImages[c.image]++
+		for _, v := range c.volumes {
+			usedVolumes[v]++
+		}
+	}
+	imgs, imageUsage, err := imagesDiskUsage(ctx, client, usedImages, options)
+	if err != nil {
+		return err
+	}
+	vols, volumeUsage, err := volumesDiskUsage(usedVolumes, options)
+	if err != nil {
+		return err
+	}
+	var (
+		cache      []dfBuildCachePrintable
+		cacheUsage dfUsage
+	)
+	if options.BuildKitHost != "" {
+		cache, cacheUsage, err = buildCacheDiskUsage(ctx, options)
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("failed to get the disk usage of the build cache")
+		}
+	}
+
+	if options.Verbose {
+		cs := make([]dfContainerPrintable, len(containers))
+		for i, c := range containers {
+			cs[i] = c.printable
+		}
+		return printDfVerbose(options, dfVerbosePrintable{
+			Images:     imgs,
+			Containers: cs,
+			Volumes:    vols,
+			BuildCache: cache,
+		})
+	}
+	return printDfSummary(options, []dfSummaryPrintable{
+		imageUsage.printable("Images"),
+		containerUsage.printable("Containers"),
+		volumeUsage.printable("Local Volumes"),
+		cacheUsage.printable("Build Cache"),
+	})
+}
+
+type dfContainer struct {
+	createdAt time.Time
+	image     string
+	volumes   []string
+	printable dfContainerPrintable
+}
+
+// containersDiskUsage returns the containers with the size of their RW layer, as reported by the snapshotter.
+// Running containers are active, the RW layers of the other ones are reclaimable.
+func containersDiskUsage(ctx context.Context, client *containerd.Client, options types.SystemDfOptions) ([]dfContainer, dfUsage, error) {
+	var usage dfUsage
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return nil, usage, err
+	}
+	snapshottersCache := map[string]snapshots.Snapshotter{}
+	res := make([]dfContainer, 0, len(containers))
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				log.G(ctx).Debugf("container %q is gone - ignoring", c.ID())
+				continue
+			}
+			return nil, usage, err
+		}
+		var size int64
+		if info.SnapshotKey != "" {
+			snapshotter, ok := snapshottersCache[info.Snapshotter]
+			if !ok {
+				snapshotter = containerdutil.SnapshotService(client, info.Snapshotter)
+				snapshottersCache[info.Snapshotter] = snapshotter
+			}
+			u, err := snapshotter.Usage(ctx, info.SnapshotKey)
+			if err != nil && !errdefs.IsNotFound(err) {
+				return nil, usage, err
+			}
+			size = u.Size
+		}
+		var command string
+		if spec, err := c.Spec(ctx); err == nil {
+			command = formatter.InspectContainerCommand(spec, true, true)
+		}
+		status := formatter.ContainerStatus(ctx, c)
+		volumes := containerVolumes(ctx, info.Labels)
+
+		usage.total++
+		usage.size += size
+		if strings.HasPrefix(status, "Up") {
+			usage.active++
+		} else {
+			usage.reclaimable += size
+		}
+		res = append(res, dfContainer{
+			createdAt: info.CreatedAt,
+			image:     info.Image,
+			volumes:   volumes,
+			printable: dfContainerPrintable{
+				ID:           idgen.TruncateID(c.ID()),
+				Image:        info.Image,
+				Command:      command,
+				LocalVolumes: len(volumes),
+				Size:         units.HumanSize(float64(size)),
+				CreatedSince: formatter.TimeSinceInHuman(info.CreatedAt),
+				Status:       status,
+				Names:        containerutil.GetContainerName(info.Labels),
+			},
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].createdAt.After(res[j].createdAt)
+	})
+	return res, usage, nil
+}
+
+// containerVolumes returns the names of the volumes mounted by a container.
+func containerVolumes(ctx context.Context, containerLabels map[string]string) []string {
+	mountsJSON, ok := containerLabels[labels.Mounts]
+	if !ok {
+		return nil
+	}
+	var mounts []dockercompat.MountPoint
+	if err := json.Unmarshal([]byte(mountsJSON), &mounts); err != nil {
+		log.G(ctx).WithError(err).Debug("failed to parse the mounts label")
+		return nil
+	}
+	var volumes []string
+	for _, m := range mounts {
+		if m.Type == mountutil.Volume {
+			volumes = append(volumes, m.Name)
+		}
+	}
+	return volumes
+}
+
+// imagesDiskUsage returns the images with their size, split between the size shared with other images and the unique size.
+// The size of an image is the size of its blobs in the content store, plus the size of its unpacked snapshots.
+// Images used by a container are active, the resources which are only referenced by the other images are reclaimable.
+func imagesDiskUsage(ctx context.Context, client *containerd.Client, usedImages map[string]int, options types.SystemDfOptions) ([]dfImagePrintable, dfUsage, error) {
+	var usage dfUsage
+	imageList, err := client.ImageService().List(ctx)
+	if err != nil {
+		return nil, usage, err
+	}
+	sort.Slice(imageList, func(i, j int) bool {
+		return imageList[i].CreatedAt.After(imageList[j].CreatedAt)
+	})
+
+	snapshotter := containerdutil.SnapshotService(client, options.GOptions.Snapshotter)
+	// Several images may share the same target (e.g., tags), resources are counted once per target
+	resourcesPerTarget := make(map[string]map[string]int64)
+	for _, img := range imageList {
+		target := img.Target.Digest.String()
+		if _, ok := resourcesPerTarget[target]; ok {
+			continue
+		}
+		resources, err := imageResources(ctx, client, snapshotter, img)
+		if err != nil {
+			return nil, usage, err
+		}
+		resourcesPerTarget[target] = resources
+	}
+
+	refCount := make(map[string]int)
+	sizes := make(map[string]int64)
+	for _, resources := range resourcesPerTarget {
+		for key, size := range resources {
+			refCount[key]++
+			sizes[key] = size
+		}
+	}
+	activeResources := make(map[string]struct{})
+	for _, img := range imageList {
+		if usedImages[img.Name] == 0 {
+			continue
+		}
+		usage.active++
+		for key := range resourcesPerTarget[img.Target.Digest.String()] {
+			activeResources[key] = struct{}{}
+		}
+	}
+	for key, size := range sizes {
+		usage.size += size
+		if _, ok := activeResources[key]; !ok {
+			usage.reclaimable += size
+		}
+	}
+	usage.total = len(imageList)
+
+	res := make([]dfImagePrintable, 0, len(imageList))
+	for _, img := range imageList {
+		var size, shared int64
+		for key, s := range resourcesPerTarget[img.Target.Digest.String()] {
+			size += s
+			if refCount[key] > 1 {
+				shared += s
+			}
+		}
+		repository, tag := imgutil.ParseRepoTag(img.Name)
+		if repository == "" {
+			repository = "<none>"
+		}
+		if tag == "" {
+			tag = "<none>"
+		}
+		res = append(res, dfImagePrintable{
+			Repository:   repository,
+			Tag:          tag,
+			ID:           img.Target.Digest.Encoded()[:12],
+			CreatedSince: formatter.TimeSinceInHuman(img.CreatedAt),
+			Size:         units.HumanSize(float64(size)),
+			SharedSize:   units.HumanSize(float64(shared)),
+			UniqueSize:   units.HumanSize(float64(size - shared)),
+			Containers:   usedImages[img.Name],
+		})
+	}
+	return res, usage, nil
+}
+
+// imageResources returns the size of the blobs and of the snapshots of an image, keyed by "blob:<digest>" and
+// "snapshot:<chainID>". Missing blobs (e.g., other platforms, lazy pulls) and snapshots (not unpacked) are ignored.
+func imageResources(ctx context.Context, client *containerd.Client, snapshotter snapshots.Snapshotter, img images.Image) (map[string]int64, error) {
+	resources := make(map[string]int64)
+	cs := client.ContentStore()
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		info, err := cs.Info(ctx, desc.Digest)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		resources["blob:"+desc.Digest.String()] = info.Size
+		return images.Children(ctx, cs, desc)
+	})
+	if err := images.Walk(ctx, handler, img.Target); err != nil {
+		return nil, err
+	}
+
+	diffIDs, err := containerd.NewImage(client, img).RootFS(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to get the rootfs of image %q", img.Name)
+		return resources, nil
+	}
+	for _, chainID := range identity.ChainIDs(diffIDs) {
+		u, err := snapshotter.Usage(ctx, chainID.String())
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		resources["snapshot:"+chainID.String()] = u.Size
+	}
+	return resources, nil
+}
+
+// volumesDiskUsage returns the local volumes with their size.
+// Volumes used by a container are active, the other ones are reclaimable.
+func volumesDiskUsage(usedVolumes map[string]int, options types.SystemDfOptions) ([]dfVolumePrintable, dfUsage, error) {
+	var usage dfUsage
+	vols, err := volume.Volumes(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address, true, nil)
+	if err != nil {
+		return nil, usage, err
+	}
+	res := make([]dfVolumePrintable, 0, len(vols))
+	for name, v := range vols {
+		usage.total++
+		usage.size += v.Size
+		if usedVolumes[name] > 0 {
+			usage.active++
+		} else {
+			usage.reclaimable += v.Size
+		}
+		res = append(res, dfVolumePrintable{
+			Name:  name,
+			Links: usedVolumes[name],
+			Size:  units.HumanSize(float64(v.Size)),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, usage, nil
+}
+
+// buildCacheDiskUsage returns the build cache records of BuildKit.
+// Records which are not in use are reclaimable.
+func buildCacheDiskUsage(ctx context.Context, options types.SystemDfOptions) ([]dfBuildCachePrintable, dfUsage, error) {
+	var usage dfUsage
+	records, err := builder.DiskUsage(ctx, types.BuilderDiskUsageOptions{
+		Stderr:       options.Stderr,
+		GOptions:     options.GOptions,
+		BuildKitHost: options.BuildKitHost,
+	})
+	if err != nil {
+		return nil, usage, err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+	res := make([]dfBuildCachePrintable, 0, len(records))
+	for _, r := range records {
+		usage.total++
+		usage.size += r.Size
+		if r.InUse {
+			usage.active++
+		} else {
+			usage.reclaimable += r.Size
+		}
+		res = append(res, buildCachePrintable(r))
+	}
+	return res, usage, nil
+}
+
+func buildCachePrintable(r buildkitutil.UsageInfo) dfBuildCachePrintable {
+	id := r.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	lastUsed := ""
+	if r.LastUsedAt != nil {
+		lastUsed = formatter.TimeSinceInHuman(*r.LastUsedAt)
+	}
+	return dfBuildCachePrintable{
+		ID:           id,
+		CacheType:    string(r.RecordType),
+		Size:         units.HumanSize(float64(r.Size)),
+		CreatedSince: formatter.TimeSinceInHuman(r.CreatedAt),
+		LastUsedAt:   lastUsed,
+		UsageCount:   r.UsageCount,
+		Shared:       r.Shared,
+	}
+}
+
+func dfTemplate(format string) (*template.Template, error) {
+	switch format {
+	case "", "table":
+		return nil, nil
+	case "raw":
+		return nil, errors.New("unsupported format: \"raw\"")
+	default:
+		return formatter.ParseTemplate(format)
+	}
+}
+
+func printDfSummary(options types.SystemDfOptions, rows []dfSummaryPrintable) error {
+	tmpl, err := dfTemplate(options.Format)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		for _, row := range rows {
+			if err := executeDfTemplate(options.Stdout, tmpl, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", row.Type, row.TotalCount, row.Active, row.Size, row.Reclaimable)
+	}
+	return w.Flush()
+}
+
+func printDfVerbose(options types.SystemDfOptions, p dfVerbosePrintable) error {
+	tmpl, err := dfTemplate(options.Format)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		return executeDfTemplate(options.Stdout, tmpl, p)
+	}
+
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprint(w, "Images space usage:\n\n")
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, i := range p.Images {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", i.Repository, i.Tag, i.ID, i.CreatedSince, i.Size, i.SharedSize, i.UniqueSize, i.Containers)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\nContainers space usage:\n\n")
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCOMMAND\tLOCAL VOLUMES\tSIZE\tCREATED\tSTATUS\tNAMES")
+	for _, c := range p.Containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", c.ID, c.Image, c.Command, c.LocalVolumes, c.Size, c.CreatedSince, c.Status, c.Names)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\nLocal Volumes space usage:\n\n")
+	fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE")
+	for _, v := range p.Volumes {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", v.Name, v.Links, v.Size)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\nBuild cache usage:\n\n")
+	fmt.Fprintln(w, "CACHE ID\tCACHE TYPE\tSIZE\tCREATED\tLAST USED\tUSAGE\tSHARED")
+	for _, b := range p.BuildCache {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%t\n", b.ID, b.CacheType, b.Size, b.CreatedSince, b.LastUsedAt, b.UsageCount, b.Shared)
+	}
+	return w.Flush()
+}
+
+func executeDfTemplate(w io.Writer, tmpl *template.Template, p any) error {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, p); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, b.String())
+	return err
+}
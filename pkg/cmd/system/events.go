@@ -26,17 +26,21 @@ import (
 	"text/template"
 	"time"
 
+	timetypes "github.com/docker/docker/api/types/time"
+
 	_ "github.com/containerd/containerd/api/events" // Register grpc event types
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/events"
-	"github.com/containerd/log"
-	"github.com/containerd/typeurl/v2"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventjournal"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
 )
 
 // EventOut contains information about an event.
+// Next to the containerd namespace, topic and payload, it carries the Docker-compatible type, action and actor of the event.
 type EventOut struct {
 	Timestamp time.Time
 	ID        string
@@ -44,16 +48,36 @@ type EventOut struct {
 	Topic     string
 	Status    Status
 	Event     string
+
+	From   string
+	Type   string
+	Action string
+	Actor  eventjournal.Actor
+}
+
+// eventJSON is the output of `--format json`: the fields of `docker events --format json`,
+// next to the fields printed by nerdctl before the Docker-compatible ones were introduced.
+// Note that "Status" and "ID" are distinct from the "status" and "id" fields of Docker.
+type eventJSON struct {
+	Timestamp time.Time
+	ID        string
+	Namespace string
+	Topic     string
+	Status    Status
+	Event     string
+	eventjournal.Message
 }
 
 type Status string
 
 const (
-	START   Status = "start"
-	UNKNOWN Status = "unknown"
+	START         Status = "start"
+	HEALTH_STATUS Status = "health_status"
+	RESTART       Status = "restart"
+	UNKNOWN       Status = "unknown"
 )
 
-var statuses = [...]Status{START, UNKNOWN}
+var statuses = [...]Status{START, HEALTH_STATUS, RESTART, UNKNOWN}
 
 func isStatus(status string) bool {
 	status = strings.ToLower(status)
@@ -68,6 +92,12 @@ func isStatus(status string) bool {
 }
 
 func TopicToStatus(topic string) Status {
+	if topic == healthcheck.EventTopic {
+		return HEALTH_STATUS
+	}
+	if topic == restartsupervisor.EventTopic {
+		return RESTART
+	}
 	if strings.Contains(strings.ToLower(topic), string(START)) {
 		return START
 	}
@@ -75,6 +105,31 @@ func TopicToStatus(topic string) Status {
 	return UNKNOWN
 }
 
+// entryStatus returns the status of a journal entry.
+// The statuses are derived from the containerd topics, except for the network and volume events that have no topic.
+func entryStatus(e *eventjournal.Entry) Status {
+	switch e.Type {
+	case networkEventType, volumeEventType:
+		return Status(e.Action)
+	}
+	return TopicToStatus(e.Topic)
+}
+
+func newEventOut(e *eventjournal.Entry) EventOut {
+	return EventOut{
+		Timestamp: e.Timestamp(),
+		ID:        e.Actor.ID,
+		Namespace: e.Namespace,
+		Topic:     e.Topic,
+		Status:    entryStatus(e),
+		Event:     e.Event,
+		From:      e.From,
+		Type:      e.Type,
+		Action:    e.Action,
+		Actor:     e.Actor,
+	}
+}
+
 // EventFilter for filtering events
 type EventFilter func(*EventOut) bool
 
@@ -84,12 +139,21 @@ func generateEventFilter(filter, filterValue string) (func(e *EventOut) bool, er
 	switch strings.ToUpper(filter) {
 	case "EVENT", "STATUS":
 		return func(e *EventOut) bool {
+			// Like Docker, "health_status" matches "health_status: healthy"
+			action, _, _ := strings.Cut(e.Action, ":")
+			if e.Action != "" && (strings.EqualFold(e.Action, filterValue) || strings.EqualFold(action, filterValue)) {
+				return true
+			}
 			if !isStatus(string(e.Status)) {
 				return false
 			}
 
 			return strings.EqualFold(string(e.Status), filterValue)
 		}, nil
+	case "TYPE":
+		return func(e *EventOut) bool {
+			return strings.EqualFold(e.Type, filterValue)
+		}, nil
 	}
 
 	return nil, fmt.Errorf("%s is an invalid or unsupported filter", filter)
@@ -145,13 +209,18 @@ func generateEventFilters(filters []string) (map[string][]EventFilter, error) {
 }
 
 // Events is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/ctr/commands/events/events.go
+// The events are recorded in the journal while they are streamed, and the past events are replayed from the journal
+// when --since or --until is specified.
 func Events(ctx context.Context, client *containerd.Client, options types.SystemEventsOptions) error {
-	eventsClient := client.EventService()
-	eventsCh, errCh := eventsClient.Subscribe(ctx)
-	var tmpl *template.Template
+	var (
+		tmpl       *template.Template
+		jsonFormat bool
+	)
 	switch options.Format {
 	case "":
 		tmpl = nil
+	case "json":
+		jsonFormat = true
 	case "raw", "table", "wide":
 		return errors.New("unsupported format: \"raw\", \"table\", and \"wide\"")
 	default:
@@ -165,63 +234,149 @@ func Events(ctx context.Context, client *containerd.Client, options types.System
 	if err != nil {
 		return err
 	}
+	now := time.Now()
+	since, err := parseEventTime(options.Since, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"since\": %w", err)
+	}
+	until, err := parseEventTime(options.Until, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"until\": %w", err)
+	}
+	r, err := newRecorder(client, options.GOptions)
+	if err != nil {
+		return err
+	}
+
+	printEntry := func(e *eventjournal.Entry) error {
+		eOut := newEventOut(e)
+		if !applyFilters(&eOut, filterMap) {
+			return nil
+		}
+		switch {
+		case jsonFormat:
+			b, err := json.Marshal(eventJSON{
+				Timestamp: eOut.Timestamp,
+				ID:        eOut.ID,
+				Namespace: eOut.Namespace,
+				Topic:     eOut.Topic,
+				Status:    eOut.Status,
+				Event:     eOut.Event,
+				Message:   e.Message,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(options.Stdout, string(b))
+			return err
+		case tmpl != nil:
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, eOut); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(options.Stdout, b.String()+"\n")
+			return err
+		case e.Topic == "":
+			// Network and volume events, which have no containerd counterpart
+			actor, err := json.Marshal(e.Actor)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(options.Stdout, eOut.Timestamp, e.Namespace, "/"+e.Type+"/"+e.Action, string(actor))
+			return err
+		default:
+			_, err := fmt.Fprintln(options.Stdout, eOut.Timestamp, e.Namespace, e.Topic, e.Event)
+			return err
+		}
+	}
+
+	// Subscribe before replaying, so that no event is lost in between
+	live := until.IsZero() || until.After(now)
+	var (
+		eventsCh <-chan *events.Envelope
+		errCh    <-chan error
+	)
+	if live {
+		eventsCh, errCh = client.EventService().Subscribe(ctx)
+	}
+	replayed := make(map[string]struct{})
+	if !since.IsZero() || !until.IsZero() {
+		entries, err := r.journal.Read(since, until)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			replayed[e.Key()] = struct{}{}
+			if err := printEntry(e); err != nil {
+				return err
+			}
+		}
+	}
+	if !live {
+		return nil
+	}
+
+	var untilCh <-chan time.Time
+	if !until.IsZero() {
+		timer := time.NewTimer(until.Sub(now))
+		defer timer.Stop()
+		untilCh = timer.C
+	}
 	for {
 		var e *events.Envelope
 		select {
 		case e = <-eventsCh:
 		case err := <-errCh:
 			return err
+		case <-untilCh:
+			return nil
 		}
-		if e != nil {
-			var out []byte
-			var id string
-			if e.Event != nil {
-				v, err := typeurl.UnmarshalAny(e.Event)
-				if err != nil {
-					log.G(ctx).WithError(err).Warn("cannot unmarshal an event from Any")
-					continue
-				}
-				out, err = json.Marshal(v)
-				if err != nil {
-					log.G(ctx).WithError(err).Warn("cannot marshal Any into JSON")
-					continue
-				}
+		if e == nil {
+			continue
+		}
+		for _, entry := range r.record(ctx, e) {
+			if _, ok := replayed[entry.Key()]; ok {
+				continue
 			}
-			var data map[string]interface{}
-			err := json.Unmarshal(out, &data)
-			if err != nil {
-				log.G(ctx).WithError(err).Warn("cannot marshal Any into JSON")
-			} else {
-				_, ok := data["container_id"]
-				if ok {
-					id = data["container_id"].(string)
-				}
+			if err := printEntry(entry); err != nil {
+				return err
 			}
+		}
+	}
+}
 
-			eOut := EventOut{e.Timestamp, id, e.Namespace, e.Topic, TopicToStatus(e.Topic), string(out)}
-			match := applyFilters(&eOut, filterMap)
-			if match {
-				if tmpl != nil {
-					var b bytes.Buffer
-					if err := tmpl.Execute(&b, eOut); err != nil {
-						return err
-					}
-					if _, err := fmt.Fprintln(options.Stdout, b.String()+"\n"); err != nil {
-						return err
-					}
-				} else {
-					if _, err := fmt.Fprintln(
-						options.Stdout,
-						e.Timestamp,
-						e.Namespace,
-						e.Topic,
-						string(out),
-					); err != nil {
-						return err
-					}
-				}
+// RecordEvents records the events in the journal until the context is done,
+// so that `nerdctl events --since` can replay the events that happened while it was not running.
+func RecordEvents(ctx context.Context, client *containerd.Client, options types.SystemRecordEventsOptions) error {
+	r, err := newRecorder(client, options.GOptions)
+	if err != nil {
+		return err
+	}
+	eventsCh, errCh := client.EventService().Subscribe(ctx)
+	for {
+		select {
+		case e := <-eventsCh:
+			if e != nil {
+				r.record(ctx, e)
 			}
-
+		case err := <-errCh:
+			return err
 		}
 	}
 }
+
+// parseEventTime parses the value of --since and --until, which is either a timestamp or a duration relative to now.
+func parseEventTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	ts, err := timetypes.GetTimestamp(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
@@ -0,0 +1,377 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"strings"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventjournal"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
+)
+
+// Types of the events, compatible with Docker.
+const (
+	containerEventType = "container"
+	imageEventType     = "image"
+	networkEventType   = "network"
+	volumeEventType    = "volume"
+)
+
+// recordedEventTypes are the types of the events appended to the journal.
+// The snapshot and content events are too frequent to be worth recording.
+var recordedEventTypes = map[string]struct{}{
+	containerEventType: {},
+	imageEventType:     {},
+	networkEventType:   {},
+	volumeEventType:    {},
+}
+
+// recorder converts the containerd events into journal entries, with Docker-compatible types, actions and attributes,
+// and appends them to the journal.
+type recorder struct {
+	client  *containerd.Client
+	journal *eventjournal.Journal
+	// cniEnv resolves the network IDs and drivers, it is nil when the CNI config dir is not accessible
+	cniEnv *netutil.CNIEnv
+	// containers caches the containers by namespace and ID, so that they are still known once deleted
+	containers map[string]*containerInfo
+}
+
+// containerInfo is what the events need to know about a container.
+type containerInfo struct {
+	attributes map[string]string
+	image      string
+	networks   []string
+	mounts     []dockercompat.MountPoint
+}
+
+func newRecorder(client *containerd.Client, globalOptions types.GlobalCommandOptions) (*recorder, error) {
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	journal, err := eventjournal.New(dataStore)
+	if err != nil {
+		return nil, err
+	}
+	cniEnv, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath,
+		netutil.WithNamespace(globalOptions.Namespace), netutil.WithDefaultNetwork(globalOptions.BridgeIP))
+	if err != nil {
+		log.L.WithError(err).Debug("network IDs will not be resolved in the events")
+		cniEnv = nil
+	}
+	return &recorder{
+		client:     client,
+		journal:    journal,
+		cniEnv:     cniEnv,
+		containers: make(map[string]*containerInfo),
+	}, nil
+}
+
+// record converts the event and appends the result to the journal.
+// A single containerd event may result in several entries, e.g., a task start is reported as the connections
+// of the container to its networks, the mounts of its volumes, and the start of the container.
+func (r *recorder) record(ctx context.Context, e *events.Envelope) []*eventjournal.Entry {
+	entries := r.convert(ctx, e)
+	var recorded []*eventjournal.Entry
+	for _, entry := range entries {
+		if _, ok := recordedEventTypes[entry.Type]; ok {
+			recorded = append(recorded, entry)
+		}
+	}
+	if err := r.journal.Append(recorded...); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to record the event in the journal")
+	}
+	return entries
+}
+
+func (r *recorder) convert(ctx context.Context, e *events.Envelope) []*eventjournal.Entry {
+	var (
+		v   any
+		out []byte
+	)
+	if e.Event != nil {
+		var err error
+		v, err = typeurl.UnmarshalAny(e.Event)
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("cannot unmarshal an event from Any")
+			return nil
+		}
+		out, err = json.Marshal(v)
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("cannot marshal Any into JSON")
+			return nil
+		}
+	}
+	base := eventjournal.Entry{
+		Message: eventjournal.Message{
+			Scope:    eventjournal.ScopeLocal,
+			Time:     e.Timestamp.Unix(),
+			TimeNano: e.Timestamp.UnixNano(),
+		},
+		Namespace: e.Namespace,
+		Topic:     e.Topic,
+		Event:     string(out),
+	}
+	ctx = namespaces.WithNamespace(ctx, e.Namespace)
+
+	switch ev := v.(type) {
+	case *eventstypes.ContainerCreate:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ID, "create", nil)}
+	case *eventstypes.ContainerUpdate:
+		switch e.Topic {
+		case healthcheck.EventTopic:
+			return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ID, "health_status: "+ev.Labels["health_status"], nil)}
+		case restartsupervisor.EventTopic:
+			return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ID, "restart",
+				map[string]string{"restartCount": ev.Labels[restartsupervisor.RestartCountEventLabel]})}
+		case eventutil.KillEventTopic:
+			return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ID, "kill",
+				map[string]string{"signal": ev.Labels[eventutil.SignalEventLabel]})}
+		case eventutil.NetworkConnectEventTopic:
+			return []*eventjournal.Entry{r.networkEntry(base, ev.ID, ev.Labels[eventutil.NetworkEventLabel], "connect")}
+		case eventutil.NetworkDisconnectEventTopic:
+			return []*eventjournal.Entry{r.networkEntry(base, ev.ID, ev.Labels[eventutil.NetworkEventLabel], "disconnect")}
+		}
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ID, "update", nil)}
+	case *eventstypes.ContainerDelete:
+		entry := r.containerEntry(ctx, base, ev.ID, "destroy", nil)
+		delete(r.containers, e.Namespace+"/"+ev.ID)
+		return []*eventjournal.Entry{entry}
+	case *eventstypes.TaskStart:
+		// The events of the networks and the volumes are not associated to a containerd topic
+		synthetic := base
+		synthetic.Topic, synthetic.Event = "", ""
+		info := r.container(ctx, ev.ContainerID)
+		entries := r.networkEntries(synthetic, ev.ContainerID, info, "connect")
+		entries = append(entries, r.volumeEntries(synthetic, ev.ContainerID, info, "mount")...)
+		return append(entries, r.containerEntry(ctx, base, ev.ContainerID, "start", nil))
+	case *eventstypes.TaskExit:
+		exitCode := map[string]string{"exitCode": strconv.FormatUint(uint64(ev.ExitStatus), 10)}
+		if ev.ID != ev.ContainerID {
+			exitCode["execID"] = ev.ID
+			return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "exec_die", exitCode)}
+		}
+		synthetic := base
+		synthetic.Topic, synthetic.Event = "", ""
+		info := r.container(ctx, ev.ContainerID)
+		entries := []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "die", exitCode)}
+		entries = append(entries, r.networkEntries(synthetic, ev.ContainerID, info, "disconnect")...)
+		return append(entries, r.volumeEntries(synthetic, ev.ContainerID, info, "unmount")...)
+	case *eventstypes.TaskOOM:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "oom", nil)}
+	case *eventstypes.TaskPaused:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "pause", nil)}
+	case *eventstypes.TaskResumed:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "unpause", nil)}
+	case *eventstypes.TaskExecAdded:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "exec_create", map[string]string{"execID": ev.ExecID})}
+	case *eventstypes.TaskExecStarted:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "exec_start", map[string]string{"execID": ev.ExecID})}
+	case *eventstypes.TaskCheckpointed:
+		return []*eventjournal.Entry{r.containerEntry(ctx, base, ev.ContainerID, "checkpoint", nil)}
+	case *eventstypes.ImageCreate:
+		return []*eventjournal.Entry{imageEntry(base, ev.Name, "tag", ev.Labels)}
+	case *eventstypes.ImageUpdate:
+		return []*eventjournal.Entry{imageEntry(base, ev.Name, "tag", ev.Labels)}
+	case *eventstypes.ImageDelete:
+		return []*eventjournal.Entry{imageEntry(base, ev.Name, "untag", nil)}
+	}
+
+	// Other events, e.g., "/snapshot/prepare", are reported with the type and the action found in the topic
+	typ, action, _ := strings.Cut(strings.TrimPrefix(e.Topic, "/"), "/")
+	base.Type = strings.TrimSuffix(typ, "s")
+	base.Action = action
+	base.Actor.ID = eventID(out)
+	return []*eventjournal.Entry{&base}
+}
+
+// eventID returns the ID of the object of an event, from the JSON representation of its payload.
+func eventID(out []byte) string {
+	var data map[string]any
+	if err := json.Unmarshal(out, &data); err != nil {
+		return ""
+	}
+	if id, ok := data["container_id"].(string); ok {
+		return id
+	}
+	// e.g., container events, including the health status events
+	id, _ := data["id"].(string)
+	return id
+}
+
+// container returns what is known about the container, loading it if it still exists.
+func (r *recorder) container(ctx context.Context, id string) *containerInfo {
+	namespace, _ := namespaces.Namespace(ctx)
+	key := namespace + "/" + id
+	c, err := r.client.LoadContainer(ctx, id)
+	if err != nil {
+		if info, ok := r.containers[key]; ok {
+			return info
+		}
+		return &containerInfo{attributes: map[string]string{}}
+	}
+	cinfo, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		if info, ok := r.containers[key]; ok {
+			return info
+		}
+		return &containerInfo{attributes: map[string]string{}}
+	}
+
+	info := &containerInfo{
+		attributes: make(map[string]string),
+		image:      cinfo.Image,
+	}
+	// The user labels are reported as attributes, like Docker
+	for k, v := range cinfo.Labels {
+		if !strings.HasPrefix(k, labels.Prefix) {
+			info.attributes[k] = v
+		}
+	}
+	if name := cinfo.Labels[labels.Name]; name != "" {
+		info.attributes["name"] = name
+	}
+	if cinfo.Image != "" {
+		info.attributes["image"] = cinfo.Image
+	}
+	if networksJSON, ok := cinfo.Labels[labels.Networks]; ok {
+		if err := json.Unmarshal([]byte(networksJSON), &info.networks); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to parse the networks of container %s", id)
+		}
+	}
+	if mountsJSON, ok := cinfo.Labels[labels.Mounts]; ok {
+		if err := json.Unmarshal([]byte(mountsJSON), &info.mounts); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to parse the mounts of container %s", id)
+		}
+	}
+	r.containers[key] = info
+	return info
+}
+
+func (r *recorder) containerEntry(ctx context.Context, base eventjournal.Entry, id, action string, extra map[string]string) *eventjournal.Entry {
+	info := r.container(ctx, id)
+	attributes := maps.Clone(info.attributes)
+	maps.Copy(attributes, extra)
+	base.Status = action
+	base.ID = id
+	base.From = info.image
+	base.Type = containerEventType
+	base.Action = action
+	base.Actor = eventjournal.Actor{ID: id, Attributes: attributes}
+	return &base
+}
+
+// networkEntries returns the network events of the container, when it is connected to CNI networks.
+func (r *recorder) networkEntries(base eventjournal.Entry, id string, info *containerInfo, action string) []*eventjournal.Entry {
+	if len(info.networks) == 0 {
+		return nil
+	}
+	if netType, err := nettype.Detect(info.networks); err != nil || netType != nettype.CNI {
+		return nil
+	}
+	var entries []*eventjournal.Entry
+	for _, network := range info.networks {
+		entries = append(entries, r.networkEntry(base, id, network, action))
+	}
+	return entries
+}
+
+func (r *recorder) networkEntry(base eventjournal.Entry, id, network, action string) *eventjournal.Entry {
+	attributes := map[string]string{
+		"container": id,
+		"name":      network,
+	}
+	networkID := network
+	if r.cniEnv != nil {
+		if netw, err := r.cniEnv.NetworkByNameOrID(network); err == nil {
+			if netw.NerdctlID != nil {
+				networkID = *netw.NerdctlID
+			}
+			if len(netw.Plugins) > 0 {
+				attributes["type"] = netw.Plugins[0].Network.Type
+			}
+		}
+	}
+	base.Type = networkEventType
+	base.Action = action
+	base.Actor = eventjournal.Actor{ID: networkID, Attributes: attributes}
+	return &base
+}
+
+// volumeEntries returns the events of the volumes mounted in the container.
+func (r *recorder) volumeEntries(base eventjournal.Entry, id string, info *containerInfo, action string) []*eventjournal.Entry {
+	var entries []*eventjournal.Entry
+	for _, m := range info.mounts {
+		if m.Type != mountutil.Volume {
+			continue
+		}
+		driver := m.Driver
+		if driver == "" {
+			driver = "local"
+		}
+		attributes := map[string]string{
+			"container": id,
+			"driver":    driver,
+		}
+		if action == "mount" {
+			attributes["destination"] = m.Destination
+			attributes["propagation"] = m.Propagation
+			attributes["read/write"] = strconv.FormatBool(m.RW)
+		}
+		entry := base
+		entry.Type = volumeEventType
+		entry.Action = action
+		entry.Actor = eventjournal.Actor{ID: m.Name, Attributes: attributes}
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+func imageEntry(base eventjournal.Entry, name, action string, imageLabels map[string]string) *eventjournal.Entry {
+	attributes := maps.Clone(imageLabels)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	attributes["name"] = name
+	base.Status = action
+	base.ID = name
+	base.Type = imageEventType
+	base.Action = action
+	base.Actor = eventjournal.Actor{ID: name, Attributes: attributes}
+	return &base
+}
@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
+)
+
+// MetricsPath is the HTTP path of the metrics served by `nerdctl system metrics`.
+const MetricsPath = "/metrics"
+
+// Metrics serves the stats of the running containers of the namespace in the Prometheus text exposition format,
+// until ctx is done.
+func Metrics(ctx context.Context, client *containerd.Client, options types.SystemMetricsOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	collector, err := container.NewStatsCollector(ctx, client, options.GOptions)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(MetricsPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", statsutil.PrometheusContentType)
+		if err := statsutil.WritePrometheus(w, collector.Stats()); err != nil {
+			log.G(ctx).WithError(err).Warn("failed to write metrics")
+		}
+	})
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	l, err := net.Listen("tcp", options.Listen)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("serving metrics on http://%s%s", l.Addr(), MetricsPath)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(l)
+	}()
+	select {
+	case <-ctx.Done():
+	case err = <-collector.Err():
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		return err
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer shutdownCancel()
+	return errors.Join(err, srv.Shutdown(shutdownCtx))
+}
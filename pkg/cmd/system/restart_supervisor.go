@@ -0,0 +1,268 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
+)
+
+const taskExitTopic = "/tasks/exit"
+
+// RestartSupervisor enforces the restart policies of the containers created with `--restart-supervisor=nerdctl`.
+// It first starts the stopped containers that have to be running, like Docker does when the daemon starts,
+// then restarts the containers which init process exits, with Docker's backoff, until ctx is done.
+func RestartSupervisor(ctx context.Context, client *containerd.Client, options types.SystemRestartSupervisorOptions) error {
+	filter := fmt.Sprintf("topic==%q,namespace==%q", taskExitTopic, options.GOptions.Namespace)
+	eventsCh, errCh := client.EventService().Subscribe(ctx, filter)
+
+	s := &supervisor{
+		client:        client,
+		globalOptions: options.GOptions,
+		pending:       make(map[string]struct{}),
+	}
+	if err := s.reconcile(ctx); err != nil {
+		return err
+	}
+
+	c := make(chan *events.Envelope)
+	defer close(c)
+	eh := eventutil.InitEventHandler()
+	eh.Handle(taskExitTopic, func(e events.Envelope) {
+		s.handleExit(ctx, e)
+	})
+	go eh.Watch(c)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case e := <-eventsCh:
+			c <- e
+		}
+	}
+}
+
+type supervisor struct {
+	client        *containerd.Client
+	globalOptions types.GlobalCommandOptions
+
+	mu sync.Mutex
+	// pending is the set of the containers waiting to be restarted
+	pending map[string]struct{}
+}
+
+// reconcile starts the stopped containers that have to be running.
+func (s *supervisor) reconcile(ctx context.Context) error {
+	containers, err := s.client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get the labels of container %s", c.ID())
+			continue
+		}
+		if _, ok := l[labels.RestartPolicy]; !ok {
+			continue
+		}
+		task, err := c.Task(ctx, nil)
+		if err != nil {
+			// Containers that were never started are left alone
+			continue
+		}
+		status, err := task.Status(ctx)
+		if err != nil || status.Status != containerd.Stopped {
+			continue
+		}
+		go s.restart(ctx, c.ID(), status.ExitStatus, 0, true)
+	}
+	return nil
+}
+
+// handleExit restarts the container which init process exited, if its policy requires it.
+func (s *supervisor) handleExit(ctx context.Context, e events.Envelope) {
+	v, err := typeurl.UnmarshalAny(e.Event)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("cannot unmarshal an event from Any")
+		return
+	}
+	ev, ok := v.(*eventstypes.TaskExit)
+	if !ok || ev.ID != ev.ContainerID {
+		// Only the exit of the init process stops the container
+		return
+	}
+	c, err := s.client.LoadContainer(ctx, ev.ContainerID)
+	if err != nil {
+		return
+	}
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return
+	}
+	if _, ok := l[labels.RestartPolicy]; !ok {
+		return
+	}
+	var ranFor time.Duration
+	if lf, err := state.New(l[labels.StateDir]); err == nil {
+		if err := lf.Load(); err == nil && !lf.StartedAt.IsZero() && ev.ExitedAt != nil {
+			ranFor = ev.ExitedAt.AsTime().Sub(lf.StartedAt)
+		}
+	}
+	s.restart(ctx, ev.ContainerID, ev.ExitStatus, ranFor, false)
+}
+
+// restart restarts the container after the backoff if its policy requires it.
+// When reconciling, the container is started immediately and its restart count is preserved.
+func (s *supervisor) restart(ctx context.Context, id string, exitCode uint32, ranFor time.Duration, reconciling bool) {
+	s.mu.Lock()
+	if _, ok := s.pending[id]; ok {
+		s.mu.Unlock()
+		return
+	}
+	s.pending[id] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.doRestart(ctx, id, exitCode, ranFor, reconciling); err != nil {
+		if errdefs.IsNotFound(err) {
+			log.G(ctx).Debugf("container %s was removed before being restarted", id)
+			return
+		}
+		log.G(ctx).WithError(err).Errorf("failed to restart container %s", id)
+	}
+}
+
+func (s *supervisor) doRestart(ctx context.Context, id string, exitCode uint32, ranFor time.Duration, reconciling bool) error {
+	c, err := s.client.LoadContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	policy, err := restartsupervisor.ParsePolicy(l[labels.RestartPolicy])
+	if err != nil {
+		return err
+	}
+	stateDir := l[labels.StateDir]
+	rs, err := restartsupervisor.ReadState(stateDir)
+	if err != nil {
+		return err
+	}
+	explicitlyStopped := l[restart.ExplicitlyStoppedLabel] == "true"
+
+	var backoff time.Duration
+	if reconciling {
+		if !policy.ShouldStartOnReconcile(exitCode, explicitlyStopped, rs.RestartCount) {
+			return nil
+		}
+		backoff = rs.Backoff
+	} else {
+		if !policy.ShouldRestart(exitCode, explicitlyStopped, rs.RestartCount) {
+			return nil
+		}
+		backoff = restartsupervisor.NextBackoff(rs.Backoff, ranFor)
+		if err := restartsupervisor.UpdateState(stateDir, func(st *restartsupervisor.State) error {
+			st.Restarting = true
+			return nil
+		}); err != nil {
+			return err
+		}
+		log.G(ctx).Debugf("restarting container %s in %s", id, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		// The container may have been stopped or started by the user during the backoff
+		l, err = c.Labels(ctx)
+		if err != nil {
+			return err
+		}
+		if l[restart.ExplicitlyStoppedLabel] == "true" || isRunning(ctx, c) {
+			return restartsupervisor.UpdateState(stateDir, func(st *restartsupervisor.State) error {
+				st.Restarting = false
+				return nil
+			})
+		}
+	}
+
+	if err := containerutil.Start(ctx, c, false, false, s.client, s.globalOptions, ""); err != nil {
+		return err
+	}
+	count := rs.RestartCount
+	if !reconciling {
+		count++
+	}
+	// containerutil.Start resets the restart state, as it does when the user starts the container
+	if err := restartsupervisor.UpdateState(stateDir, func(st *restartsupervisor.State) error {
+		st.RestartCount = count
+		st.Backoff = backoff
+		st.Restarting = false
+		st.StartedAt = time.Now()
+		return nil
+	}); err != nil {
+		return err
+	}
+	if reconciling {
+		return nil
+	}
+	ev := &eventstypes.ContainerUpdate{
+		ID:     id,
+		Labels: map[string]string{restartsupervisor.RestartCountEventLabel: fmt.Sprint(count)},
+	}
+	if err := s.client.EventService().Publish(ctx, restartsupervisor.EventTopic, ev); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to publish the restart event")
+	}
+	return nil
+}
+
+func isRunning(ctx context.Context, c containerd.Container) bool {
+	task, err := c.Task(ctx, nil)
+	if err != nil {
+		return false
+	}
+	status, err := task.Status(ctx)
+	return err == nil && status.Status == containerd.Running
+}
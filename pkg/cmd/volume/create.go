@@ -17,17 +17,28 @@
 package volume
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/docker/docker/pkg/stringid"
 
+	"github.com/containerd/errdefs"
+
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func Create(name string, options types.VolumeCreateOptions) (*native.Volume, error) {
+	if options.Driver != "" && options.Driver != volumestore.LocalDriver {
+		return nil, fmt.Errorf("unsupported volume driver %q, only %q is supported (%w)", options.Driver, volumestore.LocalDriver, errdefs.ErrNotImplemented)
+	}
+	if err := mountutil.ValidateVolumeOptions(options.Options); err != nil {
+		return nil, errors.Join(errdefs.ErrInvalidArgument, err)
+	}
 	if name == "" {
 		name = stringid.GenerateRandomID()
 		options.Labels = append(options.Labels, labels.AnonymousVolumes+"=")
@@ -37,7 +48,7 @@ func Create(name string, options types.VolumeCreateOptions) (*native.Volume, err
 		return nil, err
 	}
 	labels := strutil.DedupeStrSlice(options.Labels)
-	vol, err := volStore.Create(name, labels)
+	vol, err := volStore.Create(name, labels, options.Options)
 	if err != nil {
 		return nil, err
 	}
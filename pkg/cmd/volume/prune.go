@@ -22,10 +22,12 @@ import (
 	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 )
 
 func Prune(ctx context.Context, client *containerd.Client, options types.VolumePruneOptions) error {
@@ -64,6 +66,10 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 					continue
 				}
 			}
+			if err := mountutil.UnmountVolume(volume); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to unmount volume %q, skipping", volume.Name)
+				continue
+			}
 			toRemove = append(toRemove, volume.Name)
 		}
 
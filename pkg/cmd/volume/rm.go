@@ -28,6 +28,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 )
@@ -43,6 +44,15 @@ func Remove(ctx context.Context, client *containerd.Client, volumes []string, op
 		return err
 	}
 
+	// Volumes with driver options have to be unmounted before removal.
+	// Their info is read here, as volStore.Get cannot be called from inside the lock.
+	mountedVolumes := make(map[string]*native.Volume)
+	for _, name := range volumes {
+		if vol, err := volStore.Get(name, false); err == nil && len(vol.Options) > 0 {
+			mountedVolumes[name] = vol
+		}
+	}
+
 	// Note: to avoid racy behavior, this is called by volStore.Remove *inside a lock*
 	removableVolumes := func() (volumeNames []string, cannotRemove []error, err error) {
 		usedVolumesList, err := usedVolumes(ctx, containers)
@@ -55,6 +65,12 @@ func Remove(ctx context.Context, client *containerd.Client, volumes []string, op
 				cannotRemove = append(cannotRemove, fmt.Errorf("volume %q is in use (%w)", name, errdefs.ErrFailedPrecondition))
 				continue
 			}
+			if vol, ok := mountedVolumes[name]; ok {
+				if err := mountutil.UnmountVolume(vol); err != nil {
+					cannotRemove = append(cannotRemove, err)
+					continue
+				}
+			}
 			volumeNames = append(volumeNames, name)
 		}
 
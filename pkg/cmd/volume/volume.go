@@ -17,8 +17,16 @@
 package volume
 
 import (
+	"context"
+	"errors"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
+	"github.com/containerd/nerdctl/v2/pkg/store"
 )
 
 // Store returns a volume store
@@ -30,3 +38,50 @@ func Store(ns string, dataRoot string, address string) (volumestore.VolumeStore,
 	}
 	return volumestore.New(dataStore, ns)
 }
+
+// UnmountUnused unmounts the volumes among `names` that were created with driver options,
+// and that are not used by any container anymore, running or not. It is called when containers are removed,
+// not when they stop, so a volume stays mounted while a stopped container still refers to it.
+func UnmountUnused(ctx context.Context, client *containerd.Client, volStore volumestore.VolumeStore, names []string) error {
+	// Note: volStore.Get locks the store, so, it cannot be called after volStore.Lock
+	var mounted []*native.Volume
+	for _, name := range names {
+		vol, err := volStore.Get(name, false)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if len(vol.Options) > 0 {
+			mounted = append(mounted, vol)
+		}
+	}
+	if len(mounted) == 0 {
+		return nil
+	}
+
+	// Lock the store so that no container can start using these volumes while we are unmounting them
+	if err := volStore.Lock(); err != nil {
+		return err
+	}
+	defer volStore.Release()
+
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	usedVolumesList, err := usedVolumes(ctx, containers)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, vol := range mounted {
+		if _, ok := usedVolumesList[vol.Name]; ok {
+			continue
+		}
+		errs = append(errs, mountutil.UnmountVolume(vol))
+	}
+	return errors.Join(errs...)
+}
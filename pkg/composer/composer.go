@@ -52,6 +52,8 @@ type Options struct {
 	DebugPrintFull   bool // full debug print, may leak secret env var to logs
 	Experimental     bool // enable experimental features
 	IPFSAddress      string
+	// DataStore is where the secrets and the configs which cannot be bind-mounted from a file are materialized
+	DataStore string
 }
 
 func New(o Options, client *containerd.Client) (*Composer, error) {
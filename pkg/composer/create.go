@@ -186,6 +186,12 @@ func (c *Composer) createServiceContainer(ctx context.Context, service *servicep
 	defer os.RemoveAll(tempDir)
 	cidFilename := filepath.Join(tempDir, "cid")
 
+	fileFlags, err := c.materializeFiles(ctx, container)
+	if err != nil {
+		return "", fmt.Errorf("error while creating container %s: %w", container.Name, err)
+	}
+	container.RunArgs = append(fileFlags, container.RunArgs...)
+
 	//add metadata labels to container https://github.com/compose-spec/compose-spec/blob/master/spec.md#labels
 	container.RunArgs = append([]string{
 		"--cidfile=" + cidFilename,
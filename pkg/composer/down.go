@@ -69,6 +69,10 @@ func (c *Composer) Down(ctx context.Context, downOptions DownOptions) error {
 		}
 	}
 
+	if err := c.removeFiles(ctx); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to remove the secrets and the configs of the project")
+	}
+
 	for shortName := range c.project.Networks {
 		if err := c.downNetwork(ctx, shortName); err != nil {
 			return err
@@ -0,0 +1,133 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+)
+
+// filesMu serializes the materialization of the files of the replicas, which are created in parallel
+var filesMu sync.Mutex
+
+// filesDir returns the directory where the secrets and the configs of the project are materialized.
+// The directory is backed by a tmpfs when possible, so that secrets are never written to the disk.
+func (c *Composer) filesDir(ctx context.Context) (string, error) {
+	if c.DataStore == "" {
+		return "", errors.New("got empty data store")
+	}
+	namespace, err := namespaces.NamespaceRequired(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.DataStore, "compose", namespace, c.project.Name), nil
+}
+
+// materializeFiles writes the secrets and the configs of the container which cannot be bind-mounted from
+// their source file, and returns the "-v" flags for mounting them read-only.
+func (c *Composer) materializeFiles(ctx context.Context, container serviceparser.Container) ([]string, error) {
+	if len(container.Files) == 0 {
+		return nil, nil
+	}
+	dir, err := c.filesDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := mountFilesDir(dir); err != nil {
+		// secrets must never be written to the disk
+		if slices.ContainsFunc(container.Files, func(f serviceparser.File) bool { return f.Secret }) {
+			return nil, fmt.Errorf("failed to mount a tmpfs on %s for the secrets of %s: %w", dir, container.Name, err)
+		}
+		log.G(ctx).WithError(err).Warnf("failed to mount a tmpfs on %s, configs will be stored on the disk", dir)
+	}
+
+	var flags []string
+	for _, f := range container.Files {
+		kind := "configs"
+		if f.Secret {
+			kind = "secrets"
+		}
+		p := filepath.Join(dir, container.Name, kind, f.Source)
+		if err := writeFile(p, f); err != nil {
+			return nil, fmt.Errorf("failed to materialize %s %s: %w", kind[:len(kind)-1], f.Source, err)
+		}
+		flags = append(flags, fmt.Sprintf("-v=%s:%s:ro", p, f.Target))
+	}
+	return flags, nil
+}
+
+// EnsureFiles materializes again the secrets and the configs of the existing containers of the service before they are started,
+// as the tmpfs holding them does not survive a reboot.
+func (c *Composer) EnsureFiles(ctx context.Context, service *serviceparser.Service) error {
+	for _, container := range service.Containers {
+		if _, err := c.materializeFiles(ctx, container); err != nil {
+			return fmt.Errorf("error while materializing the files of container %s: %w", container.Name, err)
+		}
+	}
+	return nil
+}
+
+func writeFile(p string, f serviceparser.File) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data := []byte(f.Content)
+	if f.HostPath != "" {
+		var err error
+		if data, err = os.ReadFile(f.HostPath); err != nil {
+			return err
+		}
+	}
+	// overwrite in place rather than renaming, so that running containers see the new content
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Chown(p, f.UID, f.GID); err != nil {
+		return err
+	}
+	return os.Chmod(p, f.Mode)
+}
+
+// removeFiles removes the secrets and the configs materialized for the project.
+func (c *Composer) removeFiles(ctx context.Context) error {
+	dir, err := c.filesDir(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := unmountFilesDir(dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// mountFilesDir mounts a tmpfs on dir, unless already mounted.
+func mountFilesDir(dir string) error {
+	mounted, err := isMountpoint(dir)
+	if err != nil || mounted {
+		return err
+	}
+	return unix.Mount("tmpfs", dir, "tmpfs", uintptr(unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_NODEV), "mode=0700")
+}
+
+// unmountFilesDir unmounts the tmpfs mounted on dir, if any.
+func unmountFilesDir(dir string) error {
+	mounted, err := isMountpoint(dir)
+	if err != nil || !mounted {
+		return err
+	}
+	return unix.Unmount(dir, unix.MNT_DETACH)
+}
+
+func isMountpoint(dir string) (bool, error) {
+	var st, parent unix.Stat_t
+	if err := unix.Stat(dir, &st); err != nil {
+		return false, err
+	}
+	if err := unix.Stat(filepath.Dir(dir), &parent); err != nil {
+		return false, err
+	}
+	return st.Dev != parent.Dev, nil
+}
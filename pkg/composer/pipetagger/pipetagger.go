@@ -96,16 +96,19 @@ type PipeTagger struct {
 func (x *PipeTagger) Run() error {
 	scanner := bufio.NewScanner(x.r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if x.width < 0 {
-			fmt.Fprintln(x.w, line)
-		} else {
-			fmt.Fprintf(x.w, "%s%s|%s\n",
-				x.color.Sprint(x.tag),
-				strings.Repeat(" ", x.width-len(x.tag)),
-				line,
-			)
-		}
+		fmt.Fprintln(x.w, x.TagLine(scanner.Text()))
 	}
 	return scanner.Err()
 }
+
+// TagLine returns line prefixed with the tag, as written by Run.
+func (x *PipeTagger) TagLine(line string) string {
+	if x.width < 0 {
+		return line
+	}
+	return fmt.Sprintf("%s%s|%s",
+		x.color.Sprint(x.tag),
+		strings.Repeat(" ", x.width-len(x.tag)),
+		line,
+	)
+}
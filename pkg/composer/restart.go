@@ -26,6 +26,7 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
@@ -36,16 +37,59 @@ type RestartOptions struct {
 
 // Restart restarts running/stopped containers in `services`. It calls
 // `nerdctl restart CONTAINER_ID` to do the actual job.
+// The services that depend on a restarted service with `restart: true` are restarted as well.
 func (c *Composer) Restart(ctx context.Context, opt RestartOptions, services []string) error {
-	// in dependency order
-	return c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+	restarted := make(map[string]bool)
+	restartService := func(name string, svc *types.ServiceConfig) error {
+		restarted[svc.Name] = true
 		containers, err := c.Containers(ctx, svc.Name)
 		if err != nil {
 			return err
 		}
+		parsed, err := serviceparser.Parse(c.project, *svc)
+		if err != nil {
+			return err
+		}
+		if err := c.EnsureFiles(ctx, parsed); err != nil {
+			return err
+		}
 
 		return c.restartContainers(ctx, containers, opt)
-	})
+	}
+
+	// in dependency order
+	if err := c.project.ForEachService(services, restartService); err != nil {
+		return err
+	}
+
+	// repeat until no more dependents are found, as a restarted dependent may itself be a dependency
+	for {
+		dependents := c.restartDependents(restarted)
+		if len(dependents) == 0 {
+			return nil
+		}
+		if err := c.project.ForEachService(dependents, restartService, types.IgnoreDependencies); err != nil {
+			return err
+		}
+	}
+}
+
+// restartDependents returns the services that have to be restarted because they declare
+// `restart: true` in `depends_on` for one of the restarted services.
+func (c *Composer) restartDependents(restarted map[string]bool) []string {
+	var dependents []string
+	for _, svc := range c.project.Services {
+		if restarted[svc.Name] {
+			continue
+		}
+		for depName, dep := range svc.DependsOn {
+			if dep.Restart && restarted[depName] {
+				dependents = append(dependents, svc.Name)
+				break
+			}
+		}
+	}
+	return dependents
 }
 
 func (c *Composer) restartContainers(ctx context.Context, containers []containerd.Container, opt RestartOptions) error {
@@ -19,6 +19,7 @@ package serviceparser
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -69,6 +70,7 @@ func warnUnknownFields(svc types.ServiceConfig) {
 		"ContainerName",
 		"DependsOn",
 		"Deploy",
+		"Develop", // handled by `nerdctl compose watch`
 		"Devices",
 		"Dockerfile", // handled by the loader (normalizer)
 		"DNS",
@@ -76,13 +78,17 @@ func warnUnknownFields(svc types.ServiceConfig) {
 		"DNSOpts",
 		"Entrypoint",
 		"Environment",
+		"Expose",
 		"Extends", // handled by the loader
 		"Extensions",
+		"ExternalLinks",
 		"ExtraHosts",
+		"HealthCheck",
 		"Hostname",
 		"Image",
 		"Init",
 		"Labels",
+		"Links",
 		"Logging",
 		"MemLimit",
 		"Networks",
@@ -125,11 +131,13 @@ func warnUnknownFields(svc types.ServiceConfig) {
 	for depName, dep := range svc.DependsOn {
 		if unknown := reflectutil.UnknownNonEmptyFields(&dep,
 			"Condition",
+			"Required",
+			"Restart",
 		); len(unknown) > 0 {
 			log.L.Warnf("Ignoring: service %s: depends_on: %s: %+v", svc.Name, depName, unknown)
 		}
 		switch dep.Condition {
-		case "", types.ServiceConditionStarted:
+		case "", types.ServiceConditionStarted, types.ServiceConditionHealthy, types.ServiceConditionCompletedSuccessfully:
 			// NOP
 		default:
 			log.L.Warnf("Ignoring: service %s: depends_on: %s: condition %s", svc.Name, depName, dep.Condition)
@@ -185,6 +193,20 @@ func warnUnknownFields(svc types.ServiceConfig) {
 		}
 	}
 
+	if svc.Develop != nil {
+		for i, trigger := range svc.Develop.Watch {
+			if unknown := reflectutil.UnknownNonEmptyFields(&trigger,
+				"Path",
+				"Action",
+				"Target",
+				"Include",
+				"Ignore",
+			); len(unknown) > 0 {
+				log.L.Warnf("Ignoring: service %s: develop.watch[%d]: %+v", svc.Name, i, unknown)
+			}
+		}
+	}
+
 	// unknown fields of Build is checked in parseBuild().
 }
 
@@ -192,6 +214,26 @@ type Container struct {
 	Name    string   // e.g., "compose-wordpress_wordpress_1"
 	RunArgs []string // {"--pull=never", ...}
 	Mkdir   []string // For Bind.CreateHostPath
+	// Files are the secrets and the configs to be materialized by the composer before creating the container.
+	// The corresponding "-v" flags are not part of RunArgs, as the composer decides where they are materialized.
+	Files []File
+}
+
+// DefaultFileMode is the mode of the secrets and the configs materialized by the composer, unless specified.
+const DefaultFileMode os.FileMode = 0o444
+
+// File is a secret or a config whose content does not come from a file (`environment` and `content` sources),
+// or whose ownership or mode is specified.
+type File struct {
+	Secret bool
+	Source string // e.g., "db_password"
+	Target string // e.g., "/run/secrets/db_password"
+	// HostPath is set for `file` sources, Content for `environment` and `content` sources
+	HostPath string
+	Content  string
+	UID      int
+	GID      int
+	Mode     os.FileMode
 }
 
 type Build struct {
@@ -409,6 +451,29 @@ func getNetworks(project *types.Project, svc types.ServiceConfig) ([]networkName
 	return fullNames, nil
 }
 
+// getLinks returns the links to the containers, e.g., {"compose-wordpress-db-1:db"}.
+// A link to a service ("<service>[:<alias>]") targets its first replica.
+// External links ("<container>[:<alias>]") are passed as is.
+func getLinks(project *types.Project, svc types.ServiceConfig) ([]string, error) {
+	links := make([]string, 0, len(svc.Links)+len(svc.ExternalLinks))
+	for _, link := range svc.Links {
+		serviceName, alias, _ := strings.Cut(link, ":")
+		target, err := project.GetService(serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link %q: %w", link, err)
+		}
+		if alias == "" {
+			alias = serviceName
+		}
+		containerName := target.ContainerName
+		if containerName == "" {
+			containerName = DefaultContainerName(project.Name, serviceName, "1")
+		}
+		links = append(links, containerName+":"+alias)
+	}
+	return append(links, svc.ExternalLinks...), nil
+}
+
 func Parse(project *types.Project, svc types.ServiceConfig) (*Service, error) {
 	warnUnknownFields(svc)
 
@@ -464,6 +529,45 @@ func Parse(project *types.Project, svc types.ServiceConfig) (*Service, error) {
 	return parsed, nil
 }
 
+// healthCheckToFlags converts the healthcheck of a service to `nerdctl run` flags.
+func healthCheckToFlags(hc *types.HealthCheckConfig) ([]string, error) {
+	if hc.Disable || (len(hc.Test) > 0 && hc.Test[0] == "NONE") {
+		return []string{"--no-healthcheck"}, nil
+	}
+	var flags []string
+	if len(hc.Test) > 0 {
+		switch hc.Test[0] {
+		case "CMD-SHELL":
+			flags = append(flags, "--health-cmd="+strings.Join(hc.Test[1:], " "))
+		case "CMD":
+			// the exec form is passed as a JSON array, so that the arguments are run without a shell
+			b, err := json.Marshal(hc.Test[1:])
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, "--health-cmd="+string(b))
+		default:
+			return nil, fmt.Errorf("unsupported healthcheck test %v", hc.Test)
+		}
+	}
+	if hc.Interval != nil {
+		flags = append(flags, fmt.Sprintf("--health-interval=%s", time.Duration(*hc.Interval)))
+	}
+	if hc.Timeout != nil {
+		flags = append(flags, fmt.Sprintf("--health-timeout=%s", time.Duration(*hc.Timeout)))
+	}
+	if hc.StartPeriod != nil {
+		flags = append(flags, fmt.Sprintf("--health-start-period=%s", time.Duration(*hc.StartPeriod)))
+	}
+	if hc.StartInterval != nil {
+		flags = append(flags, fmt.Sprintf("--health-start-interval=%s", time.Duration(*hc.StartInterval)))
+	}
+	if hc.Retries != nil {
+		flags = append(flags, fmt.Sprintf("--health-retries=%d", *hc.Retries))
+	}
+	return flags, nil
+}
+
 func newContainer(project *types.Project, parsed *Service, i int) (*Container, error) {
 	svc := *parsed.Unparsed
 	var c Container
@@ -599,9 +703,22 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 			if value != nil && value.MacAddress != "" {
 				c.RunArgs = append(c.RunArgs, "--mac-address="+value.MacAddress)
 			}
+			if value != nil {
+				for _, alias := range value.Aliases {
+					c.RunArgs = append(c.RunArgs, fmt.Sprintf("--network-alias=%s:%s", net.fullName, alias))
+				}
+			}
 		}
 	}
 
+	links, err := getLinks(project, svc)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		c.RunArgs = append(c.RunArgs, "--link="+link)
+	}
+
 	if netTypeContainer && svc.Hostname != "" {
 		return nil, fmt.Errorf("conflicting options: hostname and container network mode")
 	}
@@ -643,10 +760,22 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 		c.RunArgs = append(c.RunArgs, "-p="+pStr)
 	}
 
+	for _, e := range svc.Expose {
+		c.RunArgs = append(c.RunArgs, "--expose="+e)
+	}
+
 	if svc.Privileged {
 		c.RunArgs = append(c.RunArgs, "--privileged")
 	}
 
+	if svc.HealthCheck != nil {
+		hcArgs, err := healthCheckToFlags(svc.HealthCheck)
+		if err != nil {
+			return nil, err
+		}
+		c.RunArgs = append(c.RunArgs, hcArgs...)
+	}
+
 	if svc.ReadOnly {
 		c.RunArgs = append(c.RunArgs, "--read-only")
 	}
@@ -704,20 +833,28 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 
 	for _, config := range svc.Configs {
 		fileRef := types.FileReferenceConfig(config)
-		vStr, err := fileReferenceConfigToFlagV(fileRef, project, false)
+		vStr, f, err := fileReferenceConfigToFlagV(fileRef, project, false)
 		if err != nil {
 			return nil, err
 		}
-		c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		if f != nil {
+			c.Files = append(c.Files, *f)
+		} else {
+			c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		}
 	}
 
 	for _, secret := range svc.Secrets {
 		fileRef := types.FileReferenceConfig(secret)
-		vStr, err := fileReferenceConfigToFlagV(fileRef, project, true)
+		vStr, f, err := fileReferenceConfigToFlagV(fileRef, project, true)
 		if err != nil {
 			return nil, err
 		}
-		c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		if f != nil {
+			c.Files = append(c.Files, *f)
+		} else {
+			c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		}
 	}
 
 	for _, tmpfs := range svc.Tmpfs {
@@ -846,7 +983,10 @@ func serviceVolumeConfigToFlagV(c types.ServiceVolumeConfig, project *types.Proj
 	return s, mkdir, nil
 }
 
-func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Project, secret bool) (string, error) {
+// fileReferenceConfigToFlagV returns the "-v" flag for bind-mounting a secret or a config from a file.
+// When the secret or the config has to be materialized by the composer (environment or content source,
+// or specified ownership or mode), the returned flag is empty and the *File is returned instead.
+func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Project, secret bool) (string, *File, error) {
 	objType := "config"
 	if secret {
 		objType = "secret"
@@ -858,29 +998,23 @@ func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Proj
 	}
 
 	if err := identifiers.ValidateDockerCompat(c.Source); err != nil {
-		return "", fmt.Errorf("invalid source name for %s: %w", objType, err)
+		return "", nil, fmt.Errorf("invalid source name for %s: %w", objType, err)
 	}
 
 	var obj types.FileObjectConfig
 	if secret {
 		secret, ok := project.Secrets[c.Source]
 		if !ok {
-			return "", fmt.Errorf("secret %s is undefined", c.Source)
+			return "", nil, fmt.Errorf("secret %s is undefined", c.Source)
 		}
 		obj = types.FileObjectConfig(secret)
 	} else {
 		config, ok := project.Configs[c.Source]
 		if !ok {
-			return "", fmt.Errorf("config %s is undefined", c.Source)
+			return "", nil, fmt.Errorf("config %s is undefined", c.Source)
 		}
 		obj = types.FileObjectConfig(config)
 	}
-	src := project.RelativePath(obj.File)
-	var err error
-	src, err = filepath.Abs(src)
-	if err != nil {
-		return "", fmt.Errorf("%s %s: invalid relative path %q: %w", objType, c.Source, src, err)
-	}
 
 	target := c.Target
 	if target == "" {
@@ -895,24 +1029,63 @@ func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Proj
 			if secret {
 				target = filepath.Join("/run/secrets", target)
 			} else {
-				return "", fmt.Errorf("config %s: target %q must be an absolute path", c.Source, c.Target)
+				return "", nil, fmt.Errorf("config %s: target %q must be an absolute path", c.Source, c.Target)
 			}
 		}
 	}
 
+	f := &File{
+		Secret: secret,
+		Source: c.Source,
+		Target: target,
+		Mode:   DefaultFileMode,
+	}
 	if c.UID != "" {
-		// Raise an error rather than ignoring the value, for avoiding any security issue
-		return "", fmt.Errorf("%s %s: unsupported field: UID", objType, c.Source)
+		uid, err := strconv.Atoi(c.UID)
+		if err != nil || uid < 0 {
+			return "", nil, fmt.Errorf("%s %s: invalid uid %q", objType, c.Source, c.UID)
+		}
+		f.UID = uid
 	}
 	if c.GID != "" {
-		return "", fmt.Errorf("%s %s: unsupported field: GID", objType, c.Source)
+		gid, err := strconv.Atoi(c.GID)
+		if err != nil || gid < 0 {
+			return "", nil, fmt.Errorf("%s %s: invalid gid %q", objType, c.Source, c.GID)
+		}
+		f.GID = gid
 	}
 	if c.Mode != nil {
-		return "", fmt.Errorf("%s %s: unsupported field: Mode", objType, c.Source)
+		if *c.Mode < 0 || *c.Mode > 0o7777 {
+			return "", nil, fmt.Errorf("%s %s: invalid mode %s", objType, c.Source, c.Mode)
+		}
+		f.Mode = os.FileMode(*c.Mode)
 	}
+	materialize := c.UID != "" || c.GID != "" || c.Mode != nil
 
-	s := fmt.Sprintf("%s:%s:ro", src, target)
-	return s, nil
+	switch {
+	case obj.File != "":
+		src := project.RelativePath(obj.File)
+		var err error
+		src, err = filepath.Abs(src)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s %s: invalid relative path %q: %w", objType, c.Source, src, err)
+		}
+		if !materialize {
+			return fmt.Sprintf("%s:%s:ro", src, target), nil, nil
+		}
+		f.HostPath = src
+	case obj.Environment != "":
+		value, ok := project.Environment[obj.Environment]
+		if !ok {
+			return "", nil, fmt.Errorf("%s %s: environment variable %q is not set", objType, c.Source, obj.Environment)
+		}
+		f.Content = value
+	case obj.Content != "":
+		f.Content = obj.Content
+	default:
+		return "", nil, fmt.Errorf("%s %s: lacks file, environment or content", objType, c.Source)
+	}
+	return "", f, nil
 }
 
 // DefaultImageName returns the image name following compose naming logic.
@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -482,6 +483,95 @@ services:
 
 }
 
+func TestParseNetworkAliasesAndLinks(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  db:
+    image: mariadb:10.5
+    networks:
+      back:
+        aliases:
+          - database
+          - mysql
+  cache:
+    image: redis:alpine
+    container_name: redis
+  web:
+    image: nginx:alpine
+    links:
+      - db
+      - cache:kv
+    external_links:
+      - legacy:old
+networks:
+  back:
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	dbSvc, err := project.GetService("db")
+	assert.NilError(t, err)
+
+	db, err := Parse(project, dbSvc)
+	assert.NilError(t, err)
+
+	t.Logf("db: %+v", db)
+	backNet := comp.ProjectName() + "_back"
+	for _, c := range db.Containers {
+		assert.Assert(t, in(c.RunArgs, "--net="+backNet))
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+backNet+":database"))
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+backNet+":mysql"))
+	}
+
+	webSvc, err := project.GetService("web")
+	assert.NilError(t, err)
+
+	web, err := Parse(project, webSvc)
+	assert.NilError(t, err)
+
+	t.Logf("web: %+v", web)
+	for _, c := range web.Containers {
+		assert.Assert(t, in(c.RunArgs, "--link="+DefaultContainerName(project.Name, "db", "1")+":db"))
+		assert.Assert(t, in(c.RunArgs, "--link=redis:kv"))
+		assert.Assert(t, in(c.RunArgs, "--link=legacy:old"))
+	}
+}
+
+func TestParseExpose(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  foo:
+    image: nginx:alpine
+    expose:
+      - "3000"
+      - "8000-8010/udp"
+      - 9090
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	fooSvc, err := project.GetService("foo")
+	assert.NilError(t, err)
+
+	foo, err := Parse(project, fooSvc)
+	assert.NilError(t, err)
+
+	t.Logf("foo: %+v", foo)
+	for _, c := range foo.Containers {
+		assert.Assert(t, in(c.RunArgs, "--expose=3000"))
+		assert.Assert(t, in(c.RunArgs, "--expose=8000-8010/udp"))
+		assert.Assert(t, in(c.RunArgs, "--expose=9090"))
+	}
+}
+
 func TestParseConfigs(t *testing.T) {
 	t.Parallel()
 	if runtime.GOOS == "windows" {
@@ -541,6 +631,77 @@ configs:
 	}
 }
 
+func TestParseSecretSources(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("test is not compatible with windows")
+	}
+	const dockerComposeYAML = `
+services:
+  foo:
+    image: nginx:alpine
+    secrets:
+    - secret1
+    - source: secret2
+      uid: "1000"
+      gid: "1001"
+      mode: 0400
+    configs:
+    - source: config1
+      target: /etc/config1
+secrets:
+  secret1:
+    environment: SECRET1
+  secret2:
+    file: ./secret2
+configs:
+  config1:
+    content: content-config1
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), map[string]string{"SECRET1": "content-secret1"})
+	assert.NilError(t, err)
+
+	fooSvc, err := project.GetService("foo")
+	assert.NilError(t, err)
+
+	foo, err := Parse(project, fooSvc)
+	assert.NilError(t, err)
+
+	t.Logf("foo: %+v", foo)
+	for _, c := range foo.Containers {
+		for _, arg := range c.RunArgs {
+			assert.Assert(t, !strings.HasPrefix(arg, "-v="), arg)
+		}
+		assert.DeepEqual(t, c.Files, []File{
+			{
+				Source:  "config1",
+				Target:  "/etc/config1",
+				Content: "content-config1",
+				Mode:    DefaultFileMode,
+			},
+			{
+				Secret:  true,
+				Source:  "secret1",
+				Target:  "/run/secrets/secret1",
+				Content: "content-secret1",
+				Mode:    DefaultFileMode,
+			},
+			{
+				Secret:   true,
+				Source:   "secret2",
+				Target:   "/run/secrets/secret2",
+				HostPath: filepath.Join(project.WorkingDir, "secret2"),
+				UID:      1000,
+				GID:      1001,
+				Mode:     0o400,
+			},
+		})
+	}
+}
+
 func TestParseRestartPolicy(t *testing.T) {
 	t.Parallel()
 	const dockerComposeYAML = `
@@ -586,3 +747,56 @@ services:
 	c = getContainersFromService("unless_stopped")[0]
 	assert.Assert(t, in(c.RunArgs, "--restart=unless-stopped"))
 }
+
+func TestParseHealthCheck(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  shell:
+    image: alpine:3.14
+    healthcheck:
+      test: ["CMD-SHELL", "wget -q -O- http://localhost/ || exit 1"]
+      interval: 10s
+      timeout: 3s
+      retries: 5
+      start_period: 30s
+      start_interval: 2s
+  exec:
+    image: alpine:3.14
+    healthcheck:
+      test: ["CMD", "echo", "it's fine"]
+  disabled:
+    image: alpine:3.14
+    healthcheck:
+      disable: true
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	getContainersFromService := func(svcName string) []Container {
+		svcConfig, err := project.GetService(svcName)
+		assert.NilError(t, err)
+		svc, err := Parse(project, svcConfig)
+		assert.NilError(t, err)
+
+		return svc.Containers
+	}
+
+	var c Container
+	c = getContainersFromService("shell")[0]
+	assert.Assert(t, in(c.RunArgs, "--health-cmd=wget -q -O- http://localhost/ || exit 1"))
+	assert.Assert(t, in(c.RunArgs, "--health-interval=10s"))
+	assert.Assert(t, in(c.RunArgs, "--health-timeout=3s"))
+	assert.Assert(t, in(c.RunArgs, "--health-retries=5"))
+	assert.Assert(t, in(c.RunArgs, "--health-start-period=30s"))
+	assert.Assert(t, in(c.RunArgs, "--health-start-interval=2s"))
+
+	c = getContainersFromService("exec")[0]
+	assert.Assert(t, in(c.RunArgs, `--health-cmd=["echo","it's fine"]`))
+
+	c = getContainersFromService("disabled")[0]
+	assert.Assert(t, in(c.RunArgs, "--no-healthcheck"))
+}
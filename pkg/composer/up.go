@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 
@@ -43,6 +44,8 @@ type UpOptions struct {
 	NoRecreate           bool
 	Scale                map[string]int // map of service name to replicas
 	Pull                 string
+	// DependencyTimeout is the maximum time to wait for the `depends_on` conditions of each service, 0 means no timeout
+	DependencyTimeout time.Duration
 }
 
 func (opts UpOptions) recreateStrategy() string {
@@ -122,12 +125,20 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 }
 
 func validateFileObjectConfig(obj types.FileObjectConfig, shortName, objType string, project *types.Project) error {
-	if unknown := reflectutil.UnknownNonEmptyFields(&obj, "Name", "External", "File"); len(unknown) > 0 {
+	if unknown := reflectutil.UnknownNonEmptyFields(&obj, "Name", "External", "File", "Environment", "Content"); len(unknown) > 0 {
 		log.L.Warnf("Ignoring: %s %s: %+v", objType, shortName, unknown)
 	}
 
-	if obj.File == "" {
-		return fmt.Errorf("%s %q: lacks file path", objType, shortName)
+	switch {
+	case obj.Environment != "":
+		if _, ok := project.Environment[obj.Environment]; !ok {
+			return fmt.Errorf("%s %q: environment variable %q is not set", objType, shortName, obj.Environment)
+		}
+		return nil
+	case obj.Content != "":
+		return nil
+	case obj.File == "":
+		return fmt.Errorf("%s %q: lacks file path, environment or content", objType, shortName)
 	}
 	fullPath := project.RelativePath(obj.File)
 	if _, err := os.Stat(fullPath); err != nil {
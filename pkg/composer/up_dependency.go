@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// DefaultDependencyTimeout is the default maximum time to wait for the `depends_on` conditions of a service.
+const DefaultDependencyTimeout = 5 * time.Minute
+
+// dependencyPollInterval is the interval between two checks of the status of the dependencies.
+const dependencyPollInterval = time.Second
+
+// waitForDependencies blocks until the dependencies of ps reach the condition set in `depends_on`.
+// The `service_started` condition is already satisfied by the dependency order of the services.
+// Failures of dependencies with `required: false` are logged and ignored.
+func (c *Composer) waitForDependencies(ctx context.Context, ps *serviceparser.Service, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	var depNames []string
+	for depName := range ps.Unparsed.DependsOn {
+		depNames = append(depNames, depName)
+	}
+	sort.Strings(depNames)
+
+	for _, depName := range depNames {
+		dep := ps.Unparsed.DependsOn[depName]
+		switch dep.Condition {
+		case types.ServiceConditionHealthy, types.ServiceConditionCompletedSuccessfully:
+		default:
+			continue
+		}
+		if err := c.waitForDependency(ctx, ps.Unparsed.Name, depName, dep.Condition, deadline); err != nil {
+			if !dep.Required {
+				log.G(ctx).Warnf("Ignoring optional dependency %s of service %s: %v", depName, ps.Unparsed.Name, err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Composer) waitForDependency(ctx context.Context, service, depName, condition string, deadline time.Time) error {
+	log.G(ctx).Infof("Service %s is waiting for %s (condition: %s)", service, depName, condition)
+	for {
+		containers, err := c.Containers(ctx, depName)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			return fmt.Errorf("service %s depends on service %s, which has no container", service, depName)
+		}
+		met := true
+		for _, container := range containers {
+			ok, err := dependencyConditionMet(ctx, container, condition)
+			if err != nil {
+				return fmt.Errorf("dependency %s of service %s failed: %w", depName, service, err)
+			}
+			met = met && ok
+		}
+		if met {
+			log.G(ctx).Infof("Service %s: dependency %s is ready (condition: %s)", service, depName, condition)
+			return nil
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for dependency %s of service %s (condition: %s)", depName, service, condition)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dependencyPollInterval):
+		}
+	}
+}
+
+// dependencyConditionMet returns true if the container satisfies the condition,
+// and an error if the condition can no longer be satisfied.
+func dependencyConditionMet(ctx context.Context, container containerd.Container, condition string) (bool, error) {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		return false, err
+	}
+	name := containerLabels[labels.Name]
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, fmt.Errorf("container %s has not been started", name)
+		}
+		return false, err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	switch condition {
+	case types.ServiceConditionCompletedSuccessfully:
+		if status.Status != containerd.Stopped {
+			return false, nil
+		}
+		if status.ExitStatus != 0 {
+			return false, fmt.Errorf("container %s exited with code %d", name, status.ExitStatus)
+		}
+		return true, nil
+	case types.ServiceConditionHealthy:
+		if status.Status == containerd.Stopped {
+			return false, fmt.Errorf("container %s exited with code %d", name, status.ExitStatus)
+		}
+		health, err := healthcheck.ContainerHealth(containerLabels)
+		if err != nil {
+			return false, err
+		}
+		if health == nil {
+			return false, fmt.Errorf("container %s has no healthcheck configured", name)
+		}
+		switch health.Status {
+		case healthcheck.Healthy:
+			return true, nil
+		case healthcheck.Unhealthy:
+			return false, fmt.Errorf("container %s is unhealthy", name)
+		default:
+			return false, nil
+		}
+	default:
+		return true, nil
+	}
+}
@@ -36,7 +36,7 @@ func (c *Composer) upNetwork(ctx context.Context, shortName string) error {
 		return nil
 	}
 
-	if unknown := reflectutil.UnknownNonEmptyFields(&net, "Name", "Ipam", "Driver", "DriverOpts"); len(unknown) > 0 {
+	if unknown := reflectutil.UnknownNonEmptyFields(&net, "Name", "Ipam", "Driver", "DriverOpts", "Internal"); len(unknown) > 0 {
 		log.G(ctx).Warnf("Ignoring: network %s: %+v", shortName, unknown)
 	}
 
@@ -57,6 +57,10 @@ func (c *Composer) upNetwork(ctx context.Context, shortName string) error {
 			createArgs = append(createArgs, fmt.Sprintf("--driver=%s", net.Driver))
 		}
 
+		if net.Internal {
+			createArgs = append(createArgs, "--internal")
+		}
+
 		if net.DriverOpts != nil {
 			for k, v := range net.DriverOpts {
 				createArgs = append(createArgs, fmt.Sprintf("--opt=%s=%s", k, v))
@@ -55,6 +55,9 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 	)
 	for _, ps := range parsedServices {
 		ps := ps
+		if err := c.waitForDependencies(ctx, ps, uo.DependencyTimeout); err != nil {
+			return err
+		}
 		var runEG errgroup.Group
 		services = append(services, ps.Unparsed.Name)
 		for _, container := range ps.Containers {
@@ -145,6 +148,9 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 
 	// start the existing container and exit early
 	if existingCid != "" && recreate == RecreateNever {
+		if _, err := c.materializeFiles(ctx, container); err != nil {
+			return "", fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
+		}
 		cmd := c.createNerdctlCmd(ctx, append([]string{"start"}, existingCid)...)
 		if err := c.executeUpCmd(ctx, cmd, container.Name, runFlagD, service.Unparsed.StdinOpen); err != nil {
 			return "", fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
@@ -182,6 +188,12 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 		container.RunArgs = append([]string{"--env-file=" + c.EnvFile}, container.RunArgs...)
 	}
 
+	fileFlags, err := c.materializeFiles(ctx, container)
+	if err != nil {
+		return "", fmt.Errorf("error while creating container %s: %w", container.Name, err)
+	}
+	container.RunArgs = append(fileFlags, container.RunArgs...)
+
 	//add metadata labels to container https://github.com/compose-spec/compose-spec/blob/master/spec.md#labels
 	container.RunArgs = append([]string{
 		"--cidfile=" + cidFilename,
@@ -36,7 +36,7 @@ func (c *Composer) upVolume(ctx context.Context, shortName string) error {
 		return nil
 	}
 
-	if unknown := reflectutil.UnknownNonEmptyFields(&vol, "Name"); len(unknown) > 0 {
+	if unknown := reflectutil.UnknownNonEmptyFields(&vol, "Name", "Driver", "DriverOpts"); len(unknown) > 0 {
 		log.G(ctx).Warnf("Ignoring: volume %s: %+v", shortName, unknown)
 	}
 
@@ -54,8 +54,14 @@ func (c *Composer) upVolume(ctx context.Context, shortName string) error {
 		createArgs := []string{
 			fmt.Sprintf("--label=%s=%s", labels.ComposeProject, c.project.Name),
 			fmt.Sprintf("--label=%s=%s", labels.ComposeVolume, shortName),
-			fullName,
 		}
+		if vol.Driver != "" {
+			createArgs = append(createArgs, fmt.Sprintf("--driver=%s", vol.Driver))
+		}
+		for k, v := range vol.DriverOpts {
+			createArgs = append(createArgs, fmt.Sprintf("--opt=%s=%s", k, v))
+		}
+		createArgs = append(createArgs, fullName)
 		if err := c.runNerdctlCmd(ctx, append([]string{"volume", "create"}, createArgs...)...); err != nil {
 			return err
 		}
@@ -0,0 +1,418 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/fsnotify/fsnotify"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+)
+
+// WatchOptions stores all option input from `nerdctl compose watch`
+type WatchOptions struct {
+	NoUp bool
+}
+
+// watchQuietPeriod is the delay without any change after which the changes are applied.
+// Editors and `git checkout` typically write several files at once.
+const watchQuietPeriod = 500 * time.Millisecond
+
+// defaultWatchIgnore are the patterns always ignored, relative to the path of a trigger:
+// VCS metadata and temporary files created by editors.
+var defaultWatchIgnore = []string{
+	"**/.git",
+	"**/*~",
+	"**/.*.swp",
+	"**/.*.swx",
+	"**/.#*",
+	"**/4913",
+}
+
+type watchTrigger struct {
+	service string
+	trigger types.Trigger
+	// path is the absolute path of trigger.Path
+	path    string
+	include *patternmatcher.PatternMatcher
+	ignore  *patternmatcher.PatternMatcher
+	// contextDir and dockerignore are set when the service has a build section
+	contextDir   string
+	dockerignore *patternmatcher.PatternMatcher
+}
+
+// rel returns the slash-separated path of p relative to the path of the trigger.
+func (t *watchTrigger) rel(p string) (string, bool) {
+	rel, err := filepath.Rel(t.path, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// ignored returns true if p is ignored by the `ignore` patterns of the trigger, or by the .dockerignore of the service.
+func (t *watchTrigger) ignored(p string) bool {
+	rel, ok := t.rel(p)
+	if !ok {
+		return true
+	}
+	if matches(t.ignore, rel) {
+		return true
+	}
+	if t.dockerignore != nil {
+		if ctxRel, err := filepath.Rel(t.contextDir, p); err == nil && matches(t.dockerignore, ctxRel) {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts returns true if a change of p has to trigger the action.
+func (t *watchTrigger) accepts(p string) bool {
+	if t.ignored(p) {
+		return false
+	}
+	if t.include != nil {
+		rel, _ := t.rel(p)
+		return matches(t.include, rel)
+	}
+	return true
+}
+
+// matches returns true if the relative path p, or one of its parent directories, matches the patterns of pm.
+// The semantics are the same as .dockerignore for `nerdctl build`.
+func matches(pm *patternmatcher.PatternMatcher, p string) bool {
+	matched, err := pm.MatchesOrParentMatches(filepath.FromSlash(p))
+	if err != nil {
+		log.L.WithError(err).Warnf("failed to match %q against the watch patterns", p)
+		return false
+	}
+	return matched
+}
+
+// readDockerignore reads the patterns of the .dockerignore file of contextDir.
+// A missing file results in an empty list.
+func readDockerignore(contextDir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(contextDir, ".dockerignore"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return ignorefile.ReadAll(f)
+}
+
+// Watch watches the paths declared in the `develop.watch` section of `services`, and applies the
+// changes to the containers: `sync` copies the changed files into the containers, `rebuild` builds the image
+// and recreates the containers, `sync+restart` copies the files and restarts the containers, `restart` restarts the containers.
+// Watch returns when ctx is done.
+func (c *Composer) Watch(ctx context.Context, wo WatchOptions, services []string) error {
+	triggers, err := c.watchTriggers(services)
+	if err != nil {
+		return err
+	}
+	if len(triggers) == 0 {
+		return errors.New("none of the selected services is configured for watch, consider setting a 'develop' section")
+	}
+
+	if !wo.NoUp {
+		if err := c.Up(ctx, UpOptions{Detach: true}, services); err != nil {
+			return err
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	defer watcher.Close()
+	for _, t := range triggers {
+		if err := addWatches(watcher, t, t.path); err != nil {
+			return err
+		}
+		log.G(ctx).Infof("Watching %s for service %s (action: %s)", t.path, t.service, t.trigger.Action)
+	}
+
+	var (
+		pending = make(map[string]struct{})
+		timer   <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if st, err := os.Stat(event.Name); err == nil && st.IsDir() {
+					for _, t := range triggers {
+						if !t.ignored(event.Name) {
+							if err := addWatches(watcher, t, event.Name); err != nil {
+								log.G(ctx).WithError(err).Warnf("failed to watch %s", event.Name)
+							}
+						}
+					}
+				}
+			}
+			pending[event.Name] = struct{}{}
+			timer = time.After(watchQuietPeriod)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.G(ctx).WithError(err).Warn("watch error")
+		case <-timer:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			pending = make(map[string]struct{})
+			timer = nil
+			c.applyWatchChanges(ctx, triggers, paths)
+		}
+	}
+}
+
+// watchTriggers returns the triggers of the `develop.watch` section of services.
+func (c *Composer) watchTriggers(services []string) ([]*watchTrigger, error) {
+	var triggers []*watchTrigger
+	err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+		if svc.Develop == nil {
+			return nil
+		}
+		var (
+			contextDir string
+			dockerIgn  *patternmatcher.PatternMatcher
+		)
+		if svc.Build != nil {
+			contextDir = c.project.RelativePath(svc.Build.Context)
+			patterns, err := readDockerignore(contextDir)
+			if err != nil {
+				return fmt.Errorf("service %s: .dockerignore: %w", svc.Name, err)
+			}
+			if dockerIgn, err = patternmatcher.New(patterns); err != nil {
+				return fmt.Errorf("service %s: .dockerignore: %w", svc.Name, err)
+			}
+		}
+		for i, trigger := range svc.Develop.Watch {
+			prefix := fmt.Sprintf("service %s: develop.watch[%d]", svc.Name, i)
+			if trigger.Path == "" {
+				return fmt.Errorf("%s: path is required", prefix)
+			}
+			switch trigger.Action {
+			case types.WatchActionSync, types.WatchActionSyncRestart, types.WatchActionSyncExec:
+				if trigger.Target == "" {
+					return fmt.Errorf("%s: target is required for action %s", prefix, trigger.Action)
+				}
+				if trigger.Action == types.WatchActionSyncExec {
+					log.L.Warnf("%s: exec is not supported yet, the files will be synced only", prefix)
+				}
+			case types.WatchActionRebuild:
+				if svc.Build == nil {
+					return fmt.Errorf("%s: action rebuild requires a build section", prefix)
+				}
+			case types.WatchActionRestart:
+			default:
+				return fmt.Errorf("%s: unsupported action %q", prefix, trigger.Action)
+			}
+			t := &watchTrigger{
+				service:      svc.Name,
+				trigger:      trigger,
+				path:         c.project.RelativePath(trigger.Path),
+				contextDir:   contextDir,
+				dockerignore: dockerIgn,
+			}
+			var err error
+			if t.ignore, err = patternmatcher.New(append(defaultWatchIgnore, trigger.Ignore...)); err != nil {
+				return fmt.Errorf("%s: ignore: %w", prefix, err)
+			}
+			if len(trigger.Include) > 0 {
+				if t.include, err = patternmatcher.New(trigger.Include); err != nil {
+					return fmt.Errorf("%s: include: %w", prefix, err)
+				}
+			}
+			triggers = append(triggers, t)
+		}
+		return nil
+	}, types.IgnoreDependencies)
+	return triggers, err
+}
+
+// addWatches watches root and its subdirectories which are not ignored by t.
+func addWatches(watcher *fsnotify.Watcher, t *watchTrigger, root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			if p == root {
+				// the trigger path is a file
+				return watcher.Add(p)
+			}
+			return nil
+		}
+		if p != t.path && t.ignored(p) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(p)
+	})
+}
+
+// applyWatchChanges applies the actions of the triggers matching the changed paths.
+// A rebuild supersedes the other actions of the same service.
+// Errors are logged, so that watching goes on.
+func (c *Composer) applyWatchChanges(ctx context.Context, triggers []*watchTrigger, paths []string) {
+	type serviceChanges struct {
+		rebuild bool
+		restart bool
+		syncs   map[string]string // key: host path, value: container path
+	}
+	var (
+		changes = make(map[string]*serviceChanges)
+		order   []string
+	)
+	for _, t := range triggers {
+		for _, p := range paths {
+			if !t.accepts(p) {
+				continue
+			}
+			sc, ok := changes[t.service]
+			if !ok {
+				sc = &serviceChanges{syncs: make(map[string]string)}
+				changes[t.service] = sc
+				order = append(order, t.service)
+			}
+			switch t.trigger.Action {
+			case types.WatchActionRebuild:
+				sc.rebuild = true
+			case types.WatchActionRestart:
+				sc.restart = true
+			case types.WatchActionSync, types.WatchActionSyncRestart, types.WatchActionSyncExec:
+				rel, _ := t.rel(p)
+				sc.syncs[p] = path.Join(t.trigger.Target, rel)
+				if t.trigger.Action == types.WatchActionSyncRestart {
+					sc.restart = true
+				}
+			}
+		}
+	}
+
+	for _, service := range order {
+		sc := changes[service]
+		if sc.rebuild {
+			log.G(ctx).Infof("Rebuilding service %s after changes were detected", service)
+			if err := c.rebuildService(ctx, service); err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to rebuild service %s", service)
+			}
+			continue
+		}
+		for hostPath, containerPath := range sc.syncs {
+			if err := c.syncPath(ctx, service, hostPath, containerPath); err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to sync %s to service %s", hostPath, service)
+			}
+		}
+		if sc.restart {
+			containers, err := c.Containers(ctx, service)
+			if err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to list the containers of service %s", service)
+				continue
+			}
+			if err := c.restartContainers(ctx, containers, RestartOptions{}); err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to restart service %s", service)
+			}
+		}
+	}
+}
+
+// syncPath copies hostPath to containerPath in the containers of service,
+// or removes containerPath if hostPath was removed.
+func (c *Composer) syncPath(ctx context.Context, service, hostPath, containerPath string) error {
+	st, err := os.Stat(hostPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		containers, err := c.Containers(ctx, service)
+		if err != nil {
+			return err
+		}
+		for _, container := range containers {
+			log.G(ctx).Infof("Removing %s from container %s", containerPath, container.ID())
+			if err := c.runNerdctlCmd(ctx, "exec", container.ID(), "rm", "-rf", containerPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	src := hostPath
+	if st.IsDir() {
+		// copy the content of the directory, whether containerPath exists or not
+		src = hostPath + string(filepath.Separator) + "."
+	}
+	return c.Copy(ctx, CopyOptions{
+		Source:      src,
+		Destination: service + ":" + containerPath,
+	})
+}
+
+// rebuildService builds the image of service and recreates its containers.
+func (c *Composer) rebuildService(ctx context.Context, service string) error {
+	svc, err := c.project.GetService(service)
+	if err != nil {
+		return err
+	}
+	ps, err := serviceparser.Parse(c.project, svc)
+	if err != nil {
+		return err
+	}
+	if ps.Build == nil {
+		return fmt.Errorf("service %s has no build section", service)
+	}
+	if err := c.buildServiceImage(ctx, ps.Image, ps.Build, ps.Unparsed.Platform, BuildOptions{}); err != nil {
+		return err
+	}
+	for _, container := range ps.Containers {
+		if _, err := c.upServiceContainer(ctx, ps, container, RecreateForce); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -44,27 +44,30 @@ type Config struct {
 	// CDISpecDirs is a list of directories in which CDI specifications can be found.
 	CDISpecDirs []string `toml:"cdi_spec_dirs,omitempty"`
 	UsernsRemap string   `toml:"userns_remap, omitempty"`
+	// RestartSupervisor is the component enforcing restart policies, "containerd" or "nerdctl".
+	RestartSupervisor string `toml:"restart_supervisor,omitempty"`
 }
 
 // New creates a default Config object statically,
 // without interpolating CLI flags, env vars, and toml.
 func New() *Config {
 	return &Config{
-		Debug:            false,
-		DebugFull:        false,
-		Address:          defaults.DefaultAddress,
-		Namespace:        namespaces.Default,
-		Snapshotter:      defaults.DefaultSnapshotter,
-		CNIPath:          ncdefaults.CNIPath(),
-		CNINetConfPath:   ncdefaults.CNINetConfPath(),
-		DataRoot:         ncdefaults.DataRoot(),
-		CgroupManager:    ncdefaults.CgroupManager(),
-		InsecureRegistry: false,
-		HostsDir:         ncdefaults.HostsDirs(),
-		Experimental:     true,
-		HostGatewayIP:    ncdefaults.HostGatewayIP(),
-		KubeHideDupe:     false,
-		CDISpecDirs:      ncdefaults.CDISpecDirs(),
-		UsernsRemap:      "",
+		Debug:             false,
+		DebugFull:         false,
+		Address:           defaults.DefaultAddress,
+		Namespace:         namespaces.Default,
+		Snapshotter:       defaults.DefaultSnapshotter,
+		CNIPath:           ncdefaults.CNIPath(),
+		CNINetConfPath:    ncdefaults.CNINetConfPath(),
+		DataRoot:          ncdefaults.DataRoot(),
+		CgroupManager:     ncdefaults.CgroupManager(),
+		InsecureRegistry:  false,
+		HostsDir:          ncdefaults.HostsDirs(),
+		Experimental:      true,
+		HostGatewayIP:     ncdefaults.HostGatewayIP(),
+		KubeHideDupe:      false,
+		CDISpecDirs:       ncdefaults.CDISpecDirs(),
+		UsernsRemap:       "",
+		RestartSupervisor: "containerd",
 	}
 }
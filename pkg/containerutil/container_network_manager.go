@@ -273,6 +273,8 @@ func (m *noneNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (
 	opts := m.netOpts
 	// Cannot have a MAC address in host networking mode.
 	opts.MACAddress = ""
+	// Ports cannot be published in this networking mode.
+	opts.PublishAll = false
 	return opts, nil
 }
 
@@ -371,9 +373,10 @@ func (m *containerNetworkManager) VerifyNetworkOptions(_ context.Context) error
 		"--hostname":   m.netOpts.Hostname,
 		"--domainname": m.netOpts.Domainname,
 		// NOTE: an empty slice still counts as a non-zero value so we check its length:
-		"-p/--publish": len(m.netOpts.PortMappings) != 0,
-		"--dns":        len(m.netOpts.DNSServers) != 0,
-		"--add-host":   len(m.netOpts.AddHost) != 0,
+		"-p/--publish":     len(m.netOpts.PortMappings) != 0,
+		"-P/--publish-all": m.netOpts.PublishAll,
+		"--dns":            len(m.netOpts.DNSServers) != 0,
+		"--add-host":       len(m.netOpts.AddHost) != 0,
 	})
 
 	if len(nonZeroParams) != 0 {
@@ -617,6 +620,8 @@ func (m *hostNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (
 	opts := m.netOpts
 	// Cannot have a MAC address in host networking mode.
 	opts.MACAddress = ""
+	// Ports cannot be published in this networking mode.
+	opts.PublishAll = false
 	return opts, nil
 }
 
@@ -19,8 +19,10 @@ package containerutil
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/oci"
@@ -29,7 +31,10 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/dnsserver"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
@@ -52,6 +57,18 @@ func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
 		}
 	}
 
+	if len(m.netOpts.PortMappings) > 0 || m.netOpts.PublishAll {
+		for _, netstr := range m.netOpts.NetworkSlice {
+			netw, err := e.NetworkByNameOrID(netstr)
+			if err != nil {
+				return err
+			}
+			if netw.Internal() {
+				return fmt.Errorf("conflicting options: ports cannot be published on internal network %q", netstr)
+			}
+		}
+	}
+
 	return validateUtsSettings(m.netOpts)
 }
 
@@ -73,8 +90,55 @@ func (m *cniNetworkManager) CleanupNetworking(_ context.Context, _ containerd.Co
 }
 
 // Returns the set of NetworkingOptions which should be set as labels on the container.
-func (m *cniNetworkManager) InternalNetworkingOptionLabels(_ context.Context) (types.NetworkOptions, error) {
-	return m.netOpts, nil
+// The links are normalized to "<name>:<alias>".
+func (m *cniNetworkManager) InternalNetworkingOptionLabels(ctx context.Context) (types.NetworkOptions, error) {
+	opts := m.netOpts
+	if len(opts.Links) == 0 {
+		return opts, nil
+	}
+	opts.Links = make([]string, 0, len(m.netOpts.Links))
+	for _, link := range m.netOpts.Links {
+		target, alias, _ := strings.Cut(link, ":")
+		name, err := m.getLinkedContainerName(ctx, target)
+		if err != nil {
+			return opts, err
+		}
+		if alias == "" {
+			alias = name
+		}
+		opts.Links = append(opts.Links, name+":"+alias)
+	}
+	return opts, nil
+}
+
+// getLinkedContainerName returns the name of the container to link to.
+func (m *cniNetworkManager) getLinkedContainerName(ctx context.Context, target string) (string, error) {
+	var name string
+	walker := &containerwalker.ContainerWalker{
+		Client: m.client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("link: multiple containers found with prefix: %s", target)
+			}
+			l, err := found.Container.Labels(ctx)
+			if err != nil {
+				return err
+			}
+			name = l[labels.Name]
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", fmt.Errorf("link: could not find container: %s", target)
+	}
+	if name == "" {
+		return "", fmt.Errorf("link: container %s has no name", target)
+	}
+	return name, nil
 }
 
 // Returns a slice of `oci.SpecOpts` and `containerd.NewContainerOpts` which represent
@@ -179,6 +243,44 @@ func (m *cniNetworkManager) buildResolvConf(resolvConfPath string) error {
 		}
 	}
 
-	_, err = resolvconf.Build(resolvConfPath, append(slirp4Dns, nameServers...), searchDomains, dnsOptions)
+	nameServers = append(slirp4Dns, nameServers...)
+	if len(m.netOpts.DNSServers) == 0 {
+		// The embedded DNS server forwards the other queries to the nameservers of the host.
+		// The latter are not listed after it, as resolvers such as the one of musl query all the nameservers
+		// in parallel, and the first answer could come from a host nameserver that does not know the containers.
+		// The OCI hook falls back to the nameservers of the host when the server cannot be started.
+		gateway, err := m.embeddedDNSServer()
+		if err != nil {
+			return err
+		}
+		if gateway != "" {
+			nameServers = []string{gateway}
+		}
+	}
+
+	_, err = resolvconf.Build(resolvConfPath, nameServers, searchDomains, dnsOptions)
 	return err
 }
+
+// embeddedDNSServer returns the address of the embedded DNS server for the first network of the container,
+// or an empty string if the network does not use it.
+func (m *cniNetworkManager) embeddedDNSServer() (string, error) {
+	if len(m.netOpts.NetworkSlice) == 0 {
+		return "", nil
+	}
+	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath, netutil.WithNamespace(m.globalOptions.Namespace), netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
+	if err != nil {
+		return "", err
+	}
+	netw, err := e.NetworkByNameOrID(m.netOpts.NetworkSlice[0])
+	if err != nil {
+		return "", err
+	}
+	if !dnsserver.Enabled(netw.Name) {
+		return "", nil
+	}
+	if gateway := netw.Gateway(); gateway != nil {
+		return gateway.String(), nil
+	}
+	return "", nil
+}
@@ -59,6 +59,7 @@ func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
 		"--dns-servers":          len(m.netOpts.DNSServers) != 0,
 		"--dns-search":           len(m.netOpts.DNSSearchDomains) != 0,
 		"--add-host":             len(m.netOpts.AddHost) != 0,
+		"-P/--publish-all":       m.netOpts.PublishAll,
 	})
 	if len(nonZeroArgs) != 0 {
 		return fmt.Errorf("the following networking arguments are not supported on Windows: %+v", nonZeroArgs)
@@ -44,13 +44,18 @@ import (
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
 	"github.com/containerd/nerdctl/v2/pkg/errutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
@@ -213,7 +218,8 @@ func GenerateSharingPIDOpts(ctx context.Context, targetCon containerd.Container)
 }
 
 // Start starts `container` with `attach` flag. If `attach` is true, it will attach to the container's stdio.
-func Start(ctx context.Context, container containerd.Container, flagA bool, flagI bool, client *containerd.Client, detachKeys string) (err error) {
+// taskOpts are passed to the creation of the task, e.g., to restore the task from a checkpoint.
+func Start(ctx context.Context, container containerd.Container, flagA bool, flagI bool, client *containerd.Client, globalOptions types.GlobalCommandOptions, detachKeys string, taskOpts ...containerd.NewTaskOpts) (err error) {
 	// defer the storage of start error in the dedicated label
 	defer func() {
 		if err != nil {
@@ -257,7 +263,7 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 	logURI := lab[labels.LogURI]
 	namespace := lab[labels.Namespace]
 	cStatus := formatter.ContainerStatus(ctx, container)
-	if cStatus == "Up" {
+	if strings.HasPrefix(cStatus, "Up") {
 		log.G(ctx).Warnf("container %s is already running", container.ID())
 		return nil
 	}
@@ -272,11 +278,20 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 	if err := UpdateExplicitlyStoppedLabel(ctx, container, false); err != nil {
 		return err
 	}
+	if _, ok := lab[labels.RestartPolicy]; ok && lab[labels.StateDir] != "" {
+		// Like Docker, starting a container resets its restart count
+		if err := restartsupervisor.ResetState(lab[labels.StateDir]); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to reset the restart state of container %s", container.ID())
+		}
+	}
 	if oldTask, err := container.Task(ctx, nil); err == nil {
 		if _, err := oldTask.Delete(ctx); err != nil {
 			log.G(ctx).WithError(err).Debug("failed to delete old task")
 		}
 	}
+	if err := mountVolumes(lab, globalOptions); err != nil {
+		return err
+	}
 	detachC := make(chan struct{})
 	attachStreamOpt := []string{}
 	if flagA {
@@ -284,7 +299,7 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, flag
 		// source: https://github.com/containerd/nerdctl/blob/main/docs/command-reference.md#whale-nerdctl-start
 		attachStreamOpt = []string{"STDOUT", "STDERR"}
 	}
-	task, err := taskutil.NewTask(ctx, client, container, attachStreamOpt, flagI, flagT, true, con, logURI, detachKeys, namespace, detachC)
+	task, err := taskutil.NewTask(ctx, client, container, attachStreamOpt, flagI, flagT, true, con, logURI, detachKeys, namespace, detachC, taskOpts...)
 	if err != nil {
 		return err
 	}
@@ -586,6 +601,38 @@ func GetContainerVolumes(containerLabels map[string]string) []*ContainerVolume {
 	return vols
 }
 
+// mountVolumes mounts the volumes created with driver options that are used by the container,
+// in case they have been unmounted since the container was created (e.g., by a reboot of the host).
+func mountVolumes(containerLabels map[string]string, globalOptions types.GlobalCommandOptions) error {
+	namespace := containerLabels[labels.Namespace]
+	if namespace == "" {
+		return nil
+	}
+	var volStore volumestore.VolumeStore
+	for _, v := range GetContainerVolumes(containerLabels) {
+		if v.Type != mountutil.Volume || v.Name == "" {
+			continue
+		}
+		if volStore == nil {
+			dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+			if err != nil {
+				return err
+			}
+			if volStore, err = volumestore.New(dataStore, namespace); err != nil {
+				return err
+			}
+		}
+		vol, err := volStore.Get(v.Name, false)
+		if err != nil {
+			return err
+		}
+		if err := mountutil.MountVolume(vol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetContainerName(containerLabels map[string]string) string {
 	if name, ok := containerLabels[labels.Name]; ok {
 		return name
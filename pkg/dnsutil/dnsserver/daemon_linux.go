@@ -0,0 +1,293 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const (
+	// dirName is the directory of the server state, relative to the data store
+	dirName = "dns"
+	// lockFile is held by the running server
+	lockFile = "server.lock"
+	// logFile receives the output of the server started by EnsureRunning
+	logFile = "server.log"
+
+	// pollInterval is the interval between two reloads of the hosts store
+	pollInterval = 2 * time.Second
+	// idleTimeout is the time after which the server exits when no container uses it
+	idleTimeout = time.Minute
+)
+
+// Run serves the containers of the data store until ctx is done, or until no container used the server for a while.
+// Run returns immediately if another server is already running for the data store.
+// cniPath and cniNetconfPath are used to find the internal networks.
+func Run(ctx context.Context, dataStore, cniPath, cniNetconfPath string) error {
+	dir := filepath.Join(dataStore, dirName)
+	lock, err := tryLock(dir)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		log.G(ctx).Debug("the DNS server is already running")
+		return nil
+	}
+	defer func() {
+		if lock != nil {
+			lock.Close()
+		}
+	}()
+
+	d := &daemon{
+		server:         NewServer(upstreams(ctx)),
+		dataStore:      dataStore,
+		cniPath:        cniPath,
+		cniNetconfPath: cniNetconfPath,
+		listeners:      make(map[string]*Listener),
+		failed:         make(map[string]struct{}),
+	}
+	defer d.close()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var idleSince time.Time
+	for {
+		switch {
+		case d.reconcile(ctx) > 0:
+			idleSince = time.Time{}
+		case idleSince.IsZero():
+			idleSince = time.Now()
+		case time.Since(idleSince) > idleTimeout:
+			// Release the lock before the last check: a container started meanwhile is either seen here,
+			// or starts a new server
+			lock.Close()
+			lock = nil
+			if d.reconcile(ctx) == 0 {
+				return nil
+			}
+			if lock, err = tryLock(dir); err != nil || lock == nil {
+				return err
+			}
+			idleSince = time.Time{}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureRunning starts `nerdctl [global flags] internal dns-server` in the background, unless a server is already
+// running for the data store.
+func EnsureRunning(dataStore, nerdctlCmd string, nerdctlArgs []string) error {
+	dir := filepath.Join(dataStore, dirName)
+	lock, err := tryLock(dir)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return nil
+	}
+	// The server takes the lock itself; losing a race against another hook is harmless
+	lock.Close()
+
+	out, err := os.OpenFile(filepath.Join(dir, logFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	args := append(append([]string{}, nerdctlArgs...), "internal", "dns-server")
+	cmd := exec.Command(nerdctlCmd, args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start the DNS server: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+type daemon struct {
+	server    *Server
+	dataStore string
+	// cniPath and cniNetconfPath are used to find the internal networks
+	cniPath        string
+	cniNetconfPath string
+	// listeners maps the gateway addresses to their listener
+	listeners map[string]*Listener
+	// failed records the addresses that could not be listened on, to warn only once
+	failed map[string]struct{}
+}
+
+// reconcile reloads the records and listens on the gateways of the networks in use.
+// It returns the number of gateways.
+func (d *daemon) reconcile(ctx context.Context) int {
+	records, err := loadRecords(d.dataStore, d.cniPath, d.cniNetconfPath)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to load the DNS records")
+		return len(d.listeners)
+	}
+	d.server.SetRecords(records)
+
+	gateways := records.Gateways()
+	for addr, l := range d.listeners {
+		if network, ok := gateways[addr]; !ok || network != l.network {
+			l.Close()
+			delete(d.listeners, addr)
+		}
+	}
+	for addr := range d.failed {
+		if _, ok := gateways[addr]; !ok {
+			delete(d.failed, addr)
+		}
+	}
+	for addr, network := range gateways {
+		if _, ok := d.listeners[addr]; ok {
+			continue
+		}
+		var l *Listener
+		err := rootlessutil.WithDetachedNetNSIfAny(func() error {
+			var err error
+			l, err = d.server.Listen(net.JoinHostPort(addr, "53"), network)
+			return err
+		})
+		if err != nil {
+			if _, ok := d.failed[addr]; !ok {
+				log.G(ctx).WithError(err).Warnf("failed to serve DNS on %s for network %s", addr, network)
+				d.failed[addr] = struct{}{}
+			}
+			continue
+		}
+		log.G(ctx).Debugf("serving DNS on %s for network %s", addr, network)
+		delete(d.failed, addr)
+		d.listeners[addr] = l
+	}
+	return len(gateways)
+}
+
+func (d *daemon) close() {
+	for addr, l := range d.listeners {
+		l.Close()
+		delete(d.listeners, addr)
+	}
+}
+
+// loadRecords reads the records of all the namespaces of the data store.
+func loadRecords(dataStore, cniPath, cniNetconfPath string) (*Records, error) {
+	namespaces, err := hostsstore.Namespaces(dataStore)
+	if err != nil {
+		return nil, err
+	}
+	metas := make(map[string][]*hostsstore.Meta)
+	internal := make(map[string][]string)
+	for _, ns := range namespaces {
+		hs, err := hostsstore.New(dataStore, ns)
+		if err != nil {
+			return nil, err
+		}
+		if metas[ns], err = hs.List(); err != nil {
+			return nil, err
+		}
+		e, err := netutil.NewCNIEnv(cniPath, cniNetconfPath, netutil.WithNamespace(ns))
+		if err != nil {
+			return nil, err
+		}
+		networks, err := e.NetworkList()
+		if err != nil {
+			return nil, err
+		}
+		for _, netw := range networks {
+			if netw.Internal() {
+				internal[ns] = append(internal[ns], netw.Name)
+			}
+		}
+	}
+	return NewRecords(metas, internal), nil
+}
+
+// upstreams returns the addresses of the nameservers the queries are forwarded to.
+// The server runs in the host network namespace, except with rootless slirp4netns/pasta where it has to go
+// through the DNS of the rootless network driver, like the containers would.
+func upstreams(ctx context.Context) []string {
+	var servers []string
+	viaDriver := false
+	if rootlessutil.IsRootlessChild() {
+		if detached, err := rootlessutil.DetachedNetNS(); err == nil && detached == "" {
+			viaDriver = true
+			if dns, err := dnsutil.GetSlirp4netnsDNS(); err == nil {
+				servers = append(servers, dns...)
+			} else {
+				log.G(ctx).WithError(err).Warn("failed to get the DNS of the rootless network driver")
+			}
+		}
+	}
+	if conf, err := resolvconf.Get(); err == nil {
+		content := conf.Content
+		if viaDriver {
+			if filtered, err := resolvconf.FilterResolvDNS(content, true); err == nil {
+				content = filtered.Content
+			}
+		}
+		servers = append(servers, resolvconf.GetNameservers(content, resolvconf.IP)...)
+	} else {
+		log.G(ctx).WithError(err).Warn("failed to read the host resolv.conf")
+	}
+	addrs := make([]string, 0, len(servers))
+	for _, s := range servers {
+		addrs = append(addrs, net.JoinHostPort(s, "53"))
+	}
+	return addrs
+}
+
+// tryLock takes the server lock without blocking. A nil file is returned if the lock is held by another process.
+func tryLock(dir string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,277 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dnsserver provides the embedded DNS server of the containers connected to user-defined networks.
+//
+// A single server runs per data store, as `nerdctl internal dns-server`, and is started by the OCI hook when
+// a container using such a network starts. It listens on the gateway address of the networks, answers the A, AAAA
+// and PTR queries for the containers of the same namespace sharing a network with the querying container (container
+// names, host names, and so compose service names), and forwards the other queries to the nameservers of the host.
+// The queries received on internal networks are not forwarded, as the server must not be an egress path.
+// The queries of the addresses which are not known containers are refused.
+// The records are read from the hosts store, which is also used to generate /etc/hosts.
+package dnsserver
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+)
+
+// Enabled returns true if the containers connected to the network use the embedded DNS server.
+// Like Docker, the default network relies on /etc/hosts only.
+func Enabled(network string) bool {
+	return network != netutil.DefaultNetworkName
+}
+
+// Records are the DNS records served to the containers.
+type Records struct {
+	// names maps the namespaces and networks to the lower-cased names of the containers and their addresses
+	names map[string]map[string]map[string][]net.IP
+	// ptrs maps the namespaces and addresses to the names of the containers
+	ptrs map[string]map[string][]string
+	// sources maps the addresses of the containers to their namespace
+	sources map[string]string
+	// attached maps the addresses of the containers to the sorted networks they are connected to
+	attached map[string][]string
+	// gateways maps the gateway addresses to the networks to listen on
+	gateways map[string]string
+	// links maps the addresses of the linking containers to the aliases of the linked containers and their addresses
+	links map[string]map[string][]net.IP
+	// internal maps the namespaces to their internal networks
+	internal map[string]map[string]struct{}
+}
+
+// NewRecords returns the records of the containers, given their hosts store metadata and the names of the
+// internal networks by namespace.
+func NewRecords(metas map[string][]*hostsstore.Meta, internal map[string][]string) *Records {
+	r := &Records{
+		names:    make(map[string]map[string]map[string][]net.IP),
+		ptrs:     make(map[string]map[string][]string),
+		sources:  make(map[string]string),
+		attached: make(map[string][]string),
+		gateways: make(map[string]string),
+		links:    make(map[string]map[string][]net.IP),
+		internal: make(map[string]map[string]struct{}),
+	}
+	for namespace, networks := range internal {
+		r.internal[namespace] = make(map[string]struct{})
+		for _, network := range networks {
+			r.internal[namespace][network] = struct{}{}
+		}
+	}
+	// byName maps the namespaces, container names and networks to the addresses of the containers
+	byName := make(map[string]map[string]map[string][]net.IP)
+	for namespace, nsMetas := range metas {
+		byName[namespace] = make(map[string]map[string][]net.IP)
+		r.names[namespace] = make(map[string]map[string][]net.IP)
+		r.ptrs[namespace] = make(map[string][]string)
+		for _, meta := range nsMetas {
+			var attached []string
+			for network, res := range meta.Networks {
+				if res != nil && Enabled(network) {
+					attached = append(attached, network)
+				}
+			}
+			slices.Sort(attached)
+			for network, res := range meta.Networks {
+				if res == nil || !Enabled(network) {
+					continue
+				}
+				names := containerNames(meta, network)
+				if r.names[namespace][network] == nil {
+					r.names[namespace][network] = make(map[string][]net.IP)
+				}
+				for _, ipCfg := range res.IPs {
+					ip := ipCfg.Address.IP
+					if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+						continue
+					}
+					for _, name := range names {
+						r.names[namespace][network][name] = append(r.names[namespace][network][name], ip)
+					}
+					if len(names) > 0 {
+						// The most specific name comes first
+						r.ptrs[namespace][ip.String()] = append(r.ptrs[namespace][ip.String()], names[0])
+					}
+					r.sources[ip.String()] = namespace
+					r.attached[ip.String()] = attached
+					if meta.Name != "" {
+						if byName[namespace][meta.Name] == nil {
+							byName[namespace][meta.Name] = make(map[string][]net.IP)
+						}
+						byName[namespace][meta.Name][network] = append(byName[namespace][meta.Name][network], ip)
+					}
+					if ipCfg.Gateway != nil {
+						r.gateways[ipCfg.Gateway.String()] = network
+					}
+				}
+			}
+		}
+	}
+	// Links are only visible from the linking container, on the networks shared with the linked one
+	for namespace, nsMetas := range metas {
+		for _, meta := range nsMetas {
+			for network, res := range meta.Networks {
+				if res == nil || !Enabled(network) {
+					continue
+				}
+				for _, ipCfg := range res.IPs {
+					if ipCfg.Address.IP == nil {
+						continue
+					}
+					from := ipCfg.Address.IP.String()
+					for alias, name := range meta.Links {
+						ips := byName[namespace][name][network]
+						if len(ips) == 0 {
+							continue
+						}
+						if r.links[from] == nil {
+							r.links[from] = make(map[string][]net.IP)
+						}
+						r.links[from][normalize(alias)] = ips
+					}
+				}
+			}
+		}
+	}
+	return r
+}
+
+// containerNames returns the names of the container on the network, the most specific first.
+// The names are the same as in /etc/hosts: the FQDN, the host name, the container name, the latter two
+// suffixed with the network name, and the network-scoped aliases.
+func containerNames(meta *hostsstore.Meta, network string) []string {
+	var names []string
+	for _, name := range []string{meta.Name, meta.Hostname} {
+		if name != "" {
+			names = append(names, name+"."+network)
+		}
+	}
+	if meta.Hostname != "" && meta.Domainname != "" {
+		names = append(names, meta.Hostname+"."+meta.Domainname)
+	}
+	for _, name := range []string{meta.Name, meta.Hostname} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	names = append(names, meta.Aliases[network]...)
+	// The host name is often the container name
+	var unique []string
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// Gateways returns the gateway addresses to listen on, and their network.
+func (r *Records) Gateways() map[string]string {
+	return r.gateways
+}
+
+// Namespace returns the namespace of the container with the given address, or an empty string.
+func (r *Records) Namespace(ip net.IP) string {
+	return r.sources[ip.String()]
+}
+
+// Internal returns whether the network of the namespace is internal.
+func (r *Records) Internal(namespace, network string) bool {
+	_, ok := r.internal[namespace][network]
+	return ok
+}
+
+// Lookup returns the addresses of the name on the network, and whether the name is known.
+func (r *Records) Lookup(namespace, network, name string) ([]net.IP, bool) {
+	ips, ok := r.names[namespace][network][normalize(name)]
+	return ips, ok
+}
+
+// LookupFrom returns the addresses of the name on the network the query was received on, or else on the other
+// networks the container with the given address is connected to, like Docker. It also returns whether the name is known.
+func (r *Records) LookupFrom(namespace, network string, from net.IP, name string) ([]net.IP, bool) {
+	if ips, ok := r.Lookup(namespace, network, name); ok {
+		return ips, true
+	}
+	for _, attached := range r.attached[from.String()] {
+		if attached == network {
+			continue
+		}
+		if ips, ok := r.Lookup(namespace, attached, name); ok {
+			return ips, true
+		}
+	}
+	return nil, false
+}
+
+// LookupLink returns the addresses of the container linked with the name as alias by the container with the
+// given address, and whether such a link exists.
+func (r *Records) LookupLink(from net.IP, name string) ([]net.IP, bool) {
+	ips, ok := r.links[from.String()][normalize(name)]
+	return ips, ok
+}
+
+// Reverse returns the names of the address of a PTR query (e.g. "1.0.4.10.in-addr.arpa.").
+func (r *Records) Reverse(namespace, name string) []string {
+	ip := reverseIP(normalize(name))
+	if ip == nil {
+		return nil
+	}
+	return r.ptrs[namespace][ip.String()]
+}
+
+// normalize lower-cases the name and strips the trailing dot.
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// reverseIP returns the address of a reverse lookup name, or nil.
+func reverseIP(name string) net.IP {
+	if s, ok := strings.CutSuffix(name, ".in-addr.arpa"); ok {
+		labels := strings.Split(s, ".")
+		if len(labels) != 4 {
+			return nil
+		}
+		for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+			labels[i], labels[j] = labels[j], labels[i]
+		}
+		return net.ParseIP(strings.Join(labels, ".")).To4()
+	}
+	if s, ok := strings.CutSuffix(name, ".ip6.arpa"); ok {
+		nibbles := strings.Split(s, ".")
+		if len(nibbles) != 32 {
+			return nil
+		}
+		var b strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			if len(nibbles[i]) != 1 {
+				return nil
+			}
+			b.WriteString(nibbles[i])
+			if i%4 == 0 && i != 0 {
+				b.WriteByte(':')
+			}
+		}
+		return net.ParseIP(b.String())
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"net"
+	"testing"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+)
+
+func cniResult(ip, gateway string) *types100.Result {
+	return &types100.Result{
+		IPs: []*types100.IPConfig{
+			{
+				Address: net.IPNet{IP: net.ParseIP(ip), Mask: net.CIDRMask(24, 32)},
+				Gateway: net.ParseIP(gateway),
+			},
+		},
+	}
+}
+
+func testRecords() *Records {
+	return NewRecords(map[string][]*hostsstore.Meta{
+		"default": {
+			{
+				ID:         "1",
+				Name:       "web",
+				Hostname:   "web",
+				Domainname: "example.com",
+				Networks: map[string]*types100.Result{
+					"front":  cniResult("10.4.1.2", "10.4.1.1"),
+					"bridge": cniResult("10.4.0.2", "10.4.0.1"),
+				},
+			},
+			{
+				ID:       "2",
+				Name:     "project-db-1",
+				Hostname: "db",
+				Networks: map[string]*types100.Result{"front": cniResult("10.4.1.3", "10.4.1.1")},
+			},
+			{
+				ID:       "3",
+				Name:     "project-db-2",
+				Hostname: "db",
+				Networks: map[string]*types100.Result{"front": cniResult("10.4.1.4", "10.4.1.1")},
+			},
+			{
+				ID:       "5",
+				Name:     "cache",
+				Hostname: "cache",
+				Networks: map[string]*types100.Result{"front": cniResult("10.4.1.6", "10.4.1.1")},
+				Aliases:  map[string][]string{"front": {"redis"}},
+				Links:    map[string]string{"database": "project-db-1", "nowhere": "missing"},
+			},
+			{
+				ID:       "6",
+				Name:     "api",
+				Hostname: "api",
+				Networks: map[string]*types100.Result{
+					"front": cniResult("10.4.1.7", "10.4.1.1"),
+					"back":  cniResult("10.4.2.2", "10.4.2.1"),
+				},
+			},
+			{
+				ID:       "7",
+				Name:     "worker",
+				Hostname: "worker",
+				Networks: map[string]*types100.Result{"back": cniResult("10.4.2.3", "10.4.2.1")},
+			},
+		},
+		"other": {
+			{
+				ID:       "4",
+				Name:     "web",
+				Hostname: "web",
+				Networks: map[string]*types100.Result{"front": cniResult("10.4.1.5", "10.4.1.1")},
+			},
+		},
+	}, map[string][]string{"other": {"front"}})
+}
+
+func TestRecords(t *testing.T) {
+	r := testRecords()
+
+	assert.DeepEqual(t, r.Gateways(), map[string]string{"10.4.1.1": "front", "10.4.2.1": "back"})
+	assert.Equal(t, r.Namespace(net.ParseIP("10.4.1.5")), "other")
+	assert.Assert(t, r.Internal("other", "front"))
+	assert.Assert(t, !r.Internal("default", "front"))
+
+	ips, ok := r.Lookup("default", "front", "WEB.")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.1.2")})
+
+	for _, name := range []string{"web.front", "web.example.com"} {
+		_, ok = r.Lookup("default", "front", name)
+		assert.Assert(t, ok, name)
+	}
+
+	// The replicas of a compose service share the host name
+	ips, ok = r.Lookup("default", "front", "db")
+	assert.Assert(t, ok)
+	assert.Equal(t, len(ips), 2)
+
+	// The default network is not served
+	_, ok = r.Lookup("default", "bridge", "web")
+	assert.Assert(t, !ok)
+
+	// Namespaces are isolated
+	ips, ok = r.Lookup("other", "front", "web")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.1.5")})
+	_, ok = r.Lookup("other", "front", "db")
+	assert.Assert(t, !ok)
+
+	// A container connected to several networks resolves the names of all of them,
+	// while the names stay scoped to the networks shared with the querying container
+	ips, ok = r.LookupFrom("default", "front", net.ParseIP("10.4.1.7"), "worker")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.2.3")})
+	ips, ok = r.LookupFrom("default", "back", net.ParseIP("10.4.2.3"), "api")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.2.2")})
+	_, ok = r.LookupFrom("default", "front", net.ParseIP("10.4.1.2"), "worker")
+	assert.Assert(t, !ok)
+	_, ok = r.LookupFrom("default", "back", net.ParseIP("10.4.2.3"), "web")
+	assert.Assert(t, !ok)
+
+	// Network-scoped aliases are visible to all the containers of the network
+	ips, ok = r.Lookup("default", "front", "redis")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.1.6")})
+
+	// Links are only visible from the linking container
+	ips, ok = r.LookupLink(net.ParseIP("10.4.1.6"), "DATABASE")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, ips, []net.IP{net.ParseIP("10.4.1.3")})
+	_, ok = r.LookupLink(net.ParseIP("10.4.1.2"), "database")
+	assert.Assert(t, !ok)
+	_, ok = r.LookupLink(net.ParseIP("10.4.1.6"), "nowhere")
+	assert.Assert(t, !ok)
+
+	assert.DeepEqual(t, r.Reverse("default", "2.1.4.10.in-addr.arpa."), []string{"web.front"})
+	assert.Assert(t, r.Reverse("other", "2.1.4.10.in-addr.arpa.") == nil)
+}
+
+func TestReverseIP(t *testing.T) {
+	assert.DeepEqual(t, reverseIP("4.3.2.1.in-addr.arpa"), net.ParseIP("1.2.3.4").To4())
+	assert.DeepEqual(t, reverseIP("b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa"),
+		net.ParseIP("4321:0:1:2:3:4:567:89ab"))
+	assert.Assert(t, reverseIP("3.2.1.in-addr.arpa") == nil)
+	assert.Assert(t, reverseIP("example.com") == nil)
+}
@@ -0,0 +1,349 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// ttl is the TTL of the records of the containers, in seconds
+	ttl = 600
+	// maxUDPSize is the maximum size of a response over UDP, above which the response is truncated
+	maxUDPSize = 512
+	// forwardTimeout is the timeout of the queries forwarded to an upstream nameserver
+	forwardTimeout = 4 * time.Second
+	// tcpTimeout is the idle timeout of the TCP connections of the clients
+	tcpTimeout = 10 * time.Second
+)
+
+// Server answers the DNS queries of the containers.
+type Server struct {
+	mu        sync.RWMutex
+	records   *Records
+	upstreams []string
+	// rotation rotates the addresses of the names that have several, for round-robin load balancing
+	rotation atomic.Uint32
+}
+
+// NewServer returns a server forwarding the queries it cannot answer to the upstream nameservers,
+// given as "host:port" addresses.
+func NewServer(upstreams []string) *Server {
+	return &Server{
+		records:   NewRecords(nil, nil),
+		upstreams: upstreams,
+	}
+}
+
+// SetRecords replaces the records served.
+func (s *Server) SetRecords(records *Records) {
+	s.mu.Lock()
+	s.records = records
+	s.mu.Unlock()
+}
+
+func (s *Server) getRecords() *Records {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.records
+}
+
+// Resolve returns the response to the query received over proto ("udp" or "tcp") on the listener of the network,
+// or nil if the query is invalid and must be dropped.
+// The queries of the addresses which are not known containers are refused: the gateway address is reachable
+// from outside of the network, and the server must not be an open resolver.
+// Like Docker, the names which are not local fail on internal networks, instead of being forwarded.
+func (s *Server) Resolve(query []byte, proto string, from net.IP, network string) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil || h.Response {
+		return nil
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil
+	}
+	records := s.getRecords()
+	namespace := records.Namespace(from)
+	if namespace == "" {
+		log.L.Debugf("refusing the query for %s from %s, which is not a known container", q.Name, from)
+		return failure(h, q, dnsmessage.RCodeRefused)
+	}
+	if h.OpCode == 0 && q.Class == dnsmessage.ClassINET {
+		switch q.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+			if ips, ok := records.LookupLink(from, q.Name.String()); ok {
+				return s.answer(h, q, proto, s.addressResources(q, ips))
+			}
+			if ips, ok := records.LookupFrom(namespace, network, from, q.Name.String()); ok {
+				return s.answer(h, q, proto, s.addressResources(q, ips))
+			}
+		case dnsmessage.TypePTR:
+			if names := records.Reverse(namespace, q.Name.String()); len(names) > 0 {
+				return s.answer(h, q, proto, ptrResources(q, names))
+			}
+		}
+	}
+	if records.Internal(namespace, network) {
+		log.L.Debugf("not forwarding the query for %s from the internal network %s", q.Name, network)
+		return failure(h, q, dnsmessage.RCodeServerFailure)
+	}
+	resp, err := s.forward(query, proto)
+	if err != nil {
+		log.L.WithError(err).Debugf("failed to forward the query for %s", q.Name)
+		return failure(h, q, dnsmessage.RCodeServerFailure)
+	}
+	return resp
+}
+
+// addressResources returns the A or AAAA resources of the addresses, rotated.
+// A name without any address of the queried type gets an empty answer.
+func (s *Server) addressResources(q dnsmessage.Question, ips []net.IP) []dnsmessage.Resource {
+	var resources []dnsmessage.Resource
+	for _, ip := range ips {
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		if ip4 := ip.To4(); ip4 != nil {
+			if q.Type == dnsmessage.TypeA {
+				resources = append(resources, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AResource{A: [4]byte(ip4)}})
+			}
+		} else if q.Type == dnsmessage.TypeAAAA {
+			resources = append(resources, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())}})
+		}
+	}
+	if len(resources) > 1 {
+		n := int(s.rotation.Add(1)) % len(resources)
+		resources = append(resources[n:], resources[:n]...)
+	}
+	return resources
+}
+
+func ptrResources(q dnsmessage.Question, names []string) []dnsmessage.Resource {
+	var resources []dnsmessage.Resource
+	for _, name := range names {
+		ptr, err := dnsmessage.NewName(name + ".")
+		if err != nil {
+			continue
+		}
+		resources = append(resources, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.PTRResource{PTR: ptr},
+		})
+	}
+	return resources
+}
+
+// answer builds the response, truncating it over UDP when it does not fit in a datagram.
+func (s *Server) answer(h dnsmessage.Header, q dnsmessage.Question, proto string, resources []dnsmessage.Resource) []byte {
+	for n := len(resources); n >= 0; n-- {
+		hdr := dnsmessage.Header{
+			ID:                 h.ID,
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   h.RecursionDesired,
+			RecursionAvailable: true,
+			Truncated:          n < len(resources),
+		}
+		msg := dnsmessage.Message{Header: hdr, Questions: []dnsmessage.Question{q}, Answers: resources[:n]}
+		b, err := msg.Pack()
+		if err != nil {
+			log.L.WithError(err).Debugf("failed to pack the response for %s", q.Name)
+			return failure(h, q, dnsmessage.RCodeServerFailure)
+		}
+		if proto != "udp" || len(b) <= maxUDPSize {
+			return b
+		}
+	}
+	return nil
+}
+
+func failure(h dnsmessage.Header, q dnsmessage.Question, rcode dnsmessage.RCode) []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 h.ID,
+			Response:           true,
+			RecursionDesired:   h.RecursionDesired,
+			RecursionAvailable: true,
+			RCode:              rcode,
+		},
+		Questions: []dnsmessage.Question{q},
+	}
+	b, err := msg.Pack()
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// forward sends the query to the upstream nameservers, in order, and returns the first response.
+func (s *Server) forward(query []byte, proto string) ([]byte, error) {
+	if len(s.upstreams) == 0 {
+		return nil, errors.New("no upstream nameserver")
+	}
+	var errs []error
+	for _, upstream := range s.upstreams {
+		resp, err := exchange(proto, upstream, query)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func exchange(proto, addr string, query []byte) ([]byte, error) {
+	conn, err := net.DialTimeout(proto, addr, forwardTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(forwardTimeout)); err != nil {
+		return nil, err
+	}
+	if proto == "udp" {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+	if err := writeTCPMessage(conn, query); err != nil {
+		return nil, err
+	}
+	return readTCPMessage(conn)
+}
+
+// readTCPMessage reads a DNS message prefixed with its length, as sent over TCP.
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	b := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(b, uint16(len(msg)))
+	copy(b[2:], msg)
+	_, err := w.Write(b)
+	return err
+}
+
+// Listener serves the queries received on an address.
+type Listener struct {
+	network string
+	udp     net.PacketConn
+	tcp     net.Listener
+}
+
+// Listen starts serving the queries received on the address, for the containers of the network.
+func (s *Server) Listen(addr, network string) (*Listener, error) {
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+	go s.serveUDP(udp, network)
+	go s.serveTCP(tcp, network)
+	return &Listener{network: network, udp: udp, tcp: tcp}, nil
+}
+
+// Close stops serving.
+func (l *Listener) Close() error {
+	return errors.Join(l.udp.Close(), l.tcp.Close())
+}
+
+func (s *Server) serveUDP(conn net.PacketConn, network string) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.L.WithError(err).Warnf("failed to read a query on %s", conn.LocalAddr())
+			}
+			return
+		}
+		query := make([]byte, n)
+		copy(query, buf[:n])
+		go func() {
+			var from net.IP
+			if udpAddr, ok := addr.(*net.UDPAddr); ok {
+				from = udpAddr.IP
+			}
+			if resp := s.Resolve(query, "udp", from, network); resp != nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP(l net.Listener, network string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.L.WithError(err).Warnf("failed to accept a connection on %s", l.Addr())
+			}
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var from net.IP
+			if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+				from = tcpAddr.IP
+			}
+			for {
+				if err := conn.SetDeadline(time.Now().Add(tcpTimeout)); err != nil {
+					return
+				}
+				query, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				resp := s.Resolve(query, "tcp", from, network)
+				if resp == nil {
+					return
+				}
+				if err := writeTCPMessage(conn, resp); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
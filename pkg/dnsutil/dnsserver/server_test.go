@@ -0,0 +1,177 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"gotest.tools/v3/assert"
+)
+
+func query(t *testing.T, name string, typ dnsmessage.Type) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET},
+		},
+	}
+	b, err := msg.Pack()
+	assert.NilError(t, err)
+	return b
+}
+
+func parse(t *testing.T, b []byte) dnsmessage.Message {
+	t.Helper()
+	var msg dnsmessage.Message
+	assert.NilError(t, msg.Unpack(b))
+	return msg
+}
+
+// fakeUpstream answers all the queries with NXDOMAIN.
+func fakeUpstream(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			msg.Header.Response = true
+			msg.Header.RCode = dnsmessage.RCodeNameError
+			b, _ := msg.Pack()
+			_, _ = conn.WriteTo(b, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestResolve(t *testing.T) {
+	s := NewServer(nil)
+	s.SetRecords(testRecords())
+	from := net.ParseIP("10.4.1.2")
+
+	resp := parse(t, s.Resolve(query(t, "db.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.ID, uint16(42))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeSuccess)
+	assert.Equal(t, len(resp.Answers), 2)
+	first := resp.Answers[0].Body.(*dnsmessage.AResource).A
+
+	// Round-robin
+	resp = parse(t, s.Resolve(query(t, "db.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Assert(t, resp.Answers[0].Body.(*dnsmessage.AResource).A != first)
+
+	// Known name without IPv6 address
+	resp = parse(t, s.Resolve(query(t, "web.", dnsmessage.TypeAAAA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeSuccess)
+	assert.Equal(t, len(resp.Answers), 0)
+
+	resp = parse(t, s.Resolve(query(t, "2.1.4.10.in-addr.arpa.", dnsmessage.TypePTR), "udp", from, "front"))
+	assert.Equal(t, len(resp.Answers), 1)
+	assert.Equal(t, resp.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String(), "web.front.")
+
+	// No upstream
+	resp = parse(t, s.Resolve(query(t, "example.com.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeServerFailure)
+}
+
+func TestResolveForward(t *testing.T) {
+	s := NewServer([]string{fakeUpstream(t)})
+	s.SetRecords(testRecords())
+	from := net.ParseIP("10.4.1.2")
+
+	resp := parse(t, s.Resolve(query(t, "example.com.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.ID, uint16(42))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeNameError)
+
+	// The names of the containers are only answered on the networks of the querying container
+	resp = parse(t, s.Resolve(query(t, "web.", dnsmessage.TypeA), "udp", net.ParseIP("10.4.2.3"), "back"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeNameError)
+}
+
+func TestResolveSeveralNetworks(t *testing.T) {
+	s := NewServer([]string{fakeUpstream(t)})
+	s.SetRecords(testRecords())
+	// "api" is connected to "front" and "back", and its resolv.conf only lists the gateway of "front"
+	from := net.ParseIP("10.4.1.7")
+
+	resp := parse(t, s.Resolve(query(t, "worker.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeSuccess)
+	assert.Equal(t, len(resp.Answers), 1)
+	assert.Equal(t, resp.Answers[0].Body.(*dnsmessage.AResource).A, [4]byte{10, 4, 2, 3})
+
+	resp = parse(t, s.Resolve(query(t, "worker.back.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeSuccess)
+	assert.Equal(t, len(resp.Answers), 1)
+}
+
+func TestResolveUnknownSource(t *testing.T) {
+	s := NewServer([]string{fakeUpstream(t)})
+	s.SetRecords(testRecords())
+	from := net.ParseIP("192.168.1.10")
+
+	// Neither forwarded, nor answered
+	resp := parse(t, s.Resolve(query(t, "example.com.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.ID, uint16(42))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeRefused)
+	resp = parse(t, s.Resolve(query(t, "web.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeRefused)
+	assert.Equal(t, len(resp.Answers), 0)
+}
+
+func TestResolveInternal(t *testing.T) {
+	s := NewServer([]string{fakeUpstream(t)})
+	s.SetRecords(testRecords())
+	from := net.ParseIP("10.4.1.5")
+
+	resp := parse(t, s.Resolve(query(t, "web.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeSuccess)
+	assert.Equal(t, len(resp.Answers), 1)
+
+	// Not forwarded
+	resp = parse(t, s.Resolve(query(t, "example.com.", dnsmessage.TypeA), "udp", from, "front"))
+	assert.Equal(t, resp.Header.RCode, dnsmessage.RCodeServerFailure)
+}
+
+func TestResolveTruncated(t *testing.T) {
+	s := NewServer(nil)
+	var ips []net.IP
+	for i := range 100 {
+		ips = append(ips, net.IPv4(10, 4, 1, byte(i+2)))
+	}
+	q := dnsmessage.Question{Name: dnsmessage.MustNewName("many."), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}
+	h := dnsmessage.Header{ID: 1}
+
+	b := s.answer(h, q, "udp", s.addressResources(q, ips))
+	assert.Assert(t, len(b) <= maxUDPSize)
+	resp := parse(t, b)
+	assert.Assert(t, resp.Header.Truncated)
+
+	resp = parse(t, s.answer(h, q, "tcp", s.addressResources(q, ips)))
+	assert.Assert(t, !resp.Header.Truncated)
+	assert.Equal(t, len(resp.Answers), 100)
+}
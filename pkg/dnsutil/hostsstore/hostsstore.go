@@ -83,12 +83,18 @@ type Meta struct {
 	ExtraHosts map[string]string // host:ip
 	Name       string
 	Domainname string
+	Aliases    map[string][]string // network:aliases
+	Links      map[string]string   // alias:name
+	Interfaces map[string]string   // network:ifname
 }
 
 type Store interface {
 	Acquire(Meta) error
 	Release(id string) error
 	Update(id, newName string) error
+	UpdateNetworks(id string, networks map[string]*types100.Result, interfaces map[string]string) error
+	GetMeta(id string) (*Meta, error)
+	List() ([]*Meta, error)
 	HostsPath(id string) (location string, err error)
 	Delete(id string) (err error)
 	AllocHostsFile(id string, content []byte) (location string, err error)
@@ -258,6 +264,118 @@ func (x *hostsStore) Update(id, newName string) (err error) {
 	})
 }
 
+// UpdateNetworks replaces the networks and interfaces recorded for the container and regenerates all hosts files.
+// It is used by `nerdctl network connect` and `nerdctl network disconnect`.
+func (x *hostsStore) UpdateNetworks(id string, networks map[string]*types100.Result, interfaces map[string]string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	return x.safeStore.WithLock(func() error {
+		var content []byte
+		if content, err = x.safeStore.Get(id, metaJSON); err != nil {
+			return err
+		}
+
+		meta := &Meta{}
+		if err = json.Unmarshal(content, meta); err != nil {
+			return err
+		}
+
+		meta.Networks = networks
+		meta.Interfaces = interfaces
+		content, err = json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+
+		if err = x.safeStore.Set(content, id, metaJSON); err != nil {
+			return err
+		}
+
+		return x.updateAllHosts()
+	})
+}
+
+// GetMeta returns the metadata recorded for the container.
+func (x *hostsStore) GetMeta(id string) (meta *Meta, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	err = x.safeStore.WithLock(func() error {
+		var content []byte
+		if content, err = x.safeStore.Get(id, metaJSON); err != nil {
+			return err
+		}
+
+		meta = &Meta{}
+		return json.Unmarshal(content, meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// List returns the metadata of all the containers of the namespace that have networking set up.
+func (x *hostsStore) List() (metas []*Meta, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	err = x.safeStore.WithLock(func() error {
+		entries, err := x.safeStore.List()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			content, err := x.safeStore.Get(entry, metaJSON)
+			if err != nil {
+				// Released containers only retain their hosts file
+				continue
+			}
+			meta := &Meta{}
+			if err := json.Unmarshal(content, meta); err != nil {
+				log.L.WithError(err).Warnf("unable to unmarshal %q", entry)
+				continue
+			}
+			metas = append(metas, meta)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return metas, nil
+}
+
+// Namespaces returns the namespaces that have a hosts store in the data store.
+func Namespaces(dataStore string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dataStore, hostsDirBasename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Join(ErrHostsStore, err)
+	}
+	var namespaces []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+	return namespaces, nil
+}
+
 func (x *hostsStore) updateAllHosts() (err error) {
 	entries, err := x.safeStore.List()
 	if err != nil {
@@ -267,6 +385,7 @@ func (x *hostsStore) updateAllHosts() (err error) {
 	metasByEntry := map[string]*Meta{}
 	metasByIP := map[string]*Meta{}
 	networkNameByIP := map[string]string{}
+	ipsByName := map[string]map[string][]string{} // name:network:ips
 
 	// Phase 1: read all meta files
 	for _, entry := range entries {
@@ -292,6 +411,12 @@ func (x *hostsStore) updateAllHosts() (err error) {
 					ipStr := ip.String()
 					metasByIP[ipStr] = meta
 					networkNameByIP[ipStr] = netName
+					if meta.Name != "" {
+						if ipsByName[meta.Name] == nil {
+							ipsByName[meta.Name] = map[string][]string{}
+						}
+						ipsByName[meta.Name][netName] = append(ipsByName[meta.Name][netName], ipStr)
+					}
 				}
 			}
 		}
@@ -343,6 +468,18 @@ func (x *hostsStore) updateAllHosts() (err error) {
 			}
 		}
 
+		// links are only visible from the linking container, on the networks shared with the linked one
+		for alias, name := range myMeta.Links {
+			for netName, ips := range ipsByName[name] {
+				if _, ok := myNetworks[netName]; !ok {
+					continue
+				}
+				for _, ip := range ips {
+					buf.WriteString(fmt.Sprintf("%-15s %s\n", ip, alias))
+				}
+			}
+		}
+
 		buf.WriteString(fmt.Sprintf("# %s\n", MarkerEnd))
 
 		var loc string
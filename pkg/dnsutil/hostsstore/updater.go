@@ -27,6 +27,9 @@ import (
 // line is line "bar.example.com bar bar.nw0 foo foo.nw0\n"
 // for  `nerdctl --name=foo --hostname=bar --domainname=example.com --network=n0`.
 //
+// line is like "bar bar.nw0 foo foo.nw0 db\n"
+// for `nerdctl --name=foo --hostname=bar --network=nw0 --network-alias=db`.
+//
 // May return an empty string slice
 func createLine(thatNetwork string, meta *Meta, myNetworks map[string]struct{}) []string {
 	line := []string{}
@@ -52,5 +55,7 @@ func createLine(thatNetwork string, meta *Meta, myNetworks map[string]struct{})
 			line = append(line, baseHostname+"."+thatNetwork)
 		}
 	}
+	// network-scoped aliases
+	line = append(line, meta.Aliases[thatNetwork]...)
 	return line
 }
@@ -31,9 +31,10 @@ func TestCreateLine(t *testing.T) {
 	type testCase struct {
 		thatIP         string
 		thatNetwork    string
-		thatHostname   string // nerdctl run --hostname
-		thatDomainname string // nerdctl run --domainname
-		thatName       string // nerdctl run --name
+		thatHostname   string              // nerdctl run --hostname
+		thatDomainname string              // nerdctl run --domainname
+		thatName       string              // nerdctl run --name
+		thatAliases    map[string][]string // nerdctl run --network-alias
 		myNetwork      string
 		expected       string
 	}
@@ -114,6 +115,23 @@ func TestCreateLine(t *testing.T) {
 			myNetwork:      netutil.DefaultNetworkName,
 			expected:       "bar.example.com.example.com bar.example.com",
 		},
+		{
+			thatIP:       "10.4.2.10",
+			thatNetwork:  "n1",
+			thatHostname: "bar",
+			thatName:     "foo",
+			thatAliases:  map[string][]string{"n1": {"db", "cache"}, "n2": {"other"}},
+			myNetwork:    "n1",
+			expected:     "bar bar.n1 foo foo.n1 db cache",
+		},
+		{
+			thatIP:       "10.4.2.11",
+			thatNetwork:  "n1",
+			thatHostname: "bar",
+			thatAliases:  map[string][]string{"n1": {"db"}},
+			myNetwork:    "n2",
+			expected:     "",
+		},
 	}
 	for _, tc := range testCases {
 		thatMeta := &Meta{
@@ -135,6 +153,7 @@ func TestCreateLine(t *testing.T) {
 			Hostname:   tc.thatHostname,
 			Domainname: tc.thatDomainname,
 			Name:       tc.thatName,
+			Aliases:    tc.thatAliases,
 		}
 
 		myNetworks := map[string]struct{}{
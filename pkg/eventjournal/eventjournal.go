@@ -0,0 +1,198 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package eventjournal provides a persistent, bounded journal of the events, stored under the data root.
+// The events are appended by `nerdctl events` and `nerdctl system record-events` while they are running,
+// so that `nerdctl events --since/--until` can replay the events that happened in the past.
+package eventjournal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/lockutil"
+)
+
+const (
+	// dirName is the name of the journal directory, relative to the data store
+	dirName = "events"
+	// fileName is the name of the current journal file. The previous one is suffixed with ".1".
+	fileName = "events.jsonl"
+
+	// DefaultMaxSize is the size of the current journal file above which it is rotated.
+	// As only the previous file is kept, the journal never takes more than twice this size.
+	DefaultMaxSize = 4 << 20
+)
+
+// ScopeLocal is the scope of all the events, compatible with Docker.
+const ScopeLocal = "local"
+
+// Actor describes the object that emitted an event, compatible with Docker.
+type Actor struct {
+	ID         string
+	Attributes map[string]string
+}
+
+// Message is an event, in the format of `docker events --format json`.
+type Message struct {
+	// Status, ID and From are deprecated in Docker, but still emitted for the container and image events.
+	Status string `json:"status,omitempty"`
+	ID     string `json:"id,omitempty"`
+	From   string `json:"from,omitempty"`
+
+	Type   string
+	Action string
+	Actor  Actor
+	Scope  string `json:"scope"`
+
+	Time     int64 `json:"time"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// Entry is an event recorded in the journal.
+// Next to the Docker-compatible message, it keeps the containerd namespace, topic and event payload.
+type Entry struct {
+	Message
+	Namespace string `json:"namespace,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+	Event     string `json:"event,omitempty"`
+}
+
+// Timestamp returns the time of the event.
+func (e *Entry) Timestamp() time.Time {
+	return time.Unix(0, e.TimeNano).UTC()
+}
+
+// Key identifies an event. The same event recorded by several processes has the same key.
+func (e *Entry) Key() string {
+	return fmt.Sprintf("%d/%s/%s/%s/%s/%s", e.TimeNano, e.Namespace, e.Topic, e.Type, e.Action, e.Actor.ID)
+}
+
+// Journal is the event journal of a data store.
+type Journal struct {
+	dir     string
+	maxSize int64
+}
+
+// New returns the event journal of the data store, creating its directory if needed.
+func New(dataStore string) (*Journal, error) {
+	dir := filepath.Join(dataStore, dirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Journal{dir: dir, maxSize: DefaultMaxSize}, nil
+}
+
+// Append appends the entries to the journal, rotating the current file when it is too large.
+func (j *Journal) Append(entries ...*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return lockutil.WithDirLock(j.dir, func() error {
+		current := filepath.Join(j.dir, fileName)
+		if st, err := os.Stat(current); err == nil && st.Size() >= j.maxSize {
+			if err := os.Rename(current, current+".1"); err != nil {
+				return err
+			}
+		}
+		f, err := os.OpenFile(current, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(buf.Bytes()); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// Read returns the entries recorded between since and until (inclusive), sorted by time.
+// A zero since or until leaves the corresponding bound open.
+// The entries recorded several times are returned once, and the lines that cannot be parsed are skipped.
+func (j *Journal) Read(since, until time.Time) ([]*Entry, error) {
+	var entries []*Entry
+	err := lockutil.WithDirLock(j.dir, func() error {
+		current := filepath.Join(j.dir, fileName)
+		for _, p := range []string{current + ".1", current} {
+			read, err := readFile(p)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, read...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	filtered := entries[:0]
+	for _, e := range entries {
+		t := e.Timestamp()
+		if (!since.IsZero() && t.Before(since)) || (!until.IsZero() && t.After(until)) {
+			continue
+		}
+		key := e.Key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		filtered = append(filtered, e)
+	}
+	sort.SliceStable(filtered, func(a, b int) bool {
+		return filtered[a].TimeNano < filtered[b].TimeNano
+	})
+	return filtered, nil
+}
+
+func readFile(p string) ([]*Entry, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		// A line may have been truncated by a crash, just skip it
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, &e)
+	}
+	return entries, scanner.Err()
+}
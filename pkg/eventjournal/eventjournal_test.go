@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package eventjournal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func entry(t time.Time, action, id string) *Entry {
+	return &Entry{
+		Message: Message{
+			Type:     "container",
+			Action:   action,
+			Actor:    Actor{ID: id},
+			Scope:    ScopeLocal,
+			Time:     t.Unix(),
+			TimeNano: t.UnixNano(),
+		},
+		Namespace: "default",
+		Topic:     "/tasks/" + action,
+	}
+}
+
+func TestJournal(t *testing.T) {
+	j, err := New(t.TempDir())
+	assert.NilError(t, err)
+
+	base := time.Unix(1700000000, 0)
+	assert.NilError(t, j.Append(entry(base.Add(2*time.Second), "die", "foo"), entry(base, "start", "foo")))
+	// The same event recorded by another process
+	assert.NilError(t, j.Append(entry(base, "start", "foo"), entry(base.Add(time.Second), "start", "bar")))
+
+	entries, err := j.Read(time.Time{}, time.Time{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Action, "start")
+	assert.Equal(t, entries[0].Actor.ID, "foo")
+	assert.Equal(t, entries[1].Actor.ID, "bar")
+	assert.Equal(t, entries[2].Action, "die")
+
+	entries, err = j.Read(base.Add(time.Second), base.Add(time.Second))
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Actor.ID, "bar")
+}
+
+func TestJournalRotation(t *testing.T) {
+	j, err := New(t.TempDir())
+	assert.NilError(t, err)
+	j.maxSize = 1
+
+	base := time.Unix(1700000000, 0)
+	for i := range 3 {
+		assert.NilError(t, j.Append(entry(base.Add(time.Duration(i)*time.Second), "start", "foo")))
+	}
+	// Only the current and the previous files are kept
+	entries, err := j.Read(time.Time{}, time.Time{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].TimeNano, base.Add(time.Second).UnixNano())
+}
+
+func TestJournalCorruptedLine(t *testing.T) {
+	j, err := New(t.TempDir())
+	assert.NilError(t, err)
+
+	base := time.Unix(1700000000, 0)
+	assert.NilError(t, j.Append(entry(base, "start", "foo")))
+	f, err := os.OpenFile(filepath.Join(j.dir, fileName), os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NilError(t, err)
+	_, err = f.WriteString("{\"Type\":\"cont")
+	assert.NilError(t, err)
+	assert.NilError(t, f.Close())
+
+	entries, err := j.Read(time.Time{}, time.Time{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+}
@@ -22,6 +22,22 @@ import (
 	"github.com/containerd/containerd/v2/core/events"
 )
 
+// Topics of the containerd events published by nerdctl, in addition to the ones of the health checks
+// and of the restart supervisor. The events are ContainerUpdate events carrying their details in labels.
+const (
+	// KillEventTopic is published when a signal is sent to a container with `nerdctl kill`.
+	// The signal is carried in the SignalEventLabel label.
+	KillEventTopic = "/nerdctl/container/kill"
+	// NetworkConnectEventTopic is published when a running container is connected to a network with `nerdctl network connect`.
+	// The network name is carried in the NetworkEventLabel label.
+	NetworkConnectEventTopic = "/nerdctl/network/connect"
+	// NetworkDisconnectEventTopic is published when a running container is disconnected from a network.
+	NetworkDisconnectEventTopic = "/nerdctl/network/disconnect"
+
+	SignalEventLabel  = "signal"
+	NetworkEventLabel = "network"
+)
+
 type eventHandler struct {
 	handlers map[string]func(events.Envelope)
 	mu       sync.Mutex
@@ -35,7 +35,9 @@ import (
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
 )
 
 func ContainerStatus(ctx context.Context, c containerd.Container) string {
@@ -66,14 +68,29 @@ func ContainerStatus(ctx context.Context, c containerd.Container) string {
 		if labels[restart.StatusLabel] == string(containerd.Running) && restart.Reconcile(status, labels) {
 			return fmt.Sprintf("Restarting (%v) %s", status.ExitStatus, TimeSinceInHuman(status.ExitTime))
 		}
+		if rs, err := restartsupervisor.ContainerState(labels); err == nil && rs != nil && rs.Restarting {
+			return fmt.Sprintf("Restarting (%v) %s", status.ExitStatus, TimeSinceInHuman(status.ExitTime))
+		}
 		return fmt.Sprintf("Exited (%v) %s", status.ExitStatus, TimeSinceInHuman(status.ExitTime))
 	case containerd.Running:
-		return "Up" // TODO: print "status.UpTime" (inexistent yet)
+		return "Up" + healthStatusSuffix(labels) // TODO: print "status.UpTime" (inexistent yet)
 	default:
 		return titleCaser.String(string(s))
 	}
 }
 
+// healthStatusSuffix returns the health status to append to the status of a running container, like Docker does.
+func healthStatusSuffix(containerLabels map[string]string) string {
+	health, err := healthcheck.ContainerHealth(containerLabels)
+	if err != nil || health == nil {
+		return ""
+	}
+	if health.Status == healthcheck.Starting {
+		return " (health: starting)"
+	}
+	return fmt.Sprintf(" (%s)", health.Status)
+}
+
 func InspectContainerCommand(spec *oci.Spec, trunc, quote bool) string {
 	if spec == nil || spec.Process == nil {
 		return ""
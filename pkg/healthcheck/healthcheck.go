@@ -0,0 +1,252 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package healthcheck provides the types and the persistence for container health checks.
+// The health check configuration is stored in the "nerdctl/healthcheck" container label, while the results
+// are stored in the container state directory, next to the lifecycle state.
+// Health checks are executed by `nerdctl container healthcheck`, which is typically driven by a transient
+// systemd timer created by the OCI hook when the container starts.
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Health statuses, compatible with Docker.
+const (
+	NoHealthcheck = "none"
+	Starting      = "starting"
+	Healthy       = "healthy"
+	Unhealthy     = "unhealthy"
+)
+
+// EventTopic is the topic of the containerd event published when the health status of a container changes.
+// The event is a ContainerUpdate carrying the new status in the "health_status" label.
+const EventTopic = "/nerdctl/container/health_status"
+
+// Test kinds, compatible with Docker.
+const (
+	TestNone     = "NONE"
+	TestCmd      = "CMD"
+	TestCmdShell = "CMD-SHELL"
+)
+
+// Defaults, compatible with Docker.
+const (
+	DefaultInterval      = 30 * time.Second
+	DefaultTimeout       = 30 * time.Second
+	DefaultStartInterval = 5 * time.Second
+	DefaultRetries       = 3
+
+	// MaxLogEntries is the number of results kept in the health log
+	MaxLogEntries = 5
+	// MaxOutputLen is the maximum size of the output of a single check kept in the health log
+	MaxOutputLen = 4096
+)
+
+// Healthcheck is the configuration of a container health check.
+// The JSON representation is compatible with the `Healthcheck` field of Docker image configs
+// (durations are expressed in nanoseconds).
+type Healthcheck struct {
+	// Test is the test to perform, e.g. {"CMD-SHELL", "curl -f http://localhost/"}.
+	// An empty slice means inheriting the image config, {"NONE"} disables the health check.
+	Test []string `json:",omitempty"`
+
+	Interval      time.Duration `json:",omitempty"`
+	Timeout       time.Duration `json:",omitempty"`
+	StartPeriod   time.Duration `json:",omitempty"`
+	StartInterval time.Duration `json:",omitempty"`
+	Retries       int           `json:",omitempty"`
+}
+
+// Disabled returns true if the health check is explicitly disabled or has nothing to run.
+func (hc *Healthcheck) Disabled() bool {
+	return hc == nil || len(hc.Test) == 0 || hc.Test[0] == TestNone
+}
+
+// Validate checks the health check configuration.
+func (hc *Healthcheck) Validate() error {
+	if hc == nil {
+		return nil
+	}
+	if len(hc.Test) > 0 {
+		switch hc.Test[0] {
+		case TestNone:
+		case TestCmd, TestCmdShell:
+			if len(hc.Test) < 2 {
+				return fmt.Errorf("invalid healthcheck test %v: missing command", hc.Test)
+			}
+		default:
+			return fmt.Errorf("invalid healthcheck test %v: must start with %q, %q or %q", hc.Test, TestNone, TestCmd, TestCmdShell)
+		}
+	}
+	for name, d := range map[string]time.Duration{
+		"interval":       hc.Interval,
+		"timeout":        hc.Timeout,
+		"start-period":   hc.StartPeriod,
+		"start-interval": hc.StartInterval,
+	} {
+		if d < 0 {
+			return fmt.Errorf("invalid healthcheck %s %s: must not be negative", name, d)
+		}
+		if d != 0 && d < time.Millisecond {
+			return fmt.Errorf("invalid healthcheck %s %s: must be at least 1ms", name, d)
+		}
+	}
+	if hc.Retries < 0 {
+		return fmt.Errorf("invalid healthcheck retries %d: must not be negative", hc.Retries)
+	}
+	return nil
+}
+
+// Merge returns the health check configuration resulting from overriding base (typically from the image config)
+// with the non-zero fields of hc (typically from the command line).
+func (hc *Healthcheck) Merge(base *Healthcheck) *Healthcheck {
+	if hc == nil {
+		return base
+	}
+	if base == nil {
+		return hc
+	}
+	merged := *base
+	if len(hc.Test) > 0 {
+		merged.Test = hc.Test
+	}
+	if hc.Interval != 0 {
+		merged.Interval = hc.Interval
+	}
+	if hc.Timeout != 0 {
+		merged.Timeout = hc.Timeout
+	}
+	if hc.StartPeriod != 0 {
+		merged.StartPeriod = hc.StartPeriod
+	}
+	if hc.StartInterval != 0 {
+		merged.StartInterval = hc.StartInterval
+	}
+	if hc.Retries != 0 {
+		merged.Retries = hc.Retries
+	}
+	return &merged
+}
+
+// GetInterval returns the interval between checks, or the default.
+func (hc *Healthcheck) GetInterval() time.Duration {
+	if hc.Interval == 0 {
+		return DefaultInterval
+	}
+	return hc.Interval
+}
+
+// GetTimeout returns the timeout of a single check, or the default.
+func (hc *Healthcheck) GetTimeout() time.Duration {
+	if hc.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return hc.Timeout
+}
+
+// GetStartInterval returns the interval between checks during the start period, or the default.
+func (hc *Healthcheck) GetStartInterval() time.Duration {
+	if hc.StartInterval == 0 {
+		return DefaultStartInterval
+	}
+	return hc.StartInterval
+}
+
+// GetRetries returns the number of consecutive failures needed to report unhealthy, or the default.
+func (hc *Healthcheck) GetRetries() int {
+	if hc.Retries == 0 {
+		return DefaultRetries
+	}
+	return hc.Retries
+}
+
+// Args returns the process arguments to execute for the test.
+func (hc *Healthcheck) Args() ([]string, error) {
+	if hc.Disabled() {
+		return nil, errors.New("healthcheck is disabled")
+	}
+	switch hc.Test[0] {
+	case TestCmd:
+		return hc.Test[1:], nil
+	case TestCmdShell:
+		return []string{"/bin/sh", "-c", strings.Join(hc.Test[1:], " ")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported healthcheck test %v", hc.Test)
+	}
+}
+
+// ParseLabel parses the value of the "nerdctl/healthcheck" label.
+func ParseLabel(s string) (*Healthcheck, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var hc Healthcheck
+	if err := json.Unmarshal([]byte(s), &hc); err != nil {
+		return nil, err
+	}
+	return &hc, nil
+}
+
+// Health is the health state of a container.
+type Health struct {
+	Status        string
+	FailingStreak int
+	Log           []*Result
+}
+
+// Result is the result of a single health check.
+type Result struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	Output   string
+}
+
+// Apply records the result of a check and updates the status accordingly.
+// startedAt is the time at which the container was started, which is used to evaluate the start period.
+func (h *Health) Apply(hc *Healthcheck, res *Result, startedAt time.Time) {
+	if len(res.Output) > MaxOutputLen {
+		res.Output = res.Output[:MaxOutputLen]
+	}
+	h.Log = append(h.Log, res)
+	if len(h.Log) > MaxLogEntries {
+		h.Log = h.Log[len(h.Log)-MaxLogEntries:]
+	}
+	if h.Status == "" {
+		h.Status = Starting
+	}
+
+	if res.ExitCode == 0 {
+		h.Status = Healthy
+		h.FailingStreak = 0
+		return
+	}
+
+	// Failures during the start period are not counted, unless the container already reported healthy.
+	if h.Status == Starting && res.Start.Sub(startedAt) < hc.StartPeriod {
+		return
+	}
+	h.FailingStreak++
+	if h.FailingStreak >= hc.GetRetries() {
+		h.Status = Unhealthy
+	}
+}
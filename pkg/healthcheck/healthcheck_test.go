@@ -0,0 +1,121 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMerge(t *testing.T) {
+	image := &Healthcheck{
+		Test:     []string{TestCmdShell, "true"},
+		Interval: 10 * time.Second,
+		Retries:  5,
+	}
+	flags := &Healthcheck{
+		Interval: 2 * time.Second,
+	}
+	merged := flags.Merge(image)
+	assert.DeepEqual(t, merged.Test, []string{TestCmdShell, "true"})
+	assert.Equal(t, merged.Interval, 2*time.Second)
+	assert.Equal(t, merged.Retries, 5)
+
+	var none *Healthcheck
+	assert.Equal(t, none.Merge(image), image)
+	assert.Equal(t, flags.Merge(nil), flags)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NilError(t, (&Healthcheck{Test: []string{TestCmd, "true"}}).Validate())
+	assert.NilError(t, (&Healthcheck{Test: []string{TestNone}}).Validate())
+	assert.ErrorContains(t, (&Healthcheck{Test: []string{TestCmd}}).Validate(), "missing command")
+	assert.ErrorContains(t, (&Healthcheck{Test: []string{"FOO", "bar"}}).Validate(), "must start with")
+	assert.ErrorContains(t, (&Healthcheck{Interval: -time.Second}).Validate(), "must not be negative")
+	assert.ErrorContains(t, (&Healthcheck{Retries: -1}).Validate(), "must not be negative")
+}
+
+func TestArgs(t *testing.T) {
+	args, err := (&Healthcheck{Test: []string{TestCmd, "curl", "-f", "http://localhost"}}).Args()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"curl", "-f", "http://localhost"})
+
+	args, err = (&Healthcheck{Test: []string{TestCmdShell, "curl -f http://localhost || exit 1"}}).Args()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"/bin/sh", "-c", "curl -f http://localhost || exit 1"})
+
+	_, err = (&Healthcheck{Test: []string{TestNone}}).Args()
+	assert.ErrorContains(t, err, "disabled")
+}
+
+func TestApply(t *testing.T) {
+	hc := &Healthcheck{
+		Test:        []string{TestCmd, "true"},
+		StartPeriod: 10 * time.Second,
+		Retries:     2,
+	}
+	startedAt := time.Now()
+	health := &Health{}
+
+	// failures during the start period are not counted
+	health.Apply(hc, &Result{Start: startedAt.Add(time.Second), ExitCode: 1}, startedAt)
+	assert.Equal(t, health.Status, Starting)
+	assert.Equal(t, health.FailingStreak, 0)
+
+	health.Apply(hc, &Result{Start: startedAt.Add(2 * time.Second), ExitCode: 0}, startedAt)
+	assert.Equal(t, health.Status, Healthy)
+
+	// once healthy, failures are counted even during the start period
+	health.Apply(hc, &Result{Start: startedAt.Add(3 * time.Second), ExitCode: 1}, startedAt)
+	assert.Equal(t, health.Status, Healthy)
+	assert.Equal(t, health.FailingStreak, 1)
+	health.Apply(hc, &Result{Start: startedAt.Add(4 * time.Second), ExitCode: 1}, startedAt)
+	assert.Equal(t, health.Status, Unhealthy)
+	assert.Equal(t, health.FailingStreak, 2)
+
+	for i := 0; i < MaxLogEntries*2; i++ {
+		health.Apply(hc, &Result{Start: startedAt.Add(time.Minute), ExitCode: 0}, startedAt)
+	}
+	assert.Equal(t, len(health.Log), MaxLogEntries)
+	assert.Equal(t, health.Status, Healthy)
+	assert.Equal(t, health.FailingStreak, 0)
+}
+
+func TestStore(t *testing.T) {
+	stateDir := t.TempDir()
+
+	health, err := ReadHealth(stateDir)
+	assert.NilError(t, err)
+	assert.Equal(t, health.Status, Starting)
+
+	err = UpdateHealth(stateDir, func(h *Health) error {
+		h.Status = Healthy
+		return nil
+	})
+	assert.NilError(t, err)
+	health, err = ReadHealth(stateDir)
+	assert.NilError(t, err)
+	assert.Equal(t, health.Status, Healthy)
+
+	assert.NilError(t, ResetHealth(stateDir))
+	assert.NilError(t, ResetHealth(stateDir))
+	health, err = ReadHealth(stateDir)
+	assert.NilError(t, err)
+	assert.Equal(t, health.Status, Starting)
+}
@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// healthFile is the name of the file carrying the health state, relative to the container state dir
+const healthFile = "health.json"
+
+// ErrHealthStore will wrap all errors here
+var ErrHealthStore = errors.New("health-store error")
+
+// ReadHealth returns the health state stored in the container state dir.
+// A container that did not record any result yet is reported as starting.
+func ReadHealth(stateDir string) (health *Health, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHealthStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	health = &Health{Status: Starting}
+	err = st.WithLock(func() error {
+		return load(st, health)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// ContainerHealth returns the health state of the container which labels are passed as argument.
+// nil is returned if the container has no health check.
+func ContainerHealth(containerLabels map[string]string) (*Health, error) {
+	hc, err := ParseLabel(containerLabels[labels.HealthCheck])
+	if err != nil {
+		return nil, err
+	}
+	if hc.Disabled() || containerLabels[labels.StateDir] == "" {
+		return nil, nil
+	}
+	return ReadHealth(containerLabels[labels.StateDir])
+}
+
+// UpdateHealth atomically applies fun to the health state stored in the container state dir.
+func UpdateHealth(stateDir string, fun func(*Health) error) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHealthStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return err
+	}
+	return st.WithLock(func() error {
+		health := &Health{Status: Starting}
+		if err := load(st, health); err != nil {
+			return err
+		}
+		if err := fun(health); err != nil {
+			return err
+		}
+		data, err := json.Marshal(health)
+		if err != nil {
+			return err
+		}
+		return st.Set(data, healthFile)
+	})
+}
+
+// ResetHealth removes the health state from the container state dir.
+// It is called every time the container is (re)started.
+func ResetHealth(stateDir string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHealthStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return err
+	}
+	return st.WithLock(func() error {
+		if err := st.Delete(healthFile); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		return nil
+	})
+}
+
+func load(st store.Store, health *Health) error {
+	data, err := st.Get(healthFile)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, health)
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// TimerUnitName returns the name of the transient systemd unit running the health checks of a container.
+func TimerUnitName(namespace, id string) string {
+	return fmt.Sprintf("nerdctl-healthcheck-%s-%s", namespace, id)
+}
+
+// TimersSupported returns true if transient systemd timers can be created.
+func TimersSupported() bool {
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return false
+	}
+	_, err := exec.LookPath("systemd-run")
+	return err == nil
+}
+
+// CreateTimer starts a transient systemd timer that periodically runs
+// `nerdctl [global flags] container healthcheck <id>`.
+// The first check runs after the start interval if a start period is set, otherwise after the interval.
+// The next checks run every interval, even during the start period, as a transient timer cannot change its interval.
+func CreateTimer(ctx context.Context, hc *Healthcheck, namespace, id, nerdctlCmd string, nerdctlArgs []string) error {
+	if hc.Disabled() {
+		return nil
+	}
+	if !TimersSupported() {
+		return errors.New("systemd is not available, health checks have to be run manually with `nerdctl container healthcheck`")
+	}
+	// Remove any stale unit left over by a previous run of the container
+	_ = RemoveTimer(ctx, namespace, id)
+
+	first := hc.GetInterval()
+	if hc.StartPeriod > 0 {
+		first = hc.GetStartInterval()
+	}
+	args := systemctlUserArgs()
+	args = append(args,
+		"--unit", TimerUnitName(namespace, id),
+		"--description", fmt.Sprintf("nerdctl healthcheck for container %s", id),
+		"--on-active", formatSeconds(first),
+		"--on-unit-inactive", formatSeconds(hc.GetInterval()),
+		"--timer-property=AccuracySec=1s",
+		"--collect",
+		"--quiet",
+		"--",
+		nerdctlCmd,
+	)
+	args = append(args, nerdctlArgs...)
+	args = append(args, "--namespace="+namespace, "container", "healthcheck", id)
+	log.G(ctx).Debugf("creating healthcheck timer: systemd-run %s", strings.Join(args, " "))
+	if out, err := exec.CommandContext(ctx, "systemd-run", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create the healthcheck timer (%q): %w", string(out), err)
+	}
+	return nil
+}
+
+// RemoveTimer stops the transient systemd timer of a container, if any.
+func RemoveTimer(ctx context.Context, namespace, id string) error {
+	if !TimersSupported() {
+		return nil
+	}
+	unit := TimerUnitName(namespace, id)
+	args := append(systemctlUserArgs(), "stop", unit+".timer", unit+".service")
+	if out, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stop the healthcheck timer (%q): %w", string(out), err)
+	}
+	return nil
+}
+
+func systemctlUserArgs() []string {
+	if rootlessutil.IsRootless() {
+		return []string{"--user"}
+	}
+	return nil
+}
+
+func formatSeconds(d time.Duration) string {
+	// systemd accepts fractional seconds down to the microsecond
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
@@ -0,0 +1,42 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+)
+
+// TimersSupported returns true if transient systemd timers can be created.
+func TimersSupported() bool {
+	return false
+}
+
+// CreateTimer is not supported on this platform: health checks have to be run with `nerdctl container healthcheck`.
+func CreateTimer(ctx context.Context, hc *Healthcheck, namespace, id, nerdctlCmd string, nerdctlArgs []string) error {
+	if hc.Disabled() {
+		return nil
+	}
+	return errors.New("healthcheck timers are not supported on this platform, health checks have to be run manually with `nerdctl container healthcheck`")
+}
+
+// RemoveTimer is a no-op on this platform.
+func RemoveTimer(ctx context.Context, namespace, id string) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/log"
+)
+
+// Changes are the image config changes applied by `nerdctl commit --change` and `nerdctl import --change`.
+type Changes struct {
+	CMD, Entrypoint []string
+}
+
+// ParseChanges parses Dockerfile instructions (supported directives: [CMD, ENTRYPOINT]).
+func ParseChanges(userChanges []string) (Changes, error) {
+	const (
+		// XXX: Where can I get a constants for this?
+		commandDirective    = "CMD"
+		entrypointDirective = "ENTRYPOINT"
+	)
+	if userChanges == nil {
+		return Changes{}, nil
+	}
+	var changes Changes
+	for _, change := range userChanges {
+		if change == "" {
+			return Changes{}, fmt.Errorf("received an empty value in change flag")
+		}
+		changeFields := strings.Fields(change)
+
+		switch changeFields[0] {
+		case commandDirective:
+			var overrideCMD []string
+			if err := json.Unmarshal([]byte(change[len(changeFields[0]):]), &overrideCMD); err != nil {
+				return Changes{}, fmt.Errorf("malformed json in change flag value %q", change)
+			}
+			if changes.CMD != nil {
+				log.L.Warn("multiple change flags supplied for the CMD directive, overriding with last supplied")
+			}
+			changes.CMD = overrideCMD
+		case entrypointDirective:
+			var overrideEntrypoint []string
+			if err := json.Unmarshal([]byte(change[len(changeFields[0]):]), &overrideEntrypoint); err != nil {
+				return Changes{}, fmt.Errorf("malformed json in change flag value %q", change)
+			}
+			if changes.Entrypoint != nil {
+				log.L.Warnf("multiple change flags supplied for the Entrypoint directive, overriding with last supplied")
+			}
+			changes.Entrypoint = overrideEntrypoint
+		default: // TODO: Support the rest of the change directives
+			return Changes{}, fmt.Errorf("unknown change directive %q", changeFields[0])
+		}
+	}
+	return changes, nil
+}
@@ -52,16 +52,12 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
-type Changes struct {
-	CMD, Entrypoint []string
-}
-
 type Opts struct {
 	Author  string
 	Message string
 	Ref     string
 	Pause   bool
-	Changes Changes
+	Changes imgutil.Changes
 }
 
 var (
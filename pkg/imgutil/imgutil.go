@@ -40,6 +40,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/errutil"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/pull"
@@ -357,6 +358,28 @@ func ReadImageConfig(ctx context.Context, img containerd.Image) (ocispec.Image,
 	return config, configDesc, nil
 }
 
+// ReadImageHealthcheck reads the Docker-specific `Healthcheck` field of the config of img.platform.
+// It returns nil if the image does not define a health check.
+func ReadImageHealthcheck(ctx context.Context, img containerd.Image) (*healthcheck.Healthcheck, error) {
+	configDesc, err := img.Config(ctx) // aware of img.platform
+	if err != nil {
+		return nil, err
+	}
+	p, err := content.ReadBlob(ctx, img.ContentStore(), configDesc)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Config struct {
+			Healthcheck *healthcheck.Healthcheck `json:"Healthcheck,omitempty"`
+		} `json:"config,omitempty"`
+	}
+	if err := json.Unmarshal(p, &config); err != nil {
+		return nil, err
+	}
+	return config.Config.Healthcheck, nil
+}
+
 // ParseRepoTag parses raw `imgName` to repository and tag.
 func ParseRepoTag(imgName string) (string, string) {
 	log.L.Debugf("raw image name=%q", imgName)
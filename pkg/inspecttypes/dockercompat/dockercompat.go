@@ -44,11 +44,13 @@ import (
 	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+	"github.com/containerd/nerdctl/v2/pkg/restartsupervisor"
 )
 
 // From https://github.com/moby/moby/blob/v26.1.2/api/types/types.go#L34-L140
@@ -156,6 +158,7 @@ type HostConfig struct {
 	DNSSearch    []string `json:"DnsSearch"`  // List of DNSSearch to look for
 	ExtraHosts   []string // List of extra hosts
 	GroupAdd     []string // GroupAdd specifies additional groups to join
+	Links        []string // List of links (in the "/<target>:/<name>/<alias>" form)
 	IpcMode      string   `json:"IpcMode"` // IPC namespace to use for the container
 	// Cgroup          CgroupSpec        // Cgroup to use for the container
 	OomScoreAdj int    // specifies the tune container’s OOM preferences (-1000 to 1000, rootless: 100 to 1000)
@@ -179,8 +182,11 @@ type HostConfig struct {
 	CPURealtimeRuntime int64             `json:"CpuRealtimeRuntime"` // Limits the CPU real-time runtime in microseconds
 	Memory             int64             // Memory limit (in bytes)
 	MemorySwap         int64             // Total memory usage (memory + swap); set `-1` to enable unlimited swap
+	MemorySwappiness   *int64            // Tuning container memory swappiness behaviour
 	OomKillDisable     bool              // specifies whether to disable OOM Killer
 	Devices            []DeviceMapping   // List of devices to map inside the container
+	DeviceCgroupRules  []string          // List of rule to be added to the device cgroup
+	Ulimits            []*units.Ulimit   // List of ulimits to be set in the container
 	LinuxBlkioSettings
 }
 
@@ -210,9 +216,9 @@ type Config struct {
 	// TODO: Tty          bool        // Attach standard streams to a tty, including stdin if it is not closed.
 	// TODO: OpenStdin    bool        // Open stdin
 	// TODO: StdinOnce    bool        // If true, close stdin after the 1 attached client disconnects.
-	Env []string `json:",omitempty"` // List of environment variable to set in the container
-	Cmd []string `json:",omitempty"` // Command to run when starting the container
-	// TODO Healthcheck     *HealthConfig       `json:",omitempty"` // Healthcheck describes how to check the container is healthy
+	Env         []string                 `json:",omitempty"` // List of environment variable to set in the container
+	Cmd         []string                 `json:",omitempty"` // Command to run when starting the container
+	Healthcheck *healthcheck.Healthcheck `json:",omitempty"` // Healthcheck describes how to check the container is healthy
 	// TODO: ArgsEscaped     bool                `json:",omitempty"` // True if command is already escaped (meaning treat as a command line) (Windows specific).
 	// TODO: Image           string              // Name of the image as it was passed by the operator (e.g. could be symbolic)
 	Volumes    map[string]struct{} `json:",omitempty"` // List of volumes (mounts) used for the container
@@ -240,7 +246,7 @@ type ContainerState struct {
 	Error      string
 	StartedAt  string
 	FinishedAt string
-	// TODO: Health     *Health `json:",omitempty"`
+	Health     *healthcheck.Health `json:",omitempty"`
 }
 
 type NetworkSettings struct {
@@ -256,9 +262,12 @@ type DNSSettings struct {
 }
 
 type HostConfigLabel struct {
-	BlkioWeight uint16
-	CidFile     string
-	Devices     []DeviceMapping
+	BlkioWeight       uint16
+	CidFile           string
+	Devices           []DeviceMapping
+	DeviceCgroupRules []string `json:",omitempty"`
+	// Ulimits are the ulimits set by the user, unlike the rlimits of the spec which include the defaults of the runtime
+	Ulimits []*units.Ulimit `json:",omitempty"`
 }
 
 type DeviceMapping struct {
@@ -333,6 +342,13 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	if n.Labels[restart.StatusLabel] == string(containerd.Running) {
 		c.RestartCount, _ = strconv.Atoi(n.Labels[restart.CountLabel])
 	}
+	restartState, err := restartsupervisor.ContainerState(n.Labels)
+	if err != nil {
+		log.L.WithError(err).Warnf("failed to read the restart state of container %s", n.ID)
+	}
+	if restartState != nil {
+		c.RestartCount = restartState.RestartCount
+	}
 	containerAnnotations := make(map[string]string)
 	if sp, ok := n.Spec.(*specs.Spec); ok {
 		containerAnnotations = sp.Annotations
@@ -394,6 +410,10 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 		c.HostConfig.ExtraHosts = parseExtraHosts(nedctlExtraHosts)
 	}
 
+	if nerdctlLinks := n.Labels[labels.Links]; nerdctlLinks != "" {
+		c.HostConfig.Links = parseLinks(nerdctlLinks, n.Labels[labels.Name])
+	}
+
 	if nerdctlLoguri := n.Labels[labels.LogURI]; nerdctlLoguri != "" {
 		c.HostConfig.LogConfig.LogURI = nerdctlLoguri
 	}
@@ -421,6 +441,8 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 
 	c.HostConfig.BlkioWeight = hostConfigLabel.BlkioWeight
 	c.HostConfig.ContainerIDFile = hostConfigLabel.CidFile
+	c.HostConfig.DeviceCgroupRules = hostConfigLabel.DeviceCgroupRules
+	c.HostConfig.Ulimits = hostConfigLabel.Ulimits
 
 	groupAdd, err := groupAddFromNative(n.Spec.(*specs.Spec))
 	if err != nil {
@@ -446,10 +468,10 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	}
 
 	cs := new(ContainerState)
-	cs.Restarting = n.Labels[restart.StatusLabel] == string(containerd.Running)
+	cs.Restarting = n.Labels[restart.StatusLabel] == string(containerd.Running) || (restartState != nil && restartState.Restarting)
 	cs.Error = n.Labels[labels.Error]
 	if n.Process != nil {
-		cs.Status = statusFromNative(n.Process.Status, n.Labels)
+		cs.Status = statusFromNative(n.Process.Status, n.Labels, restartState)
 		cs.Running = n.Process.Status.Status == containerd.Running
 		cs.Paused = n.Process.Status.Status == containerd.Paused
 		cs.Pid = n.Process.Pid
@@ -508,6 +530,7 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	c.HostConfig.OomKillDisable = memorySettings.DisableOOMKiller
 	c.HostConfig.Memory = memorySettings.Limit
 	c.HostConfig.MemorySwap = memorySettings.Swap
+	c.HostConfig.MemorySwappiness = memorySettings.Swappiness
 
 	dnsSettings, err := getDNSFromNative(n.Labels)
 	if err != nil {
@@ -549,6 +572,16 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	c.Config = &Config{
 		Labels: n.Labels,
 	}
+	if hc, err := healthcheck.ParseLabel(n.Labels[labels.HealthCheck]); err != nil {
+		log.L.WithError(err).Errorf("failed to parse the healthcheck label")
+	} else if hc != nil {
+		c.Config.Healthcheck = hc
+		if health, err := healthcheck.ContainerHealth(n.Labels); err != nil {
+			log.L.WithError(err).Errorf("failed retrieving the health state")
+		} else {
+			c.State.Health = health
+		}
+	}
 	if n.Labels[labels.Hostname] != "" {
 		hostname = n.Labels[labels.Hostname]
 	}
@@ -558,6 +591,10 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 		c.Config.Domainname = n.Labels[labels.Domainname]
 	}
 
+	if exposedPortsJSON := n.Labels[labels.ExposedPorts]; exposedPortsJSON != "" {
+		c.Config.ExposedPorts = parseExposedPorts(exposedPortsJSON)
+	}
+
 	c.HostConfig.Devices = hostConfigLabel.Devices
 
 	var pidMode string
@@ -652,12 +689,15 @@ func mountsFromNative(spMounts []specs.Mount) []MountPoint {
 	return mountpoints
 }
 
-func statusFromNative(x containerd.Status, labels map[string]string) string {
+func statusFromNative(x containerd.Status, labels map[string]string, restartState *restartsupervisor.State) string {
 	switch s := x.Status; s {
 	case containerd.Stopped:
 		if labels[restart.StatusLabel] == string(containerd.Running) && restart.Reconcile(x, labels) {
 			return "restarting"
 		}
+		if restartState != nil && restartState.Restarting {
+			return "restarting"
+		}
 		return "exited"
 	default:
 		return string(s)
@@ -820,6 +860,31 @@ func parseExtraHosts(extraHostsJSON string) []string {
 	return extraHosts
 }
 
+// parseLinks returns the links in the Docker format ("/<target>:/<name>/<alias>").
+func parseLinks(linksJSON string, name string) []string {
+	var links []string
+	if err := json.Unmarshal([]byte(linksJSON), &links); err != nil {
+		return []string{}
+	}
+	for i, link := range links {
+		target, alias, _ := strings.Cut(link, ":")
+		links[i] = fmt.Sprintf("/%s:/%s/%s", target, name, alias)
+	}
+	return links
+}
+
+func parseExposedPorts(exposedPortsJSON string) nat.PortSet {
+	var ports []string
+	if err := json.Unmarshal([]byte(exposedPortsJSON), &ports); err != nil {
+		return nil
+	}
+	portSet := make(nat.PortSet)
+	for _, p := range ports {
+		portSet[nat.Port(p)] = struct{}{}
+	}
+	return portSet
+}
+
 func getMemorySettingsFromNative(sp *specs.Spec) (*MemorySetting, error) {
 	res := &MemorySetting{}
 	if sp.Linux != nil && sp.Linux.Resources != nil && sp.Linux.Resources.Memory != nil {
@@ -834,6 +899,11 @@ func getMemorySettingsFromNative(sp *specs.Spec) (*MemorySetting, error) {
 		if sp.Linux.Resources.Memory.Swap != nil {
 			res.Swap = *sp.Linux.Resources.Memory.Swap
 		}
+
+		if sp.Linux.Resources.Memory.Swappiness != nil {
+			swappiness := int64(*sp.Linux.Resources.Memory.Swappiness)
+			res.Swappiness = &swappiness
+		}
 	}
 	return res, nil
 }
@@ -905,6 +975,7 @@ type Network struct {
 	Name       string                      `json:"Name"`
 	ID         string                      `json:"Id,omitempty"` // optional in nerdctl
 	IPAM       IPAM                        `json:"IPAM,omitempty"`
+	Internal   bool                        `json:"Internal"`
 	Labels     map[string]string           `json:"Labels"`
 	Containers map[string]EndpointResource `json:"Containers"` // Containers contains endpoints belonging to the network
 	// Scope, Driver, etc. are omitted
@@ -928,9 +999,10 @@ type structuredCNI struct {
 }
 
 type MemorySetting struct {
-	Limit            int64 `json:"limit"`
-	Swap             int64 `json:"swap"`
-	DisableOOMKiller bool  `json:"disableOOMKiller"`
+	Limit            int64  `json:"limit"`
+	Swap             int64  `json:"swap"`
+	Swappiness       *int64 `json:"swappiness,omitempty"`
+	DisableOOMKiller bool   `json:"disableOOMKiller"`
 }
 
 func NetworkFromNative(n *native.Network) (*Network, error) {
@@ -955,6 +1027,7 @@ func NetworkFromNative(n *native.Network) (*Network, error) {
 
 	if n.NerdctlLabels != nil {
 		res.Labels = *n.NerdctlLabels
+		res.Internal, _ = strconv.ParseBool(res.Labels[labels.NetworkInternal])
 	}
 
 	res.Containers = make(map[string]EndpointResource)
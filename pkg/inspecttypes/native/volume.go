@@ -19,7 +19,10 @@ package native
 // Volume is also compatible with Docker
 type Volume struct {
 	Name       string             `json:"Name"`
+	Driver     string             `json:"Driver"`
 	Mountpoint string             `json:"Mountpoint"`
 	Labels     *map[string]string `json:"Labels,omitempty"`
-	Size       int64              `json:"Size,omitempty"`
+	// Options are the options of the local volume driver, e.g., {"type": "tmpfs", "device": "tmpfs", "o": "size=100m"}
+	Options map[string]string `json:"Options,omitempty"`
+	Size    int64             `json:"Size,omitempty"`
 }
@@ -57,9 +57,21 @@ const (
 	// Currently, the length of the slice must be 1.
 	Networks = Prefix + "networks"
 
+	// NetworkAliases is a JSON-marshalled string of map[string][]string, mapping the networks to the
+	// network-scoped aliases of the container.
+	NetworkAliases = Prefix + "network-aliases"
+
+	// Links is a JSON-marshalled string of []string, e.g. []string{"db:database"}.
+	// Each element is the name of the linked container and its alias.
+	Links = Prefix + "links"
+
 	// Ports is a JSON-marshalled string of []cni.PortMapping .
 	Ports = Prefix + "ports"
 
+	// ExposedPorts is a JSON-marshalled string of []string, e.g. []string{"80/tcp"}.
+	// It contains both the ports exposed by the image and by `--expose`.
+	ExposedPorts = Prefix + "exposed-ports"
+
 	// IPAddress is the static IP address of the container assigned by the user
 	IPAddress = Prefix + "ip"
 
@@ -104,6 +116,10 @@ const (
 	// (like "nerdctl/default-network=true" or "nerdctl/default-network=false")
 	NerdctlDefaultNetwork = Prefix + "default-network"
 
+	// NetworkInternal indicates whether a network was created with `nerdctl network create --internal`.
+	// An internal network has no default route, no masquerading, and no port publishing.
+	NetworkInternal = Prefix + "network-internal"
+
 	// ContainerAutoRemove is to check whether the --rm option is specified.
 	ContainerAutoRemove = Prefix + "auto-remove"
 
@@ -118,4 +134,11 @@ const (
 
 	// User is the username of the container
 	User = Prefix + "user"
+
+	// HealthCheck is the JSON-encoded health check configuration of the container
+	HealthCheck = Prefix + "healthcheck"
+
+	// RestartPolicy is the restart policy of a container supervised by `nerdctl system restart-supervisor`,
+	// instead of the containerd restart monitor
+	RestartPolicy = Prefix + "restart-policy"
 )
@@ -0,0 +1,126 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/templates"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+)
+
+// containerMetadata is the metadata of a container attached to the log messages by the remote logging drivers.
+type containerMetadata struct {
+	ID        string
+	FullID    string
+	Name      string
+	Namespace string
+	ImageName string
+	Command   string
+	Created   time.Time
+	Labels    map[string]string
+	Env       []string
+}
+
+// loadContainerMetadata queries containerd for the metadata of the container being logged.
+func loadContainerMetadata(ctx context.Context, address string, config *logging.Config) (*containerMetadata, error) {
+	client, ctx, cancel, err := clientutil.NewClient(ctx, config.Namespace, address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cancel()
+		client.Close()
+	}()
+	container, err := client.LoadContainer(ctx, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := container.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	md := &containerMetadata{
+		ID:        config.ID[:12],
+		FullID:    config.ID,
+		Name:      containerutil.GetContainerName(info.Labels),
+		Namespace: config.Namespace,
+		ImageName: info.Image,
+		Created:   info.CreatedAt,
+		Labels:    info.Labels,
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Process != nil {
+		md.Command = strings.Join(spec.Process.Args, " ")
+		md.Env = spec.Process.Env
+	}
+	return md, nil
+}
+
+// Tag returns the value of the "tag" log option, executed as a template against the metadata.
+// The short container ID is returned if the option is not set.
+func (md *containerMetadata) Tag(opts map[string]string) (string, error) {
+	tag, ok := opts[Tag]
+	if !ok {
+		return md.ID, nil
+	}
+	tmpl, err := templates.Parse(tag)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, md); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ExtraAttributes returns the values of the container labels and environment variables
+// selected with the "labels" and "env" log options, like Docker.
+func (md *containerMetadata) ExtraAttributes(opts map[string]string) map[string]string {
+	extra := make(map[string]string)
+	if keys, ok := opts[Labels]; ok {
+		for _, k := range strings.Split(keys, ",") {
+			if v, ok := md.Labels[k]; ok {
+				extra[k] = v
+			}
+		}
+	}
+	if keys, ok := opts[Env]; ok {
+		env := make(map[string]string)
+		for _, kv := range md.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			env[k] = v
+		}
+		for _, k := range strings.Split(keys, ",") {
+			if v, ok := env[k]; ok {
+				extra[k] = v
+			}
+		}
+	}
+	return extra
+}
@@ -0,0 +1,333 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+)
+
+// Options of the local cache of the drivers shipping the logs to a remote destination ("dual logging").
+const (
+	CacheDisabled = "cache-disabled"
+	CacheMaxSize  = "cache-max-size"
+	CacheMaxFile  = "cache-max-file"
+	// Mode is the delivery mode of the logs to the remote driver, either "blocking" or "non-blocking".
+	Mode = "mode"
+	// MaxBufferSize is the size of the buffer of the remote driver in the non-blocking mode.
+	MaxBufferSize = "max-buffer-size"
+)
+
+var CacheLogOpts = []string{
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	Mode,
+	MaxBufferSize,
+}
+
+const (
+	modeBlocking    = "blocking"
+	modeNonBlocking = "non-blocking"
+)
+
+// Docker-compatible defaults
+const (
+	defaultCacheMaxSize  = "20m"
+	defaultCacheMaxFile  = "5"
+	defaultMaxBufferSize = "1m"
+)
+
+// dualLoggingDrivers are the drivers that cannot read back the logs they ship,
+// and that keep a local copy for `nerdctl logs`, like Docker.
+var dualLoggingDrivers = map[string]struct{}{
+	"fluentd": {},
+	"syslog":  {},
+	"gelf":    {},
+	"otlp":    {},
+}
+
+// CachePath returns the path of the local log cache of a container.
+func CachePath(dataStore, ns, id string) string {
+	// the file name corresponds to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "container-cached.log")
+}
+
+// cacheEnabled returns true if the driver keeps a local log cache with the given options.
+func cacheEnabled(driver string, opts map[string]string) bool {
+	if _, ok := dualLoggingDrivers[driver]; !ok {
+		return false
+	}
+	disabled, _ := strconv.ParseBool(opts[CacheDisabled])
+	return !disabled
+}
+
+// cacheViewable returns true if the logs of the container can be read from the local cache.
+// Containers created before the local cache was introduced have no cache file, and keep using the viewer of their driver.
+func cacheViewable(lcfg LogConfig, lvopts LogViewOptions) bool {
+	if !cacheEnabled(lcfg.Driver, lcfg.Opts) {
+		return false
+	}
+	_, err := os.Stat(CachePath(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID))
+	return err == nil
+}
+
+func validateCacheLogOpts(logOptMap map[string]string) error {
+	if v, ok := logOptMap[CacheDisabled]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", CacheDisabled, v, err)
+		}
+	}
+	if v, ok := logOptMap[CacheMaxSize]; ok {
+		size, err := units.FromHumanSize(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", CacheMaxSize, v, err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("%s must be a positive number", CacheMaxSize)
+		}
+	}
+	if v, ok := logOptMap[CacheMaxFile]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid %s %q, must be a positive integer", CacheMaxFile, v)
+		}
+	}
+	mode := logOptMap[Mode]
+	switch mode {
+	case "", modeBlocking, modeNonBlocking:
+	default:
+		return fmt.Errorf("invalid %s %q, must be %q or %q", Mode, mode, modeBlocking, modeNonBlocking)
+	}
+	if v, ok := logOptMap[MaxBufferSize]; ok {
+		if mode != modeNonBlocking {
+			return fmt.Errorf("%s is only supported with %s=%s", MaxBufferSize, Mode, modeNonBlocking)
+		}
+		size, err := units.RAMInBytes(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", MaxBufferSize, v, err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("%s must be a positive number", MaxBufferSize)
+		}
+	}
+	return nil
+}
+
+// dualLogger sends the logs to the remote driver, and writes them to a rotated local cache
+// in the json-file format, so that they can be read by `nerdctl logs`.
+type dualLogger struct {
+	Driver
+	cache *JSONLogger
+	// maxBufferSize is the size in bytes of the buffer of the remote driver, when the lines are dropped
+	// instead of blocking the container on a slow remote driver. Zero means blocking.
+	maxBufferSize int64
+}
+
+func newDualLogger(driver Driver, opts map[string]string) *dualLogger {
+	cacheOpts := map[string]string{
+		MaxSize: defaultCacheMaxSize,
+		MaxFile: defaultCacheMaxFile,
+	}
+	if v, ok := opts[CacheMaxSize]; ok {
+		cacheOpts[MaxSize] = v
+	}
+	if v, ok := opts[CacheMaxFile]; ok {
+		cacheOpts[MaxFile] = v
+	}
+	d := &dualLogger{
+		Driver: driver,
+		cache:  &JSONLogger{Opts: cacheOpts},
+	}
+	if opts[Mode] == modeNonBlocking {
+		maxBufferSize := defaultMaxBufferSize
+		if v, ok := opts[MaxBufferSize]; ok {
+			maxBufferSize = v
+		}
+		// the options were validated on container creation
+		d.maxBufferSize, _ = units.RAMInBytes(maxBufferSize)
+	}
+	return d
+}
+
+func (d *dualLogger) Init(dataStore, ns, id string) error {
+	if err := d.Driver.Init(dataStore, ns, id); err != nil {
+		return err
+	}
+	d.cache.Opts[LogPath] = CachePath(dataStore, ns, id)
+	return d.cache.Init(dataStore, ns, id)
+}
+
+func (d *dualLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	d.cache.Opts[LogPath] = CachePath(dataStore, config.Namespace, config.ID)
+	if err := d.cache.PreProcess(ctx, dataStore, config); err != nil {
+		return err
+	}
+	return d.Driver.PreProcess(ctx, dataStore, config)
+}
+
+func (d *dualLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	remoteStdout, cacheStdout := make(chan string, dualLoggerBufferSize), make(chan string, dualLoggerBufferSize)
+	remoteStderr, cacheStderr := make(chan string, dualLoggerBufferSize), make(chan string, dualLoggerBufferSize)
+	go tee("stdout", stdout, remoteStdout, cacheStdout, d.maxBufferSize)
+	go tee("stderr", stderr, remoteStderr, cacheStderr, d.maxBufferSize)
+
+	var (
+		wg                  sync.WaitGroup
+		remoteErr, cacheErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		remoteErr = d.Driver.Process(remoteStdout, remoteStderr)
+		// keep the local cache going when the remote driver exits before the container
+		go drain(remoteStdout)
+		drain(remoteStderr)
+	}()
+	go func() {
+		defer wg.Done()
+		cacheErr = d.cache.Process(cacheStdout, cacheStderr)
+	}()
+	wg.Wait()
+	return errors.Join(remoteErr, cacheErr)
+}
+
+// dualLoggerBufferSize is the number of lines buffered for the remote driver and for the local cache.
+const dualLoggerBufferSize = 1000
+
+// tee copies the lines of in to remote and cache.
+// By default, a slow remote driver blocks the local cache, and the stdout and stderr pipes of the container, like Docker.
+// When maxBufferSize is positive (mode=non-blocking), up to maxBufferSize bytes of lines are buffered for the
+// remote driver, and the lines that do not fit are dropped.
+func tee(stream string, in <-chan string, remote, cache chan<- string, maxBufferSize int64) {
+	if maxBufferSize <= 0 {
+		for line := range in {
+			cache <- line
+			remote <- line
+		}
+		close(remote)
+		close(cache)
+		return
+	}
+
+	buf := newLineBuffer(maxBufferSize)
+	go func() {
+		for {
+			line, ok := buf.pop()
+			if !ok {
+				break
+			}
+			remote <- line
+		}
+		close(remote)
+	}()
+	var dropped int
+	for line := range in {
+		cache <- line
+		if !buf.push(line) {
+			dropped++
+			if dropped%dualLoggerBufferSize == 1 {
+				log.L.Warnf("the remote logging driver is not keeping up, dropped %d lines of %s so far", dropped, stream)
+			}
+		}
+	}
+	if dropped > 0 {
+		log.L.Warnf("the remote logging driver dropped %d lines of %s in total", dropped, stream)
+	}
+	buf.close()
+	close(cache)
+}
+
+// drain discards the lines of ch until it is closed.
+func drain(ch <-chan string) {
+	for range ch {
+	}
+}
+
+// lineBuffer is a FIFO of lines bounded by their total size in bytes.
+type lineBuffer struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	lines   []string
+	size    int64
+	maxSize int64
+	closed  bool
+}
+
+func newLineBuffer(maxSize int64) *lineBuffer {
+	b := &lineBuffer{maxSize: maxSize}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// push appends line to the buffer, and returns false if it does not fit.
+func (b *lineBuffer) push(line string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.size+int64(len(line)) > b.maxSize {
+		return false
+	}
+	b.lines = append(b.lines, line)
+	b.size += int64(len(line))
+	b.cond.Signal()
+	return true
+}
+
+// pop waits for the first line of the buffer, and returns false once the buffer is closed and empty.
+func (b *lineBuffer) pop() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.lines) == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.lines) == 0 {
+		return "", false
+	}
+	line := b.lines[0]
+	b.lines[0] = ""
+	b.lines = b.lines[1:]
+	b.size -= int64(len(line))
+	return line, true
+}
+
+func (b *lineBuffer) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+func (d *dualLogger) PostProcess() error {
+	return errors.Join(d.Driver.PostProcess(), d.cache.PostProcess())
+}
+
+// viewLogsCache reads the local log cache of the drivers that do not have a log viewer of their own.
+func viewLogsCache(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	cachePath := CachePath(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	return viewLogsJSONFileDirect(lvopts, cachePath, stdout, stderr, stopChannel)
+}
@@ -0,0 +1,187 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+)
+
+func TestCacheEnabled(t *testing.T) {
+	assert.Assert(t, cacheEnabled("fluentd", map[string]string{}))
+	assert.Assert(t, cacheEnabled("gelf", map[string]string{CacheDisabled: "false"}))
+	assert.Assert(t, !cacheEnabled("syslog", map[string]string{CacheDisabled: "true"}))
+	assert.Assert(t, !cacheEnabled("json-file", map[string]string{}))
+
+	assert.NilError(t, ValidateLogOpts("syslog", map[string]string{CacheMaxSize: "10m", CacheMaxFile: "3"}))
+	assert.ErrorContains(t, ValidateLogOpts("fluentd", map[string]string{CacheDisabled: "maybe"}), "invalid cache-disabled")
+	assert.ErrorContains(t, ValidateLogOpts("fluentd", map[string]string{CacheMaxFile: "0"}), "invalid cache-max-file")
+
+	assert.NilError(t, ValidateLogOpts("gelf", map[string]string{Mode: "non-blocking", MaxBufferSize: "4m"}))
+	assert.NilError(t, ValidateLogOpts("fluentd", map[string]string{Mode: "blocking"}))
+	assert.ErrorContains(t, ValidateLogOpts("syslog", map[string]string{Mode: "async"}), "invalid mode")
+	assert.ErrorContains(t, ValidateLogOpts("otlp", map[string]string{MaxBufferSize: "4m"}), "only supported with mode=non-blocking")
+}
+
+// exitedDriver is a remote driver that exits without reading the logs, like a driver that lost its endpoint.
+type exitedDriver struct {
+	MockDriver
+}
+
+func (d *exitedDriver) Process(stdout <-chan string, stderr <-chan string) error {
+	return errors.New("connection refused")
+}
+
+func TestDualLoggerRemoteExited(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	d := newDualLogger(&exitedDriver{}, map[string]string{})
+	assert.NilError(t, d.Init(dataStore, ns, id))
+	assert.NilError(t, d.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: ns, ID: id}))
+
+	stdout, stderr := make(chan string), make(chan string)
+	go func() {
+		for i := 0; i < 3*dualLoggerBufferSize; i++ {
+			stdout <- "foo"
+		}
+		close(stdout)
+		close(stderr)
+	}()
+	assert.ErrorContains(t, d.Process(stdout, stderr), "connection refused")
+	assert.NilError(t, d.PostProcess())
+
+	var outBuf, errBuf bytes.Buffer
+	lvopts := LogViewOptions{ContainerID: id, Namespace: ns, DatastoreRootPath: dataStore}
+	assert.NilError(t, viewLogsCache(lvopts, &outBuf, &errBuf, make(chan os.Signal)))
+	assert.Equal(t, strings.Count(outBuf.String(), "foo\n"), 3*dualLoggerBufferSize)
+}
+
+// gatedDriver is a remote driver that does not read the logs until gate is closed, like a slow endpoint.
+type gatedDriver struct {
+	MockDriver
+	gate chan struct{}
+}
+
+func (d *gatedDriver) Process(stdout <-chan string, stderr <-chan string) error {
+	<-d.gate
+	return d.MockDriver.Process(stdout, stderr)
+}
+
+func TestDualLoggerBlocking(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	remote := &gatedDriver{gate: make(chan struct{})}
+	d := newDualLogger(remote, map[string]string{})
+	assert.NilError(t, d.Init(dataStore, ns, id))
+	assert.NilError(t, d.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: ns, ID: id}))
+
+	stdout, stderr := make(chan string), make(chan string)
+	go func() {
+		for i := 0; i < 3*dualLoggerBufferSize; i++ {
+			stdout <- "foo"
+		}
+		close(stdout)
+		close(stderr)
+	}()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(remote.gate)
+	}()
+	assert.NilError(t, d.Process(stdout, stderr))
+	assert.NilError(t, d.PostProcess())
+	assert.Equal(t, len(remote.receivedStdout), 3*dualLoggerBufferSize)
+}
+
+func TestLineBuffer(t *testing.T) {
+	b := newLineBuffer(6)
+	assert.Assert(t, b.push("foo"))
+	assert.Assert(t, b.push("bar"))
+	// the buffer is full, the line is dropped
+	assert.Assert(t, !b.push("baz"))
+
+	line, ok := b.pop()
+	assert.Assert(t, ok)
+	assert.Equal(t, line, "foo")
+	assert.Assert(t, b.push("qux"))
+
+	b.close()
+	for _, expected := range []string{"bar", "qux"} {
+		line, ok = b.pop()
+		assert.Assert(t, ok)
+		assert.Equal(t, line, expected)
+	}
+	_, ok = b.pop()
+	assert.Assert(t, !ok)
+}
+
+func TestCacheViewable(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	lcfg := LogConfig{Driver: "fluentd", Opts: map[string]string{}}
+	lvopts := LogViewOptions{ContainerID: id, Namespace: ns, DatastoreRootPath: dataStore}
+	// containers created before the local cache was introduced have no cache file
+	assert.Assert(t, !cacheViewable(lcfg, lvopts))
+
+	assert.NilError(t, newDualLogger(&MockDriver{}, lcfg.Opts).Init(dataStore, ns, id))
+	assert.Assert(t, cacheViewable(lcfg, lvopts))
+	lcfg.Opts[CacheDisabled] = "true"
+	assert.Assert(t, !cacheViewable(lcfg, lvopts))
+}
+
+func TestDualLogger(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	remote := &MockDriver{}
+	d := newDualLogger(remote, map[string]string{CacheMaxSize: "1m"})
+	assert.NilError(t, d.Init(dataStore, ns, id))
+	assert.NilError(t, d.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: ns, ID: id}))
+
+	stdout := make(chan string, 2)
+	stderr := make(chan string, 1)
+	stdout <- "foo"
+	stdout <- "bar"
+	stderr <- "baz"
+	close(stdout)
+	close(stderr)
+	assert.NilError(t, d.Process(stdout, stderr))
+	assert.NilError(t, d.PostProcess())
+
+	assert.DeepEqual(t, remote.receivedStdout, []string{"foo", "bar"})
+	assert.DeepEqual(t, remote.receivedStderr, []string{"baz"})
+
+	var outBuf, errBuf bytes.Buffer
+	lvopts := LogViewOptions{ContainerID: id, Namespace: ns, DatastoreRootPath: dataStore}
+	assert.NilError(t, viewLogsCache(lvopts, &outBuf, &errBuf, make(chan os.Signal)))
+	assert.Equal(t, outBuf.String(), "foo\nbar\n")
+	assert.Equal(t, errBuf.String(), "baz\n")
+
+	lvopts.Tail = 1
+	outBuf.Reset()
+	errBuf.Reset()
+	assert.NilError(t, viewLogsCache(lvopts, &outBuf, &errBuf, make(chan os.Signal)))
+	// stdout and stderr are written concurrently, so the last line can be either "bar" or "baz"
+	assert.Equal(t, strings.Count(outBuf.String()+errBuf.String(), "\n"), 1)
+}
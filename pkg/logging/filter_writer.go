@@ -0,0 +1,187 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output formats of the log viewers
+const (
+	// FormatRaw writes the log lines as they were written by the container
+	FormatRaw = ""
+	// FormatJSON writes one JSON record per log line, see LogRecord
+	FormatJSON = "json"
+)
+
+// LogRecord is a log line in the "json" output format.
+type LogRecord struct {
+	Time      string `json:"time,omitempty"`
+	Stream    string `json:"stream"`
+	Container string `json:"container"`
+	Message   string `json:"message"`
+	// Fields is the parsed message, when the message is itself a JSON object
+	Fields json.RawMessage `json:"fields,omitempty"`
+	// Details are the extra details enabled by `--details`
+	Details string `json:"details,omitempty"`
+}
+
+// filterWriters filter and format the lines written by a log viewer to stdout and stderr,
+// according to the Grep, Stream and Format options.
+type filterWriters struct {
+	opts       LogViewOptions
+	grep       *regexp.Regexp
+	timestamps bool
+	// mu serializes the writes of the JSON records, which are all written to the same writer
+	mu     sync.Mutex
+	stdout *lineFilterWriter
+	stderr *lineFilterWriter
+}
+
+// needsFilterWriters returns true if the lines written by the log viewers need to be filtered or formatted.
+func needsFilterWriters(lvopts LogViewOptions) bool {
+	return lvopts.Grep != "" || lvopts.Stream != "" || lvopts.Format != FormatRaw
+}
+
+// newFilterWriters returns the writers to pass to the log viewer.
+// timestamps tells whether the viewer prefixes the lines with their timestamp.
+func newFilterWriters(stdout, stderr io.Writer, lvopts LogViewOptions, timestamps bool) (*filterWriters, error) {
+	fw := &filterWriters{
+		opts:       lvopts,
+		timestamps: timestamps,
+	}
+	if lvopts.Grep != "" {
+		var err error
+		if fw.grep, err = regexp.Compile(lvopts.Grep); err != nil {
+			return nil, err
+		}
+	}
+	if lvopts.Format == FormatJSON {
+		// all the records are written to stdout, as they carry their stream
+		stderr = stdout
+	}
+	fw.stdout = &lineFilterWriter{parent: fw, w: stdout, stream: "stdout"}
+	fw.stderr = &lineFilterWriter{parent: fw, w: stderr, stream: "stderr"}
+	if lvopts.Stream == "stdout" {
+		fw.stderr.w = io.Discard
+	} else if lvopts.Stream == "stderr" {
+		fw.stdout.w = io.Discard
+	}
+	return fw, nil
+}
+
+// Flush writes the last lines, even if they are not terminated by a newline.
+func (fw *filterWriters) Flush() error {
+	if err := fw.stdout.flush(); err != nil {
+		return err
+	}
+	return fw.stderr.flush()
+}
+
+type lineFilterWriter struct {
+	parent *filterWriters
+	w      io.Writer
+	stream string
+	buf    []byte
+}
+
+func (lw *lineFilterWriter) Write(p []byte) (int, error) {
+	if lw.w == io.Discard {
+		return len(p), nil
+	}
+	lw.buf = append(lw.buf, p...)
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := lw.buf[:i]
+		lw.buf = lw.buf[i+1:]
+		if err := lw.writeLine(string(line), true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (lw *lineFilterWriter) flush() error {
+	if len(lw.buf) == 0 || lw.w == io.Discard {
+		return nil
+	}
+	line := string(lw.buf)
+	lw.buf = nil
+	return lw.writeLine(line, false)
+}
+
+func (lw *lineFilterWriter) writeLine(line string, newline bool) error {
+	fw := lw.parent
+	var ts string
+	if fw.timestamps {
+		if prefix, rest, ok := strings.Cut(line, " "); ok {
+			if _, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+				ts, line = prefix, rest
+			}
+		}
+	}
+	if fw.grep != nil && !fw.grep.MatchString(line) {
+		return nil
+	}
+
+	if fw.opts.Format == FormatJSON {
+		record := LogRecord{
+			Time:      ts,
+			Stream:    lw.stream,
+			Container: fw.opts.ContainerName,
+			Message:   line,
+		}
+		if fw.opts.ContainerName == "" {
+			record.Container = fw.opts.ContainerID
+		}
+		if fw.opts.Details && fw.opts.DetailPrefix != nil {
+			record.Details = *fw.opts.DetailPrefix
+		}
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+			record.Fields = json.RawMessage(trimmed)
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		fw.mu.Lock()
+		defer fw.mu.Unlock()
+		_, err = lw.w.Write(append(b, '\n'))
+		return err
+	}
+
+	var out []byte
+	if ts != "" && fw.opts.Timestamps {
+		out = append(out, ts...)
+		out = append(out, ' ')
+	}
+	out = append(out, line...)
+	if newline {
+		out = append(out, '\n')
+	}
+	_, err := lw.w.Write(out)
+	return err
+}
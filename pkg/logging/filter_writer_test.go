@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFilterWriters(t *testing.T) {
+	const (
+		ts1 = "2024-07-12T03:09:24.916296732Z"
+		ts2 = "2024-07-12T03:09:25.916296732Z"
+	)
+	write := func(t *testing.T, lvopts LogViewOptions, timestamps bool) (string, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		fw, err := newFilterWriters(&stdout, &stderr, lvopts, timestamps)
+		assert.NilError(t, err)
+		prefix := func(ts string) string {
+			if timestamps {
+				return ts + " "
+			}
+			return ""
+		}
+		// lines may be split across writes
+		fmt.Fprintf(fw.stdout, "%sfoo error\n%sbar ", prefix(ts1), prefix(ts2))
+		fmt.Fprintf(fw.stdout, "info\n%s{\"level\":\"error\",\"n\":1}\n", prefix(ts2))
+		fmt.Fprintf(fw.stderr, "%serror: baz\n%sunterminated error", prefix(ts1), prefix(ts2))
+		assert.NilError(t, fw.Flush())
+		return stdout.String(), stderr.String()
+	}
+
+	t.Run("grep", func(t *testing.T) {
+		stdout, stderr := write(t, LogViewOptions{Grep: "error"}, false)
+		assert.Equal(t, stdout, "foo error\n{\"level\":\"error\",\"n\":1}\n")
+		assert.Equal(t, stderr, "error: baz\nunterminated error")
+	})
+
+	t.Run("grep does not match the timestamps", func(t *testing.T) {
+		stdout, stderr := write(t, LogViewOptions{Grep: "^(foo|bar)", Timestamps: true}, true)
+		assert.Equal(t, stdout, ts1+" foo error\n"+ts2+" bar info\n")
+		assert.Equal(t, stderr, "")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stdout, stderr := write(t, LogViewOptions{Stream: "stderr"}, false)
+		assert.Equal(t, stdout, "")
+		assert.Equal(t, stderr, "error: baz\nunterminated error")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		detailPrefix := "env=foo"
+		lvopts := LogViewOptions{
+			ContainerID:   "0123456789abcdef",
+			ContainerName: "foo",
+			Format:        FormatJSON,
+			Stream:        "stdout",
+			Details:       true,
+			DetailPrefix:  &detailPrefix,
+		}
+		stdout, stderr := write(t, lvopts, true)
+		assert.Equal(t, stderr, "")
+		var records []LogRecord
+		dec := json.NewDecoder(bytes.NewBufferString(stdout))
+		for dec.More() {
+			var r LogRecord
+			assert.NilError(t, dec.Decode(&r))
+			records = append(records, r)
+		}
+		assert.DeepEqual(t, records, []LogRecord{
+			{Time: ts1, Stream: "stdout", Container: "foo", Message: "foo error", Details: detailPrefix},
+			{Time: ts2, Stream: "stdout", Container: "foo", Message: "bar info", Details: detailPrefix},
+			{Time: ts2, Stream: "stdout", Container: "foo", Message: `{"level":"error","n":1}`,
+				Fields: json.RawMessage(`{"level":"error","n":1}`), Details: detailPrefix},
+		})
+	})
+
+	_, err := newFilterWriters(&bytes.Buffer{}, &bytes.Buffer{}, LogViewOptions{Grep: "("}, false)
+	assert.ErrorContains(t, err, "missing closing")
+}
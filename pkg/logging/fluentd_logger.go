@@ -62,6 +62,11 @@ var FluentdLogOpts = []string{
 	fluentdAsyncReconnectInterval,
 	fluentRequestAck,
 	Tag,
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	Mode,
+	MaxBufferSize,
 }
 
 const (
@@ -207,6 +212,7 @@ func ValidateFluentdLoggerOpts(config map[string]string) error {
 		case fluentAddress:
 		case fluentdAsyncReconnectInterval:
 		case fluentRequestAck:
+		case CacheDisabled, CacheMaxSize, CacheMaxFile, Mode, MaxBufferSize:
 		// Accepted logger opts
 		default:
 			return fmt.Errorf("unknown log opt '%s' for fluentd log driver", key)
@@ -0,0 +1,435 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	gelfAddress           = "gelf-address"
+	gelfCompressionType   = "gelf-compression-type"
+	gelfCompressionLevel  = "gelf-compression-level"
+	gelfTCPMaxReconnect   = "gelf-tcp-max-reconnect"
+	gelfTCPReconnectDelay = "gelf-tcp-reconnect-delay"
+)
+
+var GelfLogOpts = []string{
+	gelfAddress,
+	gelfCompressionType,
+	gelfCompressionLevel,
+	gelfTCPMaxReconnect,
+	gelfTCPReconnectDelay,
+	Tag,
+	Labels,
+	Env,
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	Mode,
+	MaxBufferSize,
+}
+
+const (
+	gelfCompressionGzip = "gzip"
+	gelfCompressionZlib = "zlib"
+	gelfCompressionNone = "none"
+
+	// Docker-compatible defaults
+	gelfDefaultCompressionLevel  = flate.BestSpeed
+	gelfDefaultTCPMaxReconnect   = 3
+	gelfDefaultTCPReconnectDelay = time.Second
+
+	// gelfChunkSize is the maximum size of a UDP datagram, including the chunk header.
+	// It fits in the usual 1500 bytes MTU, like Docker.
+	gelfChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the header of a chunk:
+	// magic bytes (2), message ID (8), sequence number (1) and sequence count (1)
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the maximum number of chunks of a message, as per the GELF specification
+	gelfMaxChunks = 128
+
+	// GELF levels are syslog severities
+	gelfLevelError = 3
+	gelfLevelInfo  = 6
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+func GelfLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(GelfLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for gelf log driver", key)
+		}
+	}
+	_, err := parseGelfConfig(logOptMap)
+	return err
+}
+
+type gelfConfig struct {
+	proto             string
+	address           string
+	compressionType   string
+	compressionLevel  int
+	tcpMaxReconnect   int
+	tcpReconnectDelay time.Duration
+}
+
+func parseGelfConfig(opts map[string]string) (*gelfConfig, error) {
+	cfg := &gelfConfig{
+		compressionType:   gelfCompressionGzip,
+		compressionLevel:  gelfDefaultCompressionLevel,
+		tcpMaxReconnect:   gelfDefaultTCPMaxReconnect,
+		tcpReconnectDelay: gelfDefaultTCPReconnectDelay,
+	}
+	var err error
+	cfg.proto, cfg.address, err = parseGelfAddress(opts[gelfAddress])
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := opts[gelfCompressionType]; ok {
+		if cfg.proto == "tcp" {
+			return nil, fmt.Errorf("%s is not supported with the tcp protocol", gelfCompressionType)
+		}
+		switch v {
+		case gelfCompressionGzip, gelfCompressionZlib, gelfCompressionNone:
+			cfg.compressionType = v
+		default:
+			return nil, fmt.Errorf("invalid %s %q, must be %q, %q or %q", gelfCompressionType, v, gelfCompressionGzip, gelfCompressionZlib, gelfCompressionNone)
+		}
+	}
+	if v, ok := opts[gelfCompressionLevel]; ok {
+		if cfg.proto == "tcp" {
+			return nil, fmt.Errorf("%s is not supported with the tcp protocol", gelfCompressionLevel)
+		}
+		cfg.compressionLevel, err = strconv.Atoi(v)
+		if err != nil || cfg.compressionLevel < flate.DefaultCompression || cfg.compressionLevel > flate.BestCompression {
+			return nil, fmt.Errorf("invalid %s %q, must be an integer between %d and %d", gelfCompressionLevel, v, flate.DefaultCompression, flate.BestCompression)
+		}
+	}
+	if v, ok := opts[gelfTCPMaxReconnect]; ok {
+		if cfg.proto != "tcp" {
+			return nil, fmt.Errorf("%s is only supported with the tcp protocol", gelfTCPMaxReconnect)
+		}
+		cfg.tcpMaxReconnect, err = strconv.Atoi(v)
+		if err != nil || cfg.tcpMaxReconnect < 0 {
+			return nil, fmt.Errorf("invalid %s %q, must be a positive integer", gelfTCPMaxReconnect, v)
+		}
+	}
+	if v, ok := opts[gelfTCPReconnectDelay]; ok {
+		if cfg.proto != "tcp" {
+			return nil, fmt.Errorf("%s is only supported with the tcp protocol", gelfTCPReconnectDelay)
+		}
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid %s %q, must be a positive number of seconds", gelfTCPReconnectDelay, v)
+		}
+		cfg.tcpReconnectDelay = time.Duration(seconds) * time.Second
+	}
+	return cfg, nil
+}
+
+func parseGelfAddress(address string) (string, string, error) {
+	if address == "" {
+		return "", "", fmt.Errorf("%s is required for gelf log driver", gelfAddress)
+	}
+	addr, err := url.Parse(address)
+	if err != nil {
+		return "", "", err
+	}
+	if addr.Scheme != "udp" && addr.Scheme != "tcp" {
+		return "", "", fmt.Errorf("unsupported scheme: '%s', must be udp or tcp", addr.Scheme)
+	}
+	if _, _, err := net.SplitHostPort(addr.Host); err != nil {
+		return "", "", fmt.Errorf("invalid %s %q: %w", gelfAddress, address, err)
+	}
+	return addr.Scheme, addr.Host, nil
+}
+
+type GelfLogger struct {
+	Opts    map[string]string
+	Address string
+	writer  gelfWriter
+	// fields are the fields of every message, besides short_message, timestamp and level
+	fields map[string]interface{}
+}
+
+func (g *GelfLogger) Init(dataStore, ns, id string) error {
+	return nil
+}
+
+func (g *GelfLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	cfg, err := parseGelfConfig(g.Opts)
+	if err != nil {
+		return err
+	}
+	md, err := loadContainerMetadata(ctx, g.Address, config)
+	if err != nil {
+		return err
+	}
+	tag, err := md.Tag(g.Opts)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	fields := map[string]interface{}{
+		"version":         "1.1",
+		"host":            hostname,
+		"_container_id":   md.FullID,
+		"_container_name": md.Name,
+		"_image_name":     md.ImageName,
+		"_command":        md.Command,
+		"_created":        md.Created.Format(time.RFC3339Nano),
+		"_namespace":      md.Namespace,
+		"_tag":            tag,
+	}
+	for k, v := range md.ExtraAttributes(g.Opts) {
+		// "_id" is reserved by the GELF specification
+		if k == "id" {
+			continue
+		}
+		fields["_"+k] = v
+	}
+	g.fields = fields
+	g.writer, err = newGelfWriter(cfg)
+	return err
+}
+
+func (g *GelfLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	wg.Add(2)
+	fn := func(dataChan <-chan string, level int) {
+		defer wg.Done()
+		for line := range dataChan {
+			msg, err := g.message(line, level, time.Now())
+			if err != nil {
+				log.L.WithError(err).Error("failed to encode gelf message")
+				continue
+			}
+			mu.Lock()
+			err = g.writer.WriteMessage(msg)
+			mu.Unlock()
+			if err != nil {
+				log.L.WithError(err).Error("failed to send gelf message")
+			}
+		}
+	}
+	go fn(stdout, gelfLevelInfo)
+	go fn(stderr, gelfLevelError)
+	wg.Wait()
+	return nil
+}
+
+func (g *GelfLogger) PostProcess() error {
+	if g.writer == nil {
+		return nil
+	}
+	return g.writer.Close()
+}
+
+// message returns the JSON-encoded GELF message for a log line.
+func (g *GelfLogger) message(line string, level int, t time.Time) ([]byte, error) {
+	m := make(map[string]interface{}, len(g.fields)+3)
+	for k, v := range g.fields {
+		m[k] = v
+	}
+	m["short_message"] = line
+	m["timestamp"] = float64(t.UnixNano()) / float64(time.Second)
+	m["level"] = level
+	return json.Marshal(m)
+}
+
+type gelfWriter interface {
+	WriteMessage(msg []byte) error
+	Close() error
+}
+
+func newGelfWriter(cfg *gelfConfig) (gelfWriter, error) {
+	if cfg.proto == "tcp" {
+		w := &gelfTCPWriter{
+			address:        cfg.address,
+			maxReconnect:   cfg.tcpMaxReconnect,
+			reconnectDelay: cfg.tcpReconnectDelay,
+		}
+		if err := w.connect(); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+	conn, err := net.Dial("udp", cfg.address)
+	if err != nil {
+		return nil, err
+	}
+	return &gelfUDPWriter{
+		conn:             conn,
+		compressionType:  cfg.compressionType,
+		compressionLevel: cfg.compressionLevel,
+	}, nil
+}
+
+// gelfUDPWriter sends compressed messages as UDP datagrams, split in chunks when they exceed gelfChunkSize.
+type gelfUDPWriter struct {
+	conn             net.Conn
+	compressionType  string
+	compressionLevel int
+}
+
+func (w *gelfUDPWriter) WriteMessage(msg []byte) error {
+	data, err := gelfCompress(msg, w.compressionType, w.compressionLevel)
+	if err != nil {
+		return err
+	}
+	if len(data) <= gelfChunkSize {
+		_, err = w.conn.Write(data)
+		return err
+	}
+	chunks, err := gelfChunks(data)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *gelfUDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+func gelfCompress(msg []byte, compressionType string, level int) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		zw  io.WriteCloser
+		err error
+	)
+	switch compressionType {
+	case gelfCompressionNone:
+		return msg, nil
+	case gelfCompressionZlib:
+		zw, err = zlib.NewWriterLevel(&buf, level)
+	default:
+		zw, err = gzip.NewWriterLevel(&buf, level)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gelfChunks splits data in chunked GELF datagrams sharing a random message ID.
+func gelfChunks(data []byte) ([][]byte, error) {
+	chunkDataSize := gelfChunkSize - gelfChunkHeaderSize
+	count := (len(data) + chunkDataSize - 1) / chunkDataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("gelf message too large: %d bytes would need %d chunks, the maximum is %d", len(data), count, gelfMaxChunks)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*chunkDataSize, len(data))
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-i*chunkDataSize)
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*chunkDataSize:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// gelfTCPWriter sends uncompressed, null-byte delimited messages, reconnecting on failures.
+type gelfTCPWriter struct {
+	address        string
+	maxReconnect   int
+	reconnectDelay time.Duration
+	conn           net.Conn
+}
+
+func (w *gelfTCPWriter) connect() error {
+	conn, err := net.Dial("tcp", w.address)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *gelfTCPWriter) WriteMessage(msg []byte) error {
+	data := append(msg, 0)
+	var err error
+	for i := 0; i <= w.maxReconnect; i++ {
+		if i > 0 {
+			time.Sleep(w.reconnectDelay)
+		}
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				continue
+			}
+		}
+		if _, err = w.conn.Write(data); err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return errors.Join(fmt.Errorf("failed to send gelf message after %d reconnections", w.maxReconnect), err)
+}
+
+func (w *gelfTCPWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
@@ -0,0 +1,136 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseGelfConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]string
+		wantErr string
+	}{
+		{name: "udp", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201"}},
+		{name: "tcp", opts: map[string]string{gelfAddress: "tcp://127.0.0.1:12201", gelfTCPMaxReconnect: "5"}},
+		{name: "missingAddress", opts: map[string]string{}, wantErr: "is required"},
+		{name: "missingPort", opts: map[string]string{gelfAddress: "udp://127.0.0.1"}, wantErr: "missing port"},
+		{name: "invalidScheme", opts: map[string]string{gelfAddress: "http://127.0.0.1:12201"}, wantErr: "unsupported scheme"},
+		{name: "invalidCompression", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionType: "lz4"}, wantErr: "invalid gelf-compression-type"},
+		{name: "invalidLevel", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionLevel: "10"}, wantErr: "invalid gelf-compression-level"},
+		{name: "tcpCompression", opts: map[string]string{gelfAddress: "tcp://127.0.0.1:12201", gelfCompressionType: "gzip"}, wantErr: "not supported with the tcp protocol"},
+		{name: "udpReconnect", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfTCPMaxReconnect: "1"}, wantErr: "only supported with the tcp protocol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseGelfConfig(tt.opts)
+			if tt.wantErr == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGelfUDPChunking(t *testing.T) {
+	for _, compression := range []string{gelfCompressionGzip, gelfCompressionZlib, gelfCompressionNone} {
+		t.Run(compression, func(t *testing.T) {
+			pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+			assert.NilError(t, err)
+			defer pc.Close()
+
+			w, err := newGelfWriter(&gelfConfig{
+				proto:            "udp",
+				address:          pc.LocalAddr().String(),
+				compressionType:  compression,
+				compressionLevel: 0,
+			})
+			assert.NilError(t, err)
+			defer w.Close()
+
+			g := &GelfLogger{fields: map[string]interface{}{"version": "1.1", "_tag": "foo"}}
+			// a level 0 compression keeps the message large enough to be chunked
+			line := strings.Repeat("a", 3*gelfChunkSize)
+			msg, err := g.message(line, gelfLevelError, time.Unix(1, 500000000))
+			assert.NilError(t, err)
+			assert.NilError(t, w.WriteMessage(msg))
+
+			var (
+				data  []byte
+				count int
+			)
+			buf := make([]byte, 2*gelfChunkSize)
+			pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for i := 0; count == 0 || i < count; i++ {
+				n, _, err := pc.ReadFrom(buf)
+				assert.NilError(t, err)
+				assert.Assert(t, n <= gelfChunkSize)
+				assert.Assert(t, bytes.Equal(buf[:2], gelfChunkMagic))
+				assert.Equal(t, int(buf[10]), i)
+				count = int(buf[11])
+				data = append(data, buf[gelfChunkHeaderSize:n]...)
+			}
+			assert.Assert(t, count > 1)
+
+			var r io.Reader = bytes.NewReader(data)
+			switch compression {
+			case gelfCompressionGzip:
+				r, err = gzip.NewReader(r)
+				assert.NilError(t, err)
+			case gelfCompressionZlib:
+				r, err = zlib.NewReader(r)
+				assert.NilError(t, err)
+			}
+			var decoded map[string]interface{}
+			assert.NilError(t, json.NewDecoder(r).Decode(&decoded))
+			assert.Equal(t, decoded["short_message"], line)
+			assert.Equal(t, decoded["_tag"], "foo")
+			assert.Equal(t, decoded["level"], float64(gelfLevelError))
+			assert.Equal(t, decoded["timestamp"], 1.5)
+		})
+	}
+}
+
+func TestContainerMetadataExtraAttributes(t *testing.T) {
+	md := &containerMetadata{
+		ID:     "0123456789ab",
+		Name:   "foo",
+		Labels: map[string]string{"geo": "eu", "other": "x"},
+		Env:    []string{"customer=acme", "PATH=/bin"},
+	}
+	extra := md.ExtraAttributes(map[string]string{Labels: "geo,missing", Env: "customer"})
+	assert.DeepEqual(t, extra, map[string]string{"geo": "eu", "customer": "acme"})
+
+	tag, err := md.Tag(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, tag, "0123456789ab")
+	tag, err = md.Tag(map[string]string{Tag: "{{.Name}}/{{.ID}}"})
+	assert.NilError(t, err)
+	assert.Equal(t, tag, "foo/0123456789ab")
+}
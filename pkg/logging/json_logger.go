@@ -17,16 +17,15 @@
 package logging
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strconv"
 	"time"
 
-	"github.com/docker/go-units"
 	"github.com/fahedouch/go-logrotate"
 	"github.com/fsnotify/fsnotify"
 
@@ -42,6 +41,7 @@ var JSONDriverLogOpts = []string{
 	LogPath,
 	MaxSize,
 	MaxFile,
+	Compress,
 	Env,
 	Labels,
 }
@@ -57,7 +57,7 @@ func JSONFileLogOptsValidate(logOptMap map[string]string) error {
 			log.L.Warnf("log-opt %s is ignored for json-file log driver", key)
 		}
 	}
-	return nil
+	return validateCompressLogOpt(logOptMap, 1)
 }
 
 func (jsonLogger *JSONLogger) Init(dataStore, ns, id string) error {
@@ -86,37 +86,10 @@ func (jsonLogger *JSONLogger) PreProcess(ctx context.Context, dataStore string,
 	} else {
 		jsonFilePath = jsonfile.Path(dataStore, config.Namespace, config.ID)
 	}
-	l := &logrotate.Logger{
-		Filename: jsonFilePath,
-	}
-	// MaxBytes is the maximum size in bytes of the log file before it gets
-	// rotated. If not set, it defaults to 100 MiB.
-	// see: https://github.com/fahedouch/go-logrotate/blob/6a8beddaea39b2b9c77109d7fa2fe92053c063e5/logrotate.go#L500
-	if capacity, ok := jsonLogger.Opts[MaxSize]; ok {
-		var capVal int64
-		var err error
-		capVal, err = units.FromHumanSize(capacity)
-		if err != nil {
-			return err
-		}
-		if capVal <= 0 {
-			return fmt.Errorf("max-size must be a positive number")
-		}
-		l.MaxBytes = capVal
-	}
-	maxFile := 1
-	if maxFileString, ok := jsonLogger.Opts[MaxFile]; ok {
-		var err error
-		maxFile, err = strconv.Atoi(maxFileString)
-		if err != nil {
-			return err
-		}
-		if maxFile < 1 {
-			return fmt.Errorf("max-file cannot be less than 1")
-		}
+	l, err := newRotateLogger(jsonFilePath, jsonLogger.Opts, rotateConfig{maxFile: 1})
+	if err != nil {
+		return err
 	}
-	// MaxBackups does not include file to write logs to
-	l.MaxBackups = maxFile - 1
 	jsonLogger.logger = l
 	return nil
 }
@@ -165,6 +138,30 @@ func viewLogsJSONFileDirect(lvopts LogViewOptions, jsonLogFilePath string, stdou
 		return fmt.Errorf("failed to tail %d lines of JSON logfile %q: %w", lvopts.Tail, jsonLogFilePath, err)
 	}
 
+	// The rotated files, possibly compressed, are read before the current file,
+	// unless the current file already holds the requested tail lines.
+	if start == 0 {
+		readRotated := true
+		var remaining uint
+		if lvopts.Tail > 0 {
+			_, n, err := tailLines(fin, lvopts.Tail)
+			if err != nil {
+				return fmt.Errorf("failed to read JSON logfile %q: %w", jsonLogFilePath, err)
+			}
+			readRotated = n < lvopts.Tail
+			remaining = lvopts.Tail - n
+		}
+		if readRotated {
+			rotated, err := readRotatedLogs(jsonLogFilePath, remaining, tailLines)
+			if err != nil {
+				return fmt.Errorf("failed to read the rotated files of JSON logfile %q: %w", jsonLogFilePath, err)
+			}
+			if _, err := jsonfile.Decode(stdout, stderr, rotated, lvopts.Timestamps, lvopts.Since, lvopts.Until); err != nil {
+				return fmt.Errorf("error occurred while doing read of the rotated files of JSON logfile %q: %w", jsonLogFilePath, err)
+			}
+		}
+	}
+
 	if _, err := fin.Seek(start, io.SeekStart); err != nil {
 		return fmt.Errorf("failed to seek in log file %q from %d position: %w", jsonLogFilePath, start, err)
 	}
@@ -254,3 +251,32 @@ func viewLogsJSONFileDirect(lvopts LogViewOptions, jsonLogFilePath string, stdou
 		}
 	}
 }
+
+// tailLines is the tailFunc of line-oriented log files.
+func tailLines(r io.ReadSeeker, n uint) (int64, uint, error) {
+	start, err := tail.FindTailLineStartIndex(r, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	cnt, err := countLines(r)
+	return start, cnt, err
+}
+
+// countLines returns the number of complete lines in r, from its current position.
+func countLines(r io.Reader) (uint, error) {
+	var cnt uint
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		cnt += uint(bytes.Count(buf[:n], []byte{'\n'}))
+		if err == io.EOF {
+			return cnt, nil
+		}
+		if err != nil {
+			return cnt, err
+		}
+	}
+}
@@ -18,6 +18,7 @@ package logging
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,6 +26,12 @@ import (
 	"runtime"
 	"testing"
 	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/jsonfile"
 )
 
 func TestReadRotatedJSONLog(t *testing.T) {
@@ -175,3 +182,39 @@ func TestReadJSONLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestReadCompressedJSONLogs(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	l := &JSONLogger{Opts: map[string]string{MaxSize: "300", MaxFile: "10", Compress: "true"}}
+	assert.NilError(t, l.Init(dataStore, ns, id))
+	assert.NilError(t, l.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: ns, ID: id}))
+
+	stdout := make(chan string, 10)
+	stderr := make(chan string)
+	var expected string
+	for i := 0; i < 10; i++ {
+		line := fmt.Sprintf("line%d", i)
+		stdout <- line
+		expected += line + "\n"
+	}
+	close(stdout)
+	close(stderr)
+	assert.NilError(t, l.Process(stdout, stderr))
+
+	rotated, err := filepath.Glob(jsonfile.Path(dataStore, ns, id) + ".*.gz")
+	assert.NilError(t, err)
+	assert.Assert(t, len(rotated) > 0)
+
+	var outBuf bytes.Buffer
+	lvopts := LogViewOptions{ContainerID: id, Namespace: ns, DatastoreRootPath: dataStore}
+	assert.NilError(t, viewLogsJSONFile(lvopts, &outBuf, &outBuf, make(chan os.Signal)))
+	assert.Equal(t, outBuf.String(), expected)
+
+	outBuf.Reset()
+	lvopts.Tail = 6
+	assert.NilError(t, viewLogsJSONFile(lvopts, &outBuf, &outBuf, make(chan os.Signal)))
+	assert.Equal(t, outBuf.String(), "line4\nline5\nline6\nline7\nline8\nline9\n")
+
+	assert.ErrorContains(t, JSONFileLogOptsValidate(map[string]string{Compress: "true"}), "compress cannot be true")
+}
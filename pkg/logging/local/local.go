@@ -0,0 +1,302 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package local implements the file format of the "local" logging driver.
+//
+// The logs are stored as a sequence of protobuf-encoded entries, compatible with the
+// LogEntry message of Docker (https://github.com/moby/moby/blob/v28.1.1/api/types/plugins/logdriver/entry.proto).
+// Each entry is framed by its length, encoded as a big-endian uint32, both before and after the message,
+// so that the file can be read backwards to find the tail entries.
+package local
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	timetypes "github.com/docker/docker/api/types/time"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// frameSize is the size of the length fields framing each entry
+	frameSize = 4
+	// maxMsgLen is the maximum size of a single entry, larger sizes are reported as a corrupted file
+	maxMsgLen = 64 << 20
+)
+
+// Field numbers of the LogEntry message
+const (
+	fieldSource   protowire.Number = 1
+	fieldTimeNano protowire.Number = 2
+	fieldLine     protowire.Number = 3
+	fieldPartial  protowire.Number = 4
+)
+
+// ErrCorrupted is returned when the log file cannot be decoded.
+var ErrCorrupted = errors.New("corrupted local log file")
+
+// Entry is a log entry, compatible with the LogEntry message of Docker.
+type Entry struct {
+	// Source is "stdout" or "stderr"
+	Source   string
+	TimeNano int64
+	// Line does not include the trailing newline
+	Line    []byte
+	Partial bool
+}
+
+// Path returns the path of the log file of a container.
+func Path(dataStore, ns, id string) string {
+	// the file name corresponds to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "local-logs", "container.log")
+}
+
+// Marshal returns the framed encoding of e.
+func (e *Entry) Marshal() []byte {
+	b := make([]byte, frameSize, frameSize+len(e.Source)+len(e.Line)+32)
+	b = protowire.AppendTag(b, fieldSource, protowire.BytesType)
+	b = protowire.AppendString(b, e.Source)
+	b = protowire.AppendTag(b, fieldTimeNano, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(e.TimeNano))
+	b = protowire.AppendTag(b, fieldLine, protowire.BytesType)
+	b = protowire.AppendBytes(b, e.Line)
+	if e.Partial {
+		b = protowire.AppendTag(b, fieldPartial, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	msgLen := uint32(len(b) - frameSize)
+	binary.BigEndian.PutUint32(b, msgLen)
+	return binary.BigEndian.AppendUint32(b, msgLen)
+}
+
+// Unmarshal decodes the message of a single entry, without the framing.
+func (e *Entry) Unmarshal(b []byte) error {
+	*e = Entry{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case num == fieldSource && typ == protowire.BytesType:
+			var v string
+			v, n = protowire.ConsumeString(b)
+			e.Source = v
+		case num == fieldTimeNano && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			e.TimeNano = int64(v)
+		case num == fieldLine && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			e.Line = append([]byte(nil), v...)
+		case num == fieldPartial && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			e.Partial = protowire.DecodeBool(v)
+		default:
+			// ignore unknown fields
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// Encode writes the lines received on stdout and stderr to writer.
+// Each entry is written with a single call to writer.Write, so that it is never split by a rotation.
+func Encode(stdout <-chan string, stderr <-chan string, writer io.Writer) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(2)
+	f := func(dataChan <-chan string, name string) {
+		defer wg.Done()
+		e := &Entry{
+			Source: name,
+		}
+		for logEntry := range dataChan {
+			e.Line = []byte(logEntry)
+			e.TimeNano = time.Now().UnixNano()
+			b := e.Marshal()
+			mu.Lock()
+			_, err := writer.Write(b)
+			mu.Unlock()
+			if err != nil {
+				log.L.WithError(err).Errorf("failed to write log entry")
+				return
+			}
+		}
+	}
+	go f(stdout, "stdout")
+	go f(stderr, "stderr")
+	wg.Wait()
+	return nil
+}
+
+// ReadEntry reads a single framed entry from r.
+// io.EOF is returned if r has no more data. If r ends in the middle of an entry,
+// the bytes already read are returned along with io.ErrUnexpectedEOF.
+func ReadEntry(r io.Reader, e *Entry) ([]byte, error) {
+	header := make([]byte, frameSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		return header[:n], err
+	}
+	msgLen := binary.BigEndian.Uint32(header)
+	if msgLen > maxMsgLen {
+		return nil, fmt.Errorf("%w: entry of %d bytes", ErrCorrupted, msgLen)
+	}
+	buf := make([]byte, frameSize+int(msgLen)+frameSize)
+	copy(buf, header)
+	if n, err := io.ReadFull(r, buf[frameSize:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return buf[:frameSize+n], err
+	}
+	if trailer := binary.BigEndian.Uint32(buf[frameSize+int(msgLen):]); trailer != msgLen {
+		return nil, fmt.Errorf("%w: mismatched entry lengths %d and %d", ErrCorrupted, msgLen, trailer)
+	}
+	if err := e.Unmarshal(buf[frameSize : frameSize+int(msgLen)]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	return nil, nil
+}
+
+// FindTailEntryStartIndex returns the offset of the last n entries of f, and the number of entries found.
+// If n is 0, the beginning of the file is returned.
+func FindTailEntryStartIndex(f io.ReadSeeker, n uint) (int64, uint, error) {
+	if n == 0 {
+		return 0, 0, nil
+	}
+	pos, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	var cnt uint
+	buf := make([]byte, frameSize)
+	for pos > 0 && cnt < n {
+		if pos < 2*frameSize {
+			return 0, 0, fmt.Errorf("%w: truncated entry at offset %d", ErrCorrupted, pos)
+		}
+		if _, err := f.Seek(pos-frameSize, io.SeekStart); err != nil {
+			return 0, 0, err
+		}
+		if _, err := io.ReadFull(f, buf); err != nil {
+			return 0, 0, err
+		}
+		msgLen := int64(binary.BigEndian.Uint32(buf))
+		start := pos - 2*frameSize - msgLen
+		if start < 0 {
+			return 0, 0, fmt.Errorf("%w: truncated entry at offset %d", ErrCorrupted, pos)
+		}
+		pos = start
+		cnt++
+	}
+	return pos, cnt, nil
+}
+
+// Decode writes the entries read from r to stdout and stderr, until r has no more data.
+// If r ends in the middle of an entry, the bytes of the partial entry are returned along with an error,
+// so that the caller can retry once the entry is complete.
+func Decode(stdout, stderr io.Writer, r io.Reader, timestamps bool, since string, until string) ([]byte, error) {
+	now := time.Now()
+	var sinceTime, untilTime time.Time
+	if since != "" {
+		t, err := parseTime(since, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for \"since\": %w", err)
+		}
+		sinceTime = t
+	}
+	if until != "" {
+		t, err := parseTime(until, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for \"until\": %w", err)
+		}
+		untilTime = t
+	}
+
+	br := bufio.NewReader(r)
+	var e Entry
+	for {
+		partial, err := ReadEntry(br, &e)
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return partial, err
+		}
+		t := time.Unix(0, e.TimeNano)
+		if !sinceTime.IsZero() && t.Before(sinceTime) {
+			continue
+		}
+		if !untilTime.IsZero() && t.After(untilTime) {
+			continue
+		}
+		if err := writeEntry(&e, t, stdout, stderr, timestamps); err != nil {
+			log.L.WithError(err).Errorf("error while writing log entry to output stream")
+		}
+	}
+}
+
+func writeEntry(e *Entry, t time.Time, stdout, stderr io.Writer, timestamps bool) error {
+	var output []byte
+	if timestamps {
+		output = append(output, t.UTC().Format(time.RFC3339Nano)...)
+		output = append(output, ' ')
+	}
+	output = append(output, e.Line...)
+	if !e.Partial {
+		output = append(output, '\n')
+	}
+
+	var writeTo io.Writer
+	switch e.Source {
+	case "stdout":
+		writeTo = stdout
+	case "stderr":
+		writeTo = stderr
+	default:
+		log.L.Errorf("unknown stream name %q", e.Source)
+		return nil
+	}
+	_, err := writeTo.Write(output)
+	return err
+}
+
+func parseTime(value string, refTime time.Time) (time.Time, error) {
+	ts, err := timetypes.GetTimestamp(value, refTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
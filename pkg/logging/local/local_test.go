@@ -0,0 +1,119 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package local
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEntryMarshal(t *testing.T) {
+	e := Entry{Source: "stderr", TimeNano: 1720753764916296732, Line: []byte("foo"), Partial: true}
+	b := e.Marshal()
+
+	var decoded Entry
+	partial, err := ReadEntry(bytes.NewReader(b), &decoded)
+	assert.NilError(t, err)
+	assert.Assert(t, partial == nil)
+	assert.DeepEqual(t, decoded, e)
+
+	_, err = ReadEntry(bytes.NewReader(nil), &decoded)
+	assert.ErrorIs(t, err, io.EOF)
+	partial, err = ReadEntry(bytes.NewReader(b[:len(b)-1]), &decoded)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.DeepEqual(t, partial, b[:len(b)-1])
+
+	corrupted := append([]byte(nil), b...)
+	corrupted[len(corrupted)-1]++
+	_, err = ReadEntry(bytes.NewReader(corrupted), &decoded)
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func writeEntries(t *testing.T, entries ...Entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(e.Marshal())
+	}
+	return buf.Bytes()
+}
+
+func TestFindTailEntryStartIndex(t *testing.T) {
+	data := writeEntries(t,
+		Entry{Source: "stdout", Line: []byte("line1")},
+		Entry{Source: "stdout", Line: []byte("line2")},
+		Entry{Source: "stdout", Line: []byte("line3")},
+	)
+	entryLen := int64(len(data) / 3)
+
+	testCases := []struct {
+		n             uint
+		expectedStart int64
+		expectedCount uint
+	}{
+		{n: 0, expectedStart: 0, expectedCount: 0},
+		{n: 1, expectedStart: 2 * entryLen, expectedCount: 1},
+		{n: 3, expectedStart: 0, expectedCount: 3},
+		{n: 5, expectedStart: 0, expectedCount: 3},
+	}
+	for _, tc := range testCases {
+		start, cnt, err := FindTailEntryStartIndex(bytes.NewReader(data), tc.n)
+		assert.NilError(t, err)
+		assert.Equal(t, start, tc.expectedStart)
+		assert.Equal(t, cnt, tc.expectedCount)
+	}
+
+	_, _, err := FindTailEntryStartIndex(bytes.NewReader(data[1:]), 5)
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestDecode(t *testing.T) {
+	base := time.Date(2024, 7, 12, 3, 9, 24, 0, time.UTC)
+	last := Entry{Source: "stdout", TimeNano: base.Add(2 * time.Second).UnixNano(), Line: []byte("continued")}
+	data := writeEntries(t,
+		Entry{Source: "stdout", TimeNano: base.UnixNano(), Line: []byte("line1")},
+		Entry{Source: "stderr", TimeNano: base.Add(time.Second).UnixNano(), Line: []byte("line2")},
+		Entry{Source: "stdout", TimeNano: base.Add(2 * time.Second).UnixNano(), Line: []byte("line3 "), Partial: true},
+		last,
+	)
+
+	var stdout, stderr bytes.Buffer
+	partial, err := Decode(&stdout, &stderr, bytes.NewReader(data), false, "", "")
+	assert.NilError(t, err)
+	assert.Assert(t, partial == nil)
+	assert.Equal(t, stdout.String(), "line1\nline3 continued\n")
+	assert.Equal(t, stderr.String(), "line2\n")
+
+	stdout.Reset()
+	stderr.Reset()
+	_, err = Decode(&stdout, &stderr, bytes.NewReader(data), true, "2024-07-12T03:09:25Z", "2024-07-12T03:09:25.5Z")
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "")
+	assert.Equal(t, stderr.String(), "2024-07-12T03:09:25Z line2\n")
+
+	// an entry being written is returned to the caller
+	stdout.Reset()
+	stderr.Reset()
+	partial, err = Decode(&stdout, &stderr, bytes.NewReader(data[:len(data)-3]), false, "", "")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, len(partial), len(last.Marshal())-3)
+	assert.Equal(t, stdout.String(), "line1\nline3 ")
+}
@@ -0,0 +1,191 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fahedouch/go-logrotate"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+var LocalLogOpts = []string{
+	MaxSize,
+	MaxFile,
+	Compress,
+}
+
+// Docker-compatible defaults of the local driver
+var localRotateDefaults = rotateConfig{
+	maxSize:  "20m",
+	maxFile:  5,
+	compress: true,
+}
+
+// LocalLogger writes the logs in a compact binary format, rotated and compressed by default.
+type LocalLogger struct {
+	Opts   map[string]string
+	logger *logrotate.Logger
+}
+
+func LocalLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(LocalLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for local log driver", key)
+		}
+	}
+	_, err := newRotateLogger("", logOptMap, localRotateDefaults)
+	return err
+}
+
+func (localLogger *LocalLogger) Init(dataStore, ns, id string) error {
+	logFilePath := local.Path(dataStore, ns, id)
+	if err := os.MkdirAll(filepath.Dir(logFilePath), 0700); err != nil {
+		return err
+	}
+	if _, err := os.Stat(logFilePath); errors.Is(err, os.ErrNotExist) {
+		if writeErr := os.WriteFile(logFilePath, []byte{}, 0600); writeErr != nil {
+			return writeErr
+		}
+	}
+	return nil
+}
+
+func (localLogger *LocalLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	l, err := newRotateLogger(local.Path(dataStore, config.Namespace, config.ID), localLogger.Opts, localRotateDefaults)
+	if err != nil {
+		return err
+	}
+	localLogger.logger = l
+	return nil
+}
+
+func (localLogger *LocalLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	return local.Encode(stdout, stderr, localLogger.logger)
+}
+
+func (localLogger *LocalLogger) PostProcess() error {
+	return localLogger.logger.Close()
+}
+
+// viewLogsLocal loads log entries from the files produced by the local driver, including the rotated ones,
+// and forwards them to the provided io.Writers after applying the provided logging options.
+func viewLogsLocal(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	logFilePath := local.Path(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	fin, err := os.OpenFile(logFilePath, os.O_RDONLY, 0400)
+	if err != nil {
+		return fmt.Errorf("failed to open local log file: %w", err)
+	}
+	defer func() { fin.Close() }()
+
+	start, n, err := local.FindTailEntryStartIndex(fin, lvopts.Tail)
+	if err != nil {
+		return fmt.Errorf("failed to tail %d entries of local log file %q: %w", lvopts.Tail, logFilePath, err)
+	}
+	// The rotated files are read before the current file, unless the current file already holds the requested tail entries.
+	if start == 0 && (lvopts.Tail == 0 || n < lvopts.Tail) {
+		var remaining uint
+		if lvopts.Tail > 0 {
+			remaining = lvopts.Tail - n
+		}
+		rotated, err := readRotatedLogs(logFilePath, remaining, local.FindTailEntryStartIndex)
+		if err != nil {
+			return fmt.Errorf("failed to read the rotated files of local log file %q: %w", logFilePath, err)
+		}
+		if _, err := local.Decode(stdout, stderr, rotated, lvopts.Timestamps, lvopts.Since, lvopts.Until); err != nil {
+			return fmt.Errorf("error occurred while doing read of the rotated files of local log file %q: %w", logFilePath, err)
+		}
+	}
+	if _, err := fin.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek in local log file %q from %d position: %w", logFilePath, start, err)
+	}
+
+	var watcher *fsnotify.Watcher
+	baseName := filepath.Base(logFilePath)
+	retryTimes := 2
+	for {
+		select {
+		case <-stopChannel:
+			log.L.Debug("received stop signal while re-reading local logfile, returning")
+			return nil
+		default:
+		}
+
+		backBytes := 0
+		if partial, err := local.Decode(stdout, stderr, fin, lvopts.Timestamps, lvopts.Since, lvopts.Until); err != nil {
+			if !errors.Is(err, io.ErrUnexpectedEOF) || retryTimes == 0 {
+				return fmt.Errorf("error occurred while doing read of local logfile %q: %w", logFilePath, err)
+			}
+			// the entry is being written, read it again
+			time.Sleep(5 * time.Millisecond)
+			retryTimes--
+			backBytes = len(partial)
+		} else {
+			retryTimes = 2
+		}
+
+		if !lvopts.Follow {
+			return nil
+		}
+		if _, err := fin.Seek(int64(-backBytes), io.SeekCurrent); err != nil {
+			return fmt.Errorf("error occurred while trying to seek local logfile %q: %w", logFilePath, err)
+		}
+		if backBytes > 0 {
+			continue
+		}
+		if watcher == nil {
+			if watcher, err = NewLogFileWatcher(filepath.Dir(logFilePath)); err != nil {
+				return err
+			}
+			defer watcher.Close()
+			// try again to read as we might have missed the event
+			continue
+		}
+		// Wait until the next log change.
+		recreated, err := startTail(context.Background(), baseName, watcher)
+		if err != nil {
+			return err
+		}
+		if recreated {
+			newF, err := openFileShareDelete(logFilePath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					// the log file has just been rotated, try opening it once more
+					time.Sleep(10 * time.Millisecond)
+				}
+				newF, err = openFileShareDelete(logFilePath)
+				if err != nil {
+					return fmt.Errorf("failed to open local logfile %q: %w", logFilePath, err)
+				}
+			}
+			fin.Close()
+			fin = newF
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+)
+
+func TestLocalLogOptsValidate(t *testing.T) {
+	assert.NilError(t, LocalLogOptsValidate(map[string]string{MaxSize: "10m", MaxFile: "3"}))
+	assert.NilError(t, LocalLogOptsValidate(map[string]string{MaxFile: "1", Compress: "false"}))
+	assert.ErrorContains(t, LocalLogOptsValidate(map[string]string{MaxFile: "1"}), "compress cannot be true")
+	assert.ErrorContains(t, LocalLogOptsValidate(map[string]string{MaxSize: "0"}), "max-size must be a positive number")
+}
+
+func TestLocalLogger(t *testing.T) {
+	dataStore := t.TempDir()
+	ns, id := "default", "0123456789abcdef0123456789abcdef"
+	// every entry is 35 bytes, so that the file is rotated every 5 entries
+	l := &LocalLogger{Opts: map[string]string{MaxSize: "175", MaxFile: "10"}}
+	assert.NilError(t, l.Init(dataStore, ns, id))
+	assert.NilError(t, l.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: ns, ID: id}))
+
+	stdout := make(chan string, 12)
+	stderr := make(chan string)
+	var expected []string
+	for i := 0; i < 12; i++ {
+		line := fmt.Sprintf("line%02d", i)
+		stdout <- line
+		expected = append(expected, line+"\n")
+	}
+	close(stdout)
+	close(stderr)
+	assert.NilError(t, l.Process(stdout, stderr))
+	assert.NilError(t, l.PostProcess())
+
+	rotated, err := filepath.Glob(local.Path(dataStore, ns, id) + ".*.gz")
+	assert.NilError(t, err)
+	assert.Equal(t, len(rotated), 2)
+
+	testCases := []struct {
+		tail     uint
+		expected []string
+	}{
+		{tail: 0, expected: expected},
+		{tail: 1, expected: expected[11:]},
+		{tail: 7, expected: expected[5:]},
+		{tail: 20, expected: expected},
+	}
+	for _, tc := range testCases {
+		var outBuf, errBuf bytes.Buffer
+		lvopts := LogViewOptions{ContainerID: id, Namespace: ns, DatastoreRootPath: dataStore, Tail: tc.tail}
+		assert.NilError(t, viewLogsLocal(lvopts, &outBuf, &errBuf, make(chan os.Signal)))
+		assert.Equal(t, outBuf.String(), strings.Join(tc.expected, ""), "tail=%d", tc.tail)
+		assert.Equal(t, errBuf.String(), "")
+	}
+}
@@ -45,6 +45,7 @@ func RegisterLogViewer(driverName string, lvfn LogViewerFunc) {
 
 func init() {
 	RegisterLogViewer("json-file", viewLogsJSONFile)
+	RegisterLogViewer("local", viewLogsLocal)
 	RegisterLogViewer("journald", viewLogsJournald)
 	RegisterLogViewer("cri", viewLogsCRI)
 }
@@ -88,6 +89,18 @@ type LogViewOptions struct {
 
 	// DetailPrefix is the prefix added when Details is enabled.
 	DetailPrefix *string
+
+	// ContainerName is the name of the container, shown in the "json" format.
+	ContainerName string
+
+	// Grep is a regular expression that the lines have to match to be shown. Empty = all.
+	Grep string
+
+	// Stream is the only stream to show, "stdout" or "stderr". Empty = both.
+	Stream string
+
+	// Format is the output format, FormatRaw or FormatJSON.
+	Format string
 }
 
 func (lvo *LogViewOptions) Validate() error {
@@ -95,6 +108,18 @@ func (lvo *LogViewOptions) Validate() error {
 		return fmt.Errorf("log viewing options require a ContainerID and Namespace: %#v", lvo)
 	}
 
+	switch lvo.Stream {
+	case "", "stdout", "stderr":
+	default:
+		return fmt.Errorf("invalid stream %q, must be \"stdout\" or \"stderr\"", lvo.Stream)
+	}
+
+	switch lvo.Format {
+	case FormatRaw, FormatJSON:
+	default:
+		return fmt.Errorf("invalid log format %q, only %q is supported", lvo.Format, FormatJSON)
+	}
+
 	if lvo.DatastoreRootPath == "" || !filepath.IsAbs(lvo.DatastoreRootPath) {
 		abs, err := filepath.Abs(lvo.DatastoreRootPath)
 		if err != nil {
@@ -156,20 +181,42 @@ func InitContainerLogViewer(containerLabels map[string]string, lvopts LogViewOpt
 
 // Prints all logs for this LogViewer's containers to the provided io.Writers.
 func (lv *ContainerLogViewer) PrintLogsTo(stdout, stderr io.Writer) error {
-	if lv.logViewingOptions.Details {
-		if lv.logViewingOptions.DetailPrefix != nil {
-			prefix := *lv.logViewingOptions.DetailPrefix + " "
+	lvopts := lv.logViewingOptions
+	if lvopts.Details && lvopts.Format != FormatJSON {
+		if lvopts.DetailPrefix != nil {
+			prefix := *lvopts.DetailPrefix + " "
 			stdout = NewDetailWriter(stdout, prefix)
 			stderr = NewDetailWriter(stderr, prefix)
 		}
 
 	}
-	viewerFunc, err := getLogViewer(lv.loggingConfig.Driver)
+	var viewerFunc LogViewerFunc
+	if cacheViewable(lv.loggingConfig, lvopts) {
+		viewerFunc = viewLogsCache
+	} else {
+		var err error
+		viewerFunc, err = getLogViewer(lv.loggingConfig.Driver)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !needsFilterWriters(lvopts) {
+		return viewerFunc(lvopts, stdout, stderr, lv.stopChannel)
+	}
+	viewerOpts := lvopts
+	if lvopts.Format == FormatJSON && lv.loggingConfig.Driver != "journald" {
+		// the records carry the timestamps of the lines
+		viewerOpts.Timestamps = true
+	}
+	fw, err := newFilterWriters(stdout, stderr, lvopts, viewerOpts.Timestamps)
 	if err != nil {
 		return err
 	}
-
-	return viewerFunc(lv.logViewingOptions, stdout, stderr, lv.stopChannel)
+	if err := viewerFunc(viewerOpts, fw.stdout, fw.stderr, lv.stopChannel); err != nil {
+		return err
+	}
+	return fw.Flush()
 }
 
 // Convenience wrapper for exec.LookPath.
@@ -47,6 +47,7 @@ const (
 	LogPath    = "log-path"
 	MaxSize    = "max-size"
 	MaxFile    = "max-file"
+	Compress   = "compress"
 	Tag        = "tag"
 	Env        = "env"
 	Labels     = "labels"
@@ -66,6 +67,11 @@ var drivers = make(map[string]DriverFactory)
 var driversLogOptsValidateFunctions = make(map[string]LogOptsValidateFunc)
 
 func ValidateLogOpts(logDriver string, logOpts map[string]string) error {
+	if _, ok := dualLoggingDrivers[logDriver]; ok {
+		if err := validateCacheLogOpts(logOpts); err != nil {
+			return err
+		}
+	}
 	if value, ok := driversLogOptsValidateFunctions[logDriver]; ok && value != nil {
 		return value(logOpts)
 	}
@@ -91,7 +97,14 @@ func GetDriver(name string, opts map[string]string, address string) (Driver, err
 	if !ok {
 		return nil, fmt.Errorf("unknown logging driver %q: %w", name, errdefs.ErrNotFound)
 	}
-	return driverFactory(opts, address)
+	driver, err := driverFactory(opts, address)
+	if err != nil {
+		return nil, err
+	}
+	if cacheEnabled(name, opts) {
+		driver = newDualLogger(driver, opts)
+	}
+	return driver, nil
 }
 
 func init() {
@@ -101,6 +114,9 @@ func init() {
 	RegisterDriver("json-file", func(opts map[string]string, address string) (Driver, error) {
 		return &JSONLogger{Opts: opts}, nil
 	}, JSONFileLogOptsValidate)
+	RegisterDriver("local", func(opts map[string]string, address string) (Driver, error) {
+		return &LocalLogger{Opts: opts}, nil
+	}, LocalLogOptsValidate)
 	RegisterDriver("journald", func(opts map[string]string, address string) (Driver, error) {
 		return &JournaldLogger{Opts: opts, Address: address}, nil
 	}, JournalLogOptsValidate)
@@ -110,6 +126,12 @@ func init() {
 	RegisterDriver("syslog", func(opts map[string]string, address string) (Driver, error) {
 		return &SyslogLogger{Opts: opts}, nil
 	}, SyslogOptsValidate)
+	RegisterDriver("gelf", func(opts map[string]string, address string) (Driver, error) {
+		return &GelfLogger{Opts: opts, Address: address}, nil
+	}, GelfLogOptsValidate)
+	RegisterDriver("otlp", func(opts map[string]string, address string) (Driver, error) {
+		return &OTLPLogger{Opts: opts, Address: address}, nil
+	}, OTLPLogOptsValidate)
 }
 
 // Main is the entrypoint for the containerd runtime v2 logging plugin mode.
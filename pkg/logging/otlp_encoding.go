@@ -0,0 +1,140 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+// The OTLP messages are encoded with protowire, following
+// https://github.com/open-telemetry/opentelemetry-proto/blob/v1.5.0/opentelemetry/proto/logs/v1/logs.proto ,
+// so that the driver does not depend on the OpenTelemetry SDK.
+
+// Field numbers of the OTLP messages
+const (
+	// ExportLogsServiceRequest
+	otlpFieldResourceLogs protowire.Number = 1
+	// ResourceLogs
+	otlpFieldResource  protowire.Number = 1
+	otlpFieldScopeLogs protowire.Number = 2
+	// Resource
+	otlpFieldResourceAttributes protowire.Number = 1
+	// ScopeLogs
+	otlpFieldScope      protowire.Number = 1
+	otlpFieldLogRecords protowire.Number = 2
+	// InstrumentationScope
+	otlpFieldScopeName    protowire.Number = 1
+	otlpFieldScopeVersion protowire.Number = 2
+	// LogRecord
+	otlpFieldTimeUnixNano         protowire.Number = 1
+	otlpFieldSeverityNumber       protowire.Number = 2
+	otlpFieldSeverityText         protowire.Number = 3
+	otlpFieldBody                 protowire.Number = 5
+	otlpFieldAttributes           protowire.Number = 6
+	otlpFieldObservedTimeUnixNano protowire.Number = 11
+	// KeyValue
+	otlpFieldKey   protowire.Number = 1
+	otlpFieldValue protowire.Number = 2
+	// AnyValue
+	otlpFieldStringValue protowire.Number = 1
+)
+
+// Severity numbers of the OTLP log records
+const (
+	otlpSeverityInfo  = 9
+	otlpSeverityError = 17
+)
+
+// otlpLogRecord is a log line to be exported.
+type otlpLogRecord struct {
+	Time time.Time
+	Body string
+	// Stream is "stdout" or "stderr"
+	Stream string
+}
+
+// encodeOTLPLogsRequest returns an ExportLogsServiceRequest carrying the records of a single resource.
+func encodeOTLPLogsRequest(resource map[string]string, records []otlpLogRecord) []byte {
+	var res []byte
+	for _, k := range sortedKeys(resource) {
+		res = appendOTLPMessage(res, otlpFieldResourceAttributes, encodeOTLPKeyValue(k, resource[k]))
+	}
+
+	var scope []byte
+	scope = appendOTLPString(scope, otlpFieldScopeName, "nerdctl")
+	scope = appendOTLPString(scope, otlpFieldScopeVersion, version.GetVersion())
+
+	var scopeLogs []byte
+	scopeLogs = appendOTLPMessage(scopeLogs, otlpFieldScope, scope)
+	for _, r := range records {
+		scopeLogs = appendOTLPMessage(scopeLogs, otlpFieldLogRecords, encodeOTLPLogRecord(r))
+	}
+
+	var resourceLogs []byte
+	resourceLogs = appendOTLPMessage(resourceLogs, otlpFieldResource, res)
+	resourceLogs = appendOTLPMessage(resourceLogs, otlpFieldScopeLogs, scopeLogs)
+
+	return appendOTLPMessage(nil, otlpFieldResourceLogs, resourceLogs)
+}
+
+func encodeOTLPLogRecord(r otlpLogRecord) []byte {
+	severity, severityText := uint64(otlpSeverityInfo), "INFO"
+	if r.Stream == "stderr" {
+		severity, severityText = otlpSeverityError, "ERROR"
+	}
+	var b []byte
+	b = protowire.AppendTag(b, otlpFieldTimeUnixNano, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(r.Time.UnixNano()))
+	b = protowire.AppendTag(b, otlpFieldSeverityNumber, protowire.VarintType)
+	b = protowire.AppendVarint(b, severity)
+	b = appendOTLPString(b, otlpFieldSeverityText, severityText)
+	b = appendOTLPMessage(b, otlpFieldBody, appendOTLPString(nil, otlpFieldStringValue, r.Body))
+	b = appendOTLPMessage(b, otlpFieldAttributes, encodeOTLPKeyValue("log.iostream", r.Stream))
+	b = protowire.AppendTag(b, otlpFieldObservedTimeUnixNano, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(r.Time.UnixNano()))
+	return b
+}
+
+func encodeOTLPKeyValue(key, value string) []byte {
+	var b []byte
+	b = appendOTLPString(b, otlpFieldKey, key)
+	return appendOTLPMessage(b, otlpFieldValue, appendOTLPString(nil, otlpFieldStringValue, value))
+}
+
+func appendOTLPString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendOTLPMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
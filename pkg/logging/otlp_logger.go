@@ -0,0 +1,540 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	otlpEndpoint     = "otlp-endpoint"
+	otlpProtocol     = "otlp-protocol"
+	otlpInsecure     = "otlp-insecure"
+	otlpHeaders      = "otlp-headers"
+	otlpTimeout      = "otlp-timeout"
+	otlpBatchSize    = "otlp-batch-size"
+	otlpBatchTimeout = "otlp-batch-timeout"
+	otlpBufferSize   = "otlp-buffer-size"
+)
+
+var OTLPLogOpts = []string{
+	otlpEndpoint,
+	otlpProtocol,
+	otlpInsecure,
+	otlpHeaders,
+	otlpTimeout,
+	otlpBatchSize,
+	otlpBatchTimeout,
+	otlpBufferSize,
+	Tag,
+	Labels,
+	Env,
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	Mode,
+	MaxBufferSize,
+}
+
+const (
+	otlpProtocolGRPC         = "grpc"
+	otlpProtocolHTTPProtobuf = "http/protobuf"
+
+	// Defaults, compatible with the OpenTelemetry SDK environment variables
+	otlpDefaultGRPCEndpoint = "localhost:4317"
+	otlpDefaultHTTPEndpoint = "http://localhost:4318"
+	otlpDefaultHTTPPath     = "/v1/logs"
+	otlpDefaultTimeout      = 10 * time.Second
+	otlpDefaultBatchSize    = 512
+	otlpDefaultBatchTimeout = time.Second
+	otlpDefaultBufferSize   = 8192
+
+	// Retry parameters of the exports failing with a transient error
+	otlpMinRetryBackoff = time.Second
+	otlpMaxRetryBackoff = 30 * time.Second
+	// otlpFinalAttempts is the number of attempts to export the remaining records when the container exits
+	otlpFinalAttempts = 3
+
+	otlpGRPCExportMethod = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+)
+
+func OTLPLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(OTLPLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for otlp log driver", key)
+		}
+	}
+	_, err := parseOTLPConfig(logOptMap)
+	return err
+}
+
+type otlpConfig struct {
+	protocol     string
+	endpoint     string
+	insecure     bool
+	headers      map[string]string
+	timeout      time.Duration
+	batchSize    int
+	batchTimeout time.Duration
+	bufferSize   int
+}
+
+func parseOTLPConfig(opts map[string]string) (*otlpConfig, error) {
+	cfg := &otlpConfig{
+		protocol:     otlpProtocolGRPC,
+		headers:      make(map[string]string),
+		timeout:      otlpDefaultTimeout,
+		batchSize:    otlpDefaultBatchSize,
+		batchTimeout: otlpDefaultBatchTimeout,
+		bufferSize:   otlpDefaultBufferSize,
+	}
+	var err error
+	if v, ok := opts[otlpProtocol]; ok {
+		switch v {
+		case otlpProtocolGRPC, otlpProtocolHTTPProtobuf:
+			cfg.protocol = v
+		default:
+			return nil, fmt.Errorf("invalid %s %q, must be %q or %q", otlpProtocol, v, otlpProtocolGRPC, otlpProtocolHTTPProtobuf)
+		}
+	}
+	if v, ok := opts[otlpInsecure]; ok {
+		cfg.insecure, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", otlpInsecure, v, err)
+		}
+	}
+	cfg.endpoint, err = parseOTLPEndpoint(cfg, opts[otlpEndpoint])
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := opts[otlpHeaders]; ok && v != "" {
+		for _, kv := range strings.Split(v, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("invalid %s %q, must be a comma-separated list of key=value", otlpHeaders, kv)
+			}
+			cfg.headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	for key, d := range map[string]*time.Duration{otlpTimeout: &cfg.timeout, otlpBatchTimeout: &cfg.batchTimeout} {
+		if v, ok := opts[key]; ok {
+			*d, err = time.ParseDuration(v)
+			if err != nil || *d <= 0 {
+				return nil, fmt.Errorf("invalid %s %q, must be a positive duration", key, v)
+			}
+		}
+	}
+	for key, n := range map[string]*int{otlpBatchSize: &cfg.batchSize, otlpBufferSize: &cfg.bufferSize} {
+		if v, ok := opts[key]; ok {
+			*n, err = strconv.Atoi(v)
+			if err != nil || *n <= 0 {
+				return nil, fmt.Errorf("invalid %s %q, must be a positive integer", key, v)
+			}
+		}
+	}
+	if cfg.bufferSize < cfg.batchSize {
+		return nil, fmt.Errorf("%s (%d) must not be smaller than %s (%d)", otlpBufferSize, cfg.bufferSize, otlpBatchSize, cfg.batchSize)
+	}
+	return cfg, nil
+}
+
+// parseOTLPEndpoint returns the "host:port" target of the gRPC transport, or the URL of the HTTP transport.
+// Like the OpenTelemetry SDK, the "http" scheme of a gRPC endpoint disables TLS.
+func parseOTLPEndpoint(cfg *otlpConfig, endpoint string) (string, error) {
+	if cfg.protocol == otlpProtocolGRPC {
+		if endpoint == "" {
+			return otlpDefaultGRPCEndpoint, nil
+		}
+		if !strings.Contains(endpoint, "://") {
+			return endpoint, nil
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", err
+		}
+		switch u.Scheme {
+		case "http":
+			cfg.insecure = true
+		case "https":
+		default:
+			return "", fmt.Errorf("unsupported scheme: '%s', must be http or https", u.Scheme)
+		}
+		return u.Host, nil
+	}
+
+	if endpoint == "" {
+		endpoint = otlpDefaultHTTPEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme: '%s', must be http or https", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpDefaultHTTPPath
+	}
+	return u.String(), nil
+}
+
+// OTLPLogger ships the log lines as OpenTelemetry log records to an OTLP endpoint, typically a local collector.
+// The records are exported in batches. The batches failing with a transient error are retried with a backoff,
+// while the new records are buffered in memory, up to otlp-buffer-size records (the oldest ones are dropped).
+type OTLPLogger struct {
+	Opts     map[string]string
+	Address  string
+	batcher  *otlpBatcher
+	exporter otlpExporter
+}
+
+func (o *OTLPLogger) Init(dataStore, ns, id string) error {
+	return nil
+}
+
+func (o *OTLPLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	cfg, err := parseOTLPConfig(o.Opts)
+	if err != nil {
+		return err
+	}
+	md, err := loadContainerMetadata(ctx, o.Address, config)
+	if err != nil {
+		return err
+	}
+	resource, err := otlpResourceAttributes(md, o.Opts)
+	if err != nil {
+		return err
+	}
+	o.exporter, err = newOTLPExporter(cfg)
+	if err != nil {
+		return err
+	}
+	o.batcher = &otlpBatcher{
+		exporter:     o.exporter,
+		resource:     resource,
+		timeout:      cfg.timeout,
+		batchSize:    cfg.batchSize,
+		batchTimeout: cfg.batchTimeout,
+		bufferSize:   cfg.bufferSize,
+	}
+	return nil
+}
+
+// otlpResourceAttributes returns the attributes of the resource producing the logs, following the
+// OpenTelemetry semantic conventions for containers where applicable.
+func otlpResourceAttributes(md *containerMetadata, opts map[string]string) (map[string]string, error) {
+	tag, err := md.Tag(opts)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]string)
+	for k, v := range md.ExtraAttributes(opts) {
+		attrs[k] = v
+	}
+	for k, v := range map[string]string{
+		"service.name":         md.Name,
+		"container.id":         md.FullID,
+		"container.name":       md.Name,
+		"container.image.name": md.ImageName,
+		"container.runtime":    "containerd",
+		"containerd.namespace": md.Namespace,
+		"nerdctl.tag":          tag,
+	} {
+		attrs[k] = v
+	}
+	if project, ok := md.Labels[labels.ComposeProject]; ok {
+		attrs["compose.project"] = project
+	}
+	if service, ok := md.Labels[labels.ComposeService]; ok {
+		attrs["compose.service"] = service
+		attrs["service.name"] = service
+	}
+	return attrs, nil
+}
+
+func (o *OTLPLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	records := make(chan otlpLogRecord)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	fn := func(dataChan <-chan string, stream string) {
+		defer wg.Done()
+		for line := range dataChan {
+			records <- otlpLogRecord{Time: time.Now(), Body: line, Stream: stream}
+		}
+	}
+	go fn(stdout, "stdout")
+	go fn(stderr, "stderr")
+	go func() {
+		wg.Wait()
+		close(records)
+	}()
+	o.batcher.run(records)
+	return nil
+}
+
+func (o *OTLPLogger) PostProcess() error {
+	if o.exporter == nil {
+		return nil
+	}
+	return o.exporter.Close()
+}
+
+// otlpBatcher accumulates the records and exports them in batches.
+type otlpBatcher struct {
+	exporter     otlpExporter
+	resource     map[string]string
+	timeout      time.Duration
+	batchSize    int
+	batchTimeout time.Duration
+	bufferSize   int
+
+	buffer    []otlpLogRecord
+	backoff   time.Duration
+	nextRetry time.Time
+	dropping  bool
+}
+
+// run exports the records received from the channel until it is closed, then exports the remaining ones.
+func (b *otlpBatcher) run(records <-chan otlpLogRecord) {
+	ticker := time.NewTicker(b.batchTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case r, ok := <-records:
+			if !ok {
+				b.flushFinal()
+				return
+			}
+			b.add(r)
+			if len(b.buffer) >= b.batchSize {
+				b.flush()
+			}
+		case <-ticker.C:
+			b.flush()
+		}
+	}
+}
+
+func (b *otlpBatcher) add(r otlpLogRecord) {
+	b.buffer = append(b.buffer, r)
+	if len(b.buffer) > b.bufferSize {
+		if !b.dropping {
+			log.L.Warnf("otlp buffer is full (%d records), dropping the oldest log records", b.bufferSize)
+			b.dropping = true
+		}
+		b.buffer = b.buffer[len(b.buffer)-b.bufferSize:]
+	}
+}
+
+// flush exports the buffered records, unless a retry is pending.
+func (b *otlpBatcher) flush() {
+	for len(b.buffer) > 0 && !time.Now().Before(b.nextRetry) {
+		if err := b.exportBatch(); err != nil {
+			b.backoff = min(max(2*b.backoff, otlpMinRetryBackoff), otlpMaxRetryBackoff)
+			b.nextRetry = time.Now().Add(b.backoff)
+			log.L.WithError(err).Warnf("failed to export otlp log records, retrying in %s", b.backoff)
+			return
+		}
+		b.backoff = 0
+		b.dropping = false
+	}
+}
+
+// flushFinal exports the remaining records, with a bounded number of attempts.
+func (b *otlpBatcher) flushFinal() {
+	for attempt := 1; len(b.buffer) > 0; attempt++ {
+		err := b.exportBatch()
+		if err == nil {
+			attempt = 0
+			continue
+		}
+		if attempt >= otlpFinalAttempts {
+			log.L.WithError(err).Errorf("failed to export otlp log records, dropping %d records", len(b.buffer))
+			return
+		}
+		time.Sleep(otlpMinRetryBackoff * time.Duration(attempt))
+	}
+}
+
+// exportBatch exports the oldest batch of the buffer, and removes it from the buffer on success
+// or on a permanent error.
+func (b *otlpBatcher) exportBatch() error {
+	n := min(b.batchSize, len(b.buffer))
+	req := encodeOTLPLogsRequest(b.resource, b.buffer[:n])
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	err := b.exporter.Export(ctx, req)
+	var permanent *otlpPermanentError
+	if errors.As(err, &permanent) {
+		log.L.WithError(err).Errorf("otlp endpoint rejected the log records, dropping %d records", n)
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	b.buffer = b.buffer[n:]
+	return nil
+}
+
+// otlpPermanentError is an export error that retrying would not fix.
+type otlpPermanentError struct {
+	err error
+}
+
+func (e *otlpPermanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *otlpPermanentError) Unwrap() error {
+	return e.err
+}
+
+// otlpExporter sends an encoded ExportLogsServiceRequest.
+type otlpExporter interface {
+	Export(ctx context.Context, req []byte) error
+	Close() error
+}
+
+func newOTLPExporter(cfg *otlpConfig) (otlpExporter, error) {
+	if cfg.protocol == otlpProtocolHTTPProtobuf {
+		return &otlpHTTPExporter{
+			url:     cfg.endpoint,
+			headers: cfg.headers,
+			client:  &http.Client{},
+		}, nil
+	}
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(cfg.endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &otlpGRPCExporter{conn: conn, headers: cfg.headers}, nil
+}
+
+type otlpHTTPExporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (e *otlpHTTPExporter) Export(ctx context.Context, req []byte) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("unexpected status %s from %s: %q", resp.Status, e.url, string(body))
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return err
+	default:
+		return &otlpPermanentError{err: err}
+	}
+}
+
+func (e *otlpHTTPExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type otlpGRPCExporter struct {
+	conn    *grpc.ClientConn
+	headers map[string]string
+}
+
+func (e *otlpGRPCExporter) Export(ctx context.Context, req []byte) error {
+	for k, v := range e.headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	var resp []byte
+	err := e.conn.Invoke(ctx, otlpGRPCExportMethod, &req, &resp, grpc.ForceCodec(otlpRawCodec{}))
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return err
+	default:
+		return &otlpPermanentError{err: err}
+	}
+}
+
+func (e *otlpGRPCExporter) Close() error {
+	return e.conn.Close()
+}
+
+// otlpRawCodec passes the messages, encoded by encodeOTLPLogsRequest, as is.
+type otlpRawCodec struct{}
+
+func (otlpRawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (otlpRawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (otlpRawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,152 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseOTLPConfig(t *testing.T) {
+	cfg, err := parseOTLPConfig(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.protocol, otlpProtocolGRPC)
+	assert.Equal(t, cfg.endpoint, otlpDefaultGRPCEndpoint)
+
+	cfg, err = parseOTLPConfig(map[string]string{otlpEndpoint: "http://collector:4317"})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.endpoint, "collector:4317")
+	assert.Assert(t, cfg.insecure)
+
+	cfg, err = parseOTLPConfig(map[string]string{otlpProtocol: otlpProtocolHTTPProtobuf, otlpHeaders: "authorization=Bearer x, x-tenant=foo"})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.endpoint, "http://localhost:4318/v1/logs")
+	assert.DeepEqual(t, cfg.headers, map[string]string{"authorization": "Bearer x", "x-tenant": "foo"})
+
+	for _, opts := range []map[string]string{
+		{otlpProtocol: "http/json"},
+		{otlpProtocol: otlpProtocolHTTPProtobuf, otlpEndpoint: "grpc://localhost:4318"},
+		{otlpHeaders: "foo"},
+		{otlpBatchSize: "0"},
+		{otlpBatchTimeout: "1"},
+		{otlpBatchSize: "100", otlpBufferSize: "10"},
+	} {
+		_, err := parseOTLPConfig(opts)
+		assert.Assert(t, err != nil, "expected an error for %v", opts)
+	}
+}
+
+// decodeOTLPStrings returns the string values of the AnyValue messages found in the encoded message,
+// by walking the nested messages that are known to contain them.
+func decodeOTLPStrings(t *testing.T, b []byte, path ...protowire.Number) []string {
+	var res []string
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		assert.Assert(t, n > 0)
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			assert.Assert(t, n > 0)
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		assert.Assert(t, n > 0)
+		b = b[n:]
+		if num != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			res = append(res, string(v))
+		} else {
+			res = append(res, decodeOTLPStrings(t, v, path[1:]...)...)
+		}
+	}
+	return res
+}
+
+func TestOTLPHTTPExport(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]byte
+		fail     = true
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/v1/logs")
+		assert.Equal(t, r.Header.Get("Content-Type"), "application/x-protobuf")
+		body, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		// the first export fails with a transient error, and has to be retried
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	cfg, err := parseOTLPConfig(map[string]string{otlpProtocol: otlpProtocolHTTPProtobuf, otlpEndpoint: srv.URL, otlpBatchSize: "2"})
+	assert.NilError(t, err)
+	exporter, err := newOTLPExporter(cfg)
+	assert.NilError(t, err)
+	b := &otlpBatcher{
+		exporter:     exporter,
+		resource:     map[string]string{"container.name": "foo"},
+		timeout:      cfg.timeout,
+		batchSize:    cfg.batchSize,
+		batchTimeout: cfg.batchTimeout,
+		bufferSize:   cfg.bufferSize,
+	}
+	records := make(chan otlpLogRecord, 3)
+	for _, line := range []string{"one", "two", "three"} {
+		records <- otlpLogRecord{Time: time.Now(), Body: line, Stream: "stdout"}
+	}
+	close(records)
+	b.run(records)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, len(requests), 2)
+	var bodies []string
+	for _, req := range requests {
+		bodies = append(bodies, decodeOTLPStrings(t, req,
+			otlpFieldResourceLogs, otlpFieldScopeLogs, otlpFieldLogRecords, otlpFieldBody, otlpFieldStringValue)...)
+	}
+	assert.DeepEqual(t, bodies, []string{"one", "two", "three"})
+	keys := decodeOTLPStrings(t, requests[0],
+		otlpFieldResourceLogs, otlpFieldResource, otlpFieldResourceAttributes, otlpFieldKey)
+	assert.DeepEqual(t, keys, []string{"container.name"})
+}
+
+func TestOTLPBufferBound(t *testing.T) {
+	b := &otlpBatcher{bufferSize: 2}
+	for _, line := range []string{"one", "two", "three"} {
+		b.add(otlpLogRecord{Body: line})
+	}
+	assert.Equal(t, len(b.buffer), 2)
+	assert.Equal(t, b.buffer[0].Body, "two")
+}
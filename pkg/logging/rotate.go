@@ -0,0 +1,228 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/fahedouch/go-logrotate"
+)
+
+const compressSuffix = ".gz"
+
+// rotateConfig is the rotation configuration of the drivers writing to local files.
+type rotateConfig struct {
+	maxSize  string
+	maxFile  int
+	compress bool
+}
+
+// newRotateLogger returns a logrotate.Logger writing to filename, configured with
+// the max-size, max-file and compress options, or with the given defaults.
+func newRotateLogger(filename string, opts map[string]string, defaults rotateConfig) (*logrotate.Logger, error) {
+	l := &logrotate.Logger{
+		Filename: filename,
+	}
+	// MaxBytes is the maximum size in bytes of the log file before it gets
+	// rotated. If not set, it defaults to 100 MiB.
+	// see: https://github.com/fahedouch/go-logrotate/blob/6a8beddaea39b2b9c77109d7fa2fe92053c063e5/logrotate.go#L500
+	capacity, ok := opts[MaxSize]
+	if !ok {
+		capacity = defaults.maxSize
+	}
+	if capacity != "" {
+		capVal, err := units.FromHumanSize(capacity)
+		if err != nil {
+			return nil, err
+		}
+		if capVal <= 0 {
+			return nil, fmt.Errorf("max-size must be a positive number")
+		}
+		l.MaxBytes = capVal
+	}
+	maxFile := defaults.maxFile
+	if maxFileString, ok := opts[MaxFile]; ok {
+		var err error
+		maxFile, err = strconv.Atoi(maxFileString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if maxFile < 1 {
+		return nil, fmt.Errorf("max-file cannot be less than 1")
+	}
+	// MaxBackups does not include file to write logs to
+	l.MaxBackups = maxFile - 1
+	compress := defaults.compress
+	if compressString, ok := opts[Compress]; ok {
+		var err error
+		compress, err = strconv.ParseBool(compressString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compress %q: %w", compressString, err)
+		}
+	}
+	if compress && maxFile < 2 {
+		return nil, fmt.Errorf("compress cannot be true when max-file is less than 2")
+	}
+	l.Compress = compress
+	return l, nil
+}
+
+// validateCompressLogOpt checks the compress option against the max-file option.
+func validateCompressLogOpt(logOptMap map[string]string, defaultMaxFile int) error {
+	v, ok := logOptMap[Compress]
+	if !ok {
+		return nil
+	}
+	compress, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid compress %q: %w", v, err)
+	}
+	maxFile := defaultMaxFile
+	if maxFileString, ok := logOptMap[MaxFile]; ok {
+		if maxFile, err = strconv.Atoi(maxFileString); err != nil {
+			return fmt.Errorf("invalid max-file %q: %w", maxFileString, err)
+		}
+	}
+	if compress && maxFile < 2 {
+		return fmt.Errorf("compress cannot be true when max-file is less than 2")
+	}
+	return nil
+}
+
+// rotatedLogFiles returns the files rotated away from logPath by logrotate.Logger,
+// e.g. "container.log.1" or "container.log.2.gz", from the oldest to the most recent.
+func rotatedLogFiles(logPath string) ([]string, error) {
+	dir, base := filepath.Dir(logPath), filepath.Base(logPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type rotated struct {
+		path  string
+		order int
+		mtime int64
+	}
+	names := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = struct{}{}
+	}
+	var files []rotated
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		if uncompressed := strings.TrimSuffix(name, compressSuffix); uncompressed != name {
+			if _, ok := names[uncompressed]; ok {
+				// the file is being compressed, the uncompressed file is still complete
+				continue
+			}
+		}
+		order, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, base+"."), compressSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// the file was removed by a concurrent rotation
+			continue
+		}
+		files = append(files, rotated{path: filepath.Join(dir, name), order: order, mtime: info.ModTime().UnixNano()})
+	}
+	// The modification time of the backups is set when they are rotated.
+	// The order is reset when the logger is restarted, so it is only used to break ties.
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].mtime != files[j].mtime {
+			return files[i].mtime < files[j].mtime
+		}
+		return files[i].order < files[j].order
+	})
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// readRotatedLogFile returns the content of a rotated log file, decompressing it if needed.
+func readRotatedLogFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, compressSuffix) {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress log file %q: %w", path, err)
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// tailFunc returns the offset of the last n entries of r, and the number of entries found after that offset.
+type tailFunc func(r io.ReadSeeker, n uint) (int64, uint, error)
+
+// readRotatedLogs returns a reader over the content of the rotated log files of logPath, oldest first.
+// If tail is positive, only the last tail entries of the rotated files are returned.
+func readRotatedLogs(logPath string, tail uint, tailFn tailFunc) (io.Reader, error) {
+	files, err := rotatedLogFiles(logPath)
+	if err != nil {
+		return nil, err
+	}
+	var chunks [][]byte
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := readRotatedLogFile(files[i])
+		if err != nil {
+			if os.IsNotExist(err) {
+				// removed or compressed by a concurrent rotation
+				continue
+			}
+			return nil, err
+		}
+		if tail > 0 {
+			start, n, err := tailFn(bytes.NewReader(data), tail)
+			if err != nil {
+				return nil, fmt.Errorf("failed to tail log file %q: %w", files[i], err)
+			}
+			data = data[start:]
+			if n >= tail {
+				chunks = append(chunks, data)
+				break
+			}
+			tail -= n
+		}
+		chunks = append(chunks, data)
+	}
+	readers := make([]io.Reader, 0, len(chunks))
+	for i := len(chunks) - 1; i >= 0; i-- {
+		readers = append(readers, bytes.NewReader(chunks[i]))
+	}
+	return io.MultiReader(readers...), nil
+}
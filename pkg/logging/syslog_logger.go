@@ -56,6 +56,11 @@ var syslogOpts = []string{
 	syslogTLSSkipVerify,
 	syslogFormat,
 	Tag,
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	Mode,
+	MaxBufferSize,
 }
 
 var syslogFacilities = map[string]syslog.Priority{
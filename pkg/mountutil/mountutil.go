@@ -21,8 +21,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/moby/sys/userns"
 	"github.com/opencontainers/runtime-spec/specs-go"
 
@@ -212,9 +214,47 @@ func handleNamedVolumes(source string, volStore volumestore.VolumeStore) (volume
 	res.Type = Volume
 	res.Source = vol.Mountpoint
 
+	// Volumes created with driver options are mounted on first use
+	if err := MountVolume(vol); err != nil {
+		return res, err
+	}
+
 	return res, nil
 }
 
+// ValidateVolumeOptions validates the options of the local volume driver.
+// "type", "device" and "o" are the fstype, the source and the options of the mount, like Docker.
+// "size" is the size limit of a tmpfs volume, e.g., "100m".
+func ValidateVolumeOptions(opts map[string]string) error {
+	for k := range opts {
+		switch k {
+		case "type", "device", "o", "size":
+		default:
+			return fmt.Errorf("invalid volume option: %q", k)
+		}
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	for _, k := range []string{"type", "device"} {
+		if opts[k] == "" {
+			return fmt.Errorf("missing required volume option: %q", k)
+		}
+	}
+	if slices.Contains(strings.Split(opts["o"], ","), "bind") && !filepath.IsAbs(opts["device"]) {
+		return fmt.Errorf("the device of a bind volume must be an absolute path, got %q", opts["device"])
+	}
+	if size, ok := opts["size"]; ok {
+		if opts["type"] != Tmpfs {
+			return fmt.Errorf("volume option \"size\" is supported only for tmpfs volumes, not %q", opts["type"])
+		}
+		if _, err := units.RAMInBytes(size); err != nil {
+			return fmt.Errorf("invalid volume size %q: %w", size, err)
+		}
+	}
+	return nil
+}
+
 func getVolumeOptions(src string, vType string, rawOpts string) ([]string, []oci.SpecOpts, error) {
 	// always call parseVolumeOptions for bind mount to allow the parser to add some default options
 	var err error
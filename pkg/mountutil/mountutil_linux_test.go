@@ -348,3 +348,34 @@ func TestProcessFlagVAnonymousVolumes(t *testing.T) {
 		})
 	}
 }
+
+func TestVolumeMount(t *testing.T) {
+	tests := []struct {
+		name string
+		opts map[string]string
+		want mount.Mount
+	}{
+		{
+			name: "tmpfs",
+			opts: map[string]string{"type": "tmpfs", "device": "tmpfs", "o": "uid=1000", "size": "64m"},
+			want: mount.Mount{Type: "tmpfs", Source: "tmpfs", Options: []string{"uid=1000", "size=64m"}},
+		},
+		{
+			name: "bind",
+			opts: map[string]string{"type": "none", "device": "/srv/data", "o": "bind"},
+			want: mount.Mount{Type: "none", Source: "/srv/data", Options: []string{"bind"}},
+		},
+		{
+			name: "nfs",
+			opts: map[string]string{"type": "nfs", "device": ":/export", "o": "addr=192.168.0.1,rw"},
+			want: mount.Mount{Type: "nfs", Source: ":/export", Options: []string{"addr=192.168.0.1", "rw"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := volumeMount(tt.opts)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -18,6 +18,9 @@ package mountutil
 
 import (
 	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
 
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
@@ -36,3 +39,58 @@ func (mv *MockVolumeStore) CreateWithoutLock(name string, labels []string) (*nat
 
 //nolint:unused
 var mockVolumeStore = &MockVolumeStore{}
+
+func TestValidateVolumeOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts map[string]string
+		err  string
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name: "tmpfs with size",
+			opts: map[string]string{"type": "tmpfs", "device": "tmpfs", "size": "100m"},
+		},
+		{
+			name: "bind",
+			opts: map[string]string{"type": "none", "device": "/srv/data", "o": "bind"},
+		},
+		{
+			name: "unknown option",
+			opts: map[string]string{"type": "tmpfs", "device": "tmpfs", "foo": "bar"},
+			err:  "invalid volume option",
+		},
+		{
+			name: "missing device",
+			opts: map[string]string{"type": "nfs", "o": "addr=192.168.0.1"},
+			err:  `missing required volume option: "device"`,
+		},
+		{
+			name: "relative bind",
+			opts: map[string]string{"type": "none", "device": "data", "o": "bind"},
+			err:  "must be an absolute path",
+		},
+		{
+			name: "size of non-tmpfs",
+			opts: map[string]string{"type": "ext4", "device": "/dev/sdb1", "size": "1g"},
+			err:  "supported only for tmpfs volumes",
+		},
+		{
+			name: "invalid size",
+			opts: map[string]string{"type": "tmpfs", "device": "tmpfs", "size": "big"},
+			err:  "invalid volume size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVolumeOptions(tt.opts)
+			if tt.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mountutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+// MountVolume mounts a volume created with driver options on its mountpoint, unless it is mounted already.
+// It is a no-op for the volumes without driver options, which are plain directories.
+func MountVolume(vol *native.Volume) error {
+	if len(vol.Options) == 0 {
+		return nil
+	}
+	mounted, err := isMountpoint(vol.Mountpoint)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return nil
+	}
+	m, err := volumeMount(vol.Options)
+	if err != nil {
+		return err
+	}
+	log.L.Debugf("mounting volume %q (%+v) on %q", vol.Name, m, vol.Mountpoint)
+	if err := m.Mount(vol.Mountpoint); err != nil {
+		return fmt.Errorf("failed to mount volume %q: %w", vol.Name, err)
+	}
+	return nil
+}
+
+// UnmountVolume unmounts a volume created with driver options.
+// It is a no-op for the volumes without driver options, and for the volumes that are not mounted.
+func UnmountVolume(vol *native.Volume) error {
+	if len(vol.Options) == 0 {
+		return nil
+	}
+	if err := mount.UnmountAll(vol.Mountpoint, 0); err != nil {
+		return fmt.Errorf("failed to unmount volume %q: %w", vol.Name, err)
+	}
+	return nil
+}
+
+// volumeMount returns the mount of a volume created with `-o type=<type> -o device=<device> -o o=<options>`.
+func volumeMount(opts map[string]string) (mount.Mount, error) {
+	m := mount.Mount{
+		Type:   opts["type"],
+		Source: opts["device"],
+	}
+	if o := opts["o"]; o != "" {
+		m.Options = strings.Split(o, ",")
+	}
+	if size, ok := opts["size"]; ok {
+		sizeBytes, err := units.RAMInBytes(size)
+		if err != nil {
+			return m, fmt.Errorf("invalid volume size %q: %w", size, err)
+		}
+		m.Options = append(m.Options, getTmpfsSize(sizeBytes))
+	}
+	return m, nil
+}
+
+func isMountpoint(dir string) (bool, error) {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	mi, err := mount.Lookup(dir)
+	if err != nil {
+		return false, err
+	}
+	return mi.Mountpoint == dir, nil
+}
@@ -0,0 +1,41 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package mountutil
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+// MountVolume fails for the volumes created with driver options, as they are supported only on Linux.
+func MountVolume(vol *native.Volume) error {
+	if len(vol.Options) == 0 {
+		return nil
+	}
+	return fmt.Errorf("volume driver options are not supported on %s: %w", runtime.GOOS, errdefs.ErrNotImplemented)
+}
+
+// UnmountVolume is a no-op on non-Linux platforms.
+func UnmountVolume(vol *native.Volume) error {
+	return nil
+}
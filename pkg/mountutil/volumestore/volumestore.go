@@ -37,6 +37,9 @@ const (
 	volumeDirBasename  = "volumes"
 	dataDirName        = "_data"
 	volumeJSONFileName = "volume.json"
+
+	// LocalDriver is the only volume driver, like the "local" driver of Docker
+	LocalDriver = "local"
 )
 
 // ErrVolumeStore will wrap all errors here
@@ -48,9 +51,10 @@ type VolumeStore interface {
 	// Get returns an existing volume
 	Get(name string, size bool) (*native.Volume, error)
 	// Create will either return an existing volume, or create a new one
-	// NOTE that different labels will NOT create a new volume if there is one by that name already,
-	// but instead return the existing one with the (possibly different) labels
-	Create(name string, labels []string) (vol *native.Volume, err error)
+	// NOTE that different labels or options will NOT create a new volume if there is one by that name already,
+	// but instead return the existing one with the (possibly different) labels and options
+	// The options are the options of the local driver (see mountutil.ValidateVolumeOptions), and are persisted as-is.
+	Create(name string, labels []string, options map[string]string) (vol *native.Volume, err error)
 	// List returns all existing volumes.
 	// Note that list is expensive as it reads all volumes individual info
 	List(size bool) (map[string]native.Volume, error)
@@ -158,10 +162,10 @@ func (vs *volumeStore) CreateWithoutLock(name string, labels []string) (vol *nat
 		return nil, err
 	}
 
-	return vs.rawCreate(name, labels)
+	return vs.rawCreate(name, labels, nil)
 }
 
-func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume, err error) {
+func (vs *volumeStore) Create(name string, labels []string, options map[string]string) (vol *native.Volume, err error) {
 	defer func() {
 		if err != nil {
 			err = errors.Join(ErrVolumeStore, err)
@@ -173,7 +177,7 @@ func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume,
 	}
 
 	err = vs.Locker.WithLock(func() error {
-		vol, err = vs.rawCreate(name, labels)
+		vol, err = vs.rawCreate(name, labels, options)
 		return err
 	})
 
@@ -322,9 +326,12 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 		return nil, err
 	}
 
+	vo := volumeOpts(content)
 	vol = &native.Volume{
-		Name:   name,
-		Labels: labels(content),
+		Name:    name,
+		Driver:  LocalDriver,
+		Labels:  vo.Labels,
+		Options: vo.Options,
 	}
 
 	vol.Mountpoint, err = vs.manager.Location(name, dataDirName)
@@ -342,14 +349,18 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 	return vol, nil
 }
 
-func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volume, err error) {
+func (vs *volumeStore) rawCreate(name string, labels []string, options map[string]string) (vol *native.Volume, err error) {
 	volOpts := struct {
-		Labels map[string]string `json:"labels"`
+		Labels  map[string]string `json:"labels"`
+		Options map[string]string `json:"options,omitempty"`
 	}{}
 
 	if len(labels) > 0 {
 		volOpts.Labels = strutil.ConvertKVStringsToMap(labels)
 	}
+	if len(options) > 0 {
+		volOpts.Options = options
+	}
 
 	// Failure here must exit, no need to clean-up
 	labelsJSON, err := json.MarshalIndent(volOpts, "", "    ")
@@ -369,8 +380,14 @@ func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volu
 	}
 
 	// At this point, we either have an existing volume, or created a new one successfully
+	content, err := vs.manager.Get(name, volumeJSONFileName)
+	if err != nil {
+		return nil, err
+	}
 	vol = &native.Volume{
-		Name: name,
+		Name:    name,
+		Driver:  LocalDriver,
+		Options: volumeOpts(content).Options,
 	}
 
 	if err = vs.manager.GroupEnsure(name, dataDirName); err != nil {
@@ -385,13 +402,15 @@ func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volu
 }
 
 // Private helpers
-func labels(b []byte) *map[string]string {
-	type volumeOpts struct {
-		Labels *map[string]string `json:"labels,omitempty"`
-	}
-	var vo volumeOpts
+type volumeOptions struct {
+	Labels  *map[string]string `json:"labels,omitempty"`
+	Options map[string]string  `json:"options,omitempty"`
+}
+
+func volumeOpts(b []byte) volumeOptions {
+	var vo volumeOptions
 	if err := json.Unmarshal(b, &vo); err != nil {
-		return nil
+		return volumeOptions{}
 	}
-	return vo.Labels
+	return vo
 }
@@ -0,0 +1,64 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"net"
+	"slices"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/iptable"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// EnsureIsolation installs the iptables rules that isolate the bridge network from the other bridge networks,
+// and from the outside when the network is internal.
+// It is a no-op for the non-bridge networks, and for the networks that were not created by nerdctl.
+func (n *NetworkConfig) EnsureIsolation() error {
+	brName, ipv6, ok := n.isolatedBridge()
+	if !ok {
+		return nil
+	}
+	return rootlessutil.WithDetachedNetNSIfAny(func() error {
+		return iptable.SetupBridgeIsolation(brName, n.Internal(), ipv6)
+	})
+}
+
+func (n *NetworkConfig) removeIsolation() error {
+	brName, ipv6, ok := n.isolatedBridge()
+	if !ok {
+		return nil
+	}
+	return rootlessutil.WithDetachedNetNSIfAny(func() error {
+		return iptable.TeardownBridgeIsolation(brName, ipv6)
+	})
+}
+
+// isolatedBridge returns the name of the bridge interface of the network, and whether the network has IPv6 subnets.
+func (n *NetworkConfig) isolatedBridge() (string, bool, bool) {
+	if n.NerdctlID == nil || len(n.Plugins) == 0 || n.Plugins[0].Network.Type != "bridge" {
+		return "", false, false
+	}
+	var bridge bridgeConfig
+	if err := json.Unmarshal(n.Plugins[0].Bytes, &bridge); err != nil || bridge.BrName == "" {
+		return "", false, false
+	}
+	ipv6 := slices.ContainsFunc(n.subnets(), func(subnet *net.IPNet) bool {
+		return subnet.IP.To4() == nil
+	})
+	return bridge.BrName, ipv6, true
+}
@@ -260,6 +260,15 @@ func (e *CNIEnv) NetworkByNameOrID(key string) (*NetworkConfig, error) {
 	return nil, fmt.Errorf("no such network: %q", key)
 }
 
+// Internal returns whether the network was created with `nerdctl network create --internal`.
+func (n *NetworkConfig) Internal() bool {
+	if n.NerdctlLabels == nil {
+		return false
+	}
+	internal, _ := strconv.ParseBool((*n.NerdctlLabels)[labels.NetworkInternal])
+	return internal
+}
+
 func (e *CNIEnv) filterNetworks(filterf func(*NetworkConfig) bool) ([]*NetworkConfig, error) {
 	networkConfigs, err := e.networkConfigList()
 	if err != nil {
@@ -323,15 +332,26 @@ func (e *CNIEnv) CreateNetwork(opts types.NetworkCreateOptions) (*NetworkConfig,
 		if _, ok := netMap[opts.Name]; ok {
 			return errdefs.ErrAlreadyExists
 		}
+		netLabels := opts.Labels
+		if opts.Internal {
+			if opts.Driver != "bridge" {
+				return fmt.Errorf("internal networks are supported only for the bridge driver, not %q", opts.Driver)
+			}
+			netLabels = append(netLabels, fmt.Sprintf("%s=true", labels.NetworkInternal))
+		}
 		ipam, err := e.generateIPAM(opts.IPAMDriver, opts.Subnets, opts.Gateway, opts.IPRange, opts.IPAMOptions, opts.IPv6)
 		if err != nil {
 			return err
 		}
-		plugins, err := e.generateCNIPlugins(opts.Driver, opts.Name, ipam, opts.Options, opts.IPv6)
+		if opts.Internal {
+			// No default route
+			delete(ipam, "routes")
+		}
+		plugins, err := e.generateCNIPlugins(opts.Driver, opts.Name, ipam, opts.Options, opts.IPv6, opts.Internal)
 		if err != nil {
 			return err
 		}
-		netConf, err = e.generateNetworkConfig(opts.Name, opts.Labels, plugins)
+		netConf, err = e.generateNetworkConfig(opts.Name, netLabels, plugins)
 		if err != nil {
 			return err
 		}
@@ -349,6 +369,9 @@ func (e *CNIEnv) RemoveNetwork(net *NetworkConfig) error {
 		if err := os.RemoveAll(net.File); err != nil {
 			return err
 		}
+		if err := net.removeIsolation(); err != nil {
+			log.L.WithError(err).Warnf("failed to remove the isolation rules of network %q", net.Name)
+		}
 		return net.clean()
 	}
 	return lockutil.WithDirLock(filepath.Join(e.NetconfPath, ".nerdctl.lock"), fn)
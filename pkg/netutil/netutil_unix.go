@@ -21,6 +21,7 @@ package netutil
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os/exec"
@@ -78,6 +79,42 @@ func (n *NetworkConfig) subnets() []*net.IPNet {
 	return subnets
 }
 
+// Gateway returns the first IPv4 gateway of a bridge network using the host-local IPAM driver, or nil.
+// When the gateway is not set in the config, the bridge plugin uses the first address of the subnet.
+func (n *NetworkConfig) Gateway() net.IP {
+	if len(n.Plugins) == 0 || n.Plugins[0].Network.Type != "bridge" {
+		return nil
+	}
+	var bridge bridgeConfig
+	if err := json.Unmarshal(n.Plugins[0].Bytes, &bridge); err != nil {
+		return nil
+	}
+	if bridge.IPAM["type"] != "host-local" {
+		return nil
+	}
+	var ipam hostLocalIPAMConfig
+	if err := mapstructure.Decode(bridge.IPAM, &ipam); err != nil {
+		return nil
+	}
+	for _, irange := range ipam.Ranges {
+		if len(irange) == 0 {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(irange[0].Subnet)
+		if err != nil || subnet.IP.To4() == nil {
+			continue
+		}
+		if gw := net.ParseIP(irange[0].Gateway); gw != nil {
+			return gw
+		}
+		gw := make(net.IP, len(subnet.IP.To4()))
+		copy(gw, subnet.IP.To4())
+		gw[len(gw)-1]++
+		return gw
+	}
+	return nil
+}
+
 func (n *NetworkConfig) clean() error {
 	// Remove the bridge network interface on the host.
 	if len(n.Plugins) > 0 && n.Plugins[0].Network.Type == "bridge" {
@@ -90,7 +127,7 @@ func (n *NetworkConfig) clean() error {
 	return nil
 }
 
-func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]interface{}, opts map[string]string, ipv6 bool) ([]CNIPlugin, error) {
+func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]interface{}, opts map[string]string, ipv6 bool, internal bool) ([]CNIPlugin, error) {
 	var (
 		plugins []CNIPlugin
 		err     error
@@ -99,6 +136,7 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 	case "bridge":
 		mtu := 0
 		iPMasq := true
+		iPMasqSet := false
 		for opt, v := range opts {
 			switch opt {
 			case "mtu", "com.docker.network.driver.mtu":
@@ -111,6 +149,7 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 				if err != nil {
 					return nil, err
 				}
+				iPMasqSet = true
 			default:
 				return nil, fmt.Errorf("unsupported %q network option %q", driver, opt)
 			}
@@ -121,6 +160,12 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		} else {
 			bridge = newBridgePlugin("br-" + networkID(name)[:12])
 		}
+		if internal {
+			if iPMasqSet && iPMasq {
+				return nil, errors.New("ip-masq cannot be enabled for internal networks")
+			}
+			iPMasq = false
+		}
 		bridge.MTU = mtu
 		bridge.IPAM = ipam
 		bridge.IsGW = true
@@ -129,7 +174,12 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		if ipv6 {
 			bridge.Capabilities["ips"] = true
 		}
-		plugins = []CNIPlugin{bridge, newPortMapPlugin(), newFirewallPlugin(), newTuningPlugin()}
+		if internal {
+			// Ports cannot be published on internal networks
+			plugins = []CNIPlugin{bridge, newFirewallPlugin(), newTuningPlugin()}
+		} else {
+			plugins = []CNIPlugin{bridge, newPortMapPlugin(), newFirewallPlugin(), newTuningPlugin()}
+		}
 		if name != DefaultNetworkName {
 			firewallPath := filepath.Join(e.Path, "firewall")
 			ok, err := firewallPluginGEQ110(firewallPath)
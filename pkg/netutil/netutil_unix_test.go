@@ -69,3 +69,22 @@ func TestGuessFirewallPluginVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateCNIPluginsInternal(t *testing.T) {
+	e := &CNIEnv{Path: t.TempDir()}
+	ipam := map[string]interface{}{"type": "host-local"}
+
+	plugins, err := e.generateCNIPlugins("bridge", "foo", ipam, map[string]string{}, false, true)
+	assert.NilError(t, err)
+	var pluginTypes []string
+	for _, p := range plugins {
+		pluginTypes = append(pluginTypes, p.GetPluginType())
+	}
+	assert.DeepEqual(t, pluginTypes, []string{"bridge", "firewall", "tuning"})
+	bridge, ok := plugins[0].(*bridgeConfig)
+	assert.Assert(t, ok)
+	assert.Assert(t, !bridge.IPMasq)
+
+	_, err = e.generateCNIPlugins("bridge", "foo", ipam, map[string]string{"ip-masq": "true"}, false, true)
+	assert.ErrorContains(t, err, "ip-masq cannot be enabled for internal networks")
+}
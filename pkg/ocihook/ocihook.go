@@ -25,9 +25,12 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/containernetworking/cni/libcni"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
 	types100 "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	b4nndclient "github.com/rootless-containers/bypass4netns/pkg/api/daemon/client"
@@ -37,7 +40,9 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/bypass4netnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/dnsserver"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/lockutil"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
@@ -61,7 +66,10 @@ const (
 	NetworkNamespace = labels.Prefix + "network-namespace"
 )
 
-func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetconfPath, bridgeIP string) error {
+// Run handles the OCI hook event read from stdin.
+// nerdctlCmd and nerdctlArgs are the nerdctl executable and its global flags, used to schedule the health checks
+// and to start the embedded DNS server.
+func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetconfPath, bridgeIP, nerdctlCmd string, nerdctlArgs []string) error {
 	if stdin == nil || event == "" || dataStore == "" || cniPath == "" || cniNetconfPath == "" {
 		return errors.New("got insufficient args")
 	}
@@ -117,6 +125,8 @@ func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetcon
 	if err != nil {
 		return err
 	}
+	opts.nerdctlCmd = nerdctlCmd
+	opts.nerdctlArgs = nerdctlArgs
 
 	switch event {
 	case "createRuntime":
@@ -140,6 +150,13 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 	}
 	o.extraHosts = extraHosts
 
+	if o.networkAliases, err = getNetworkAliases(state); err != nil {
+		return nil, err
+	}
+	if o.links, err = getLinks(state); err != nil {
+		return nil, err
+	}
+
 	hs, err := loadSpec(o.state.Bundle)
 	if err != nil {
 		return nil, err
@@ -187,7 +204,9 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 			}
 			cniOpts = append(cniOpts, cni.WithConfListBytes(netw.Bytes))
 			o.cniNames = append(o.cniNames, netstr)
+			o.cniNetworks = append(o.cniNetworks, netw)
 		}
+		o.cniEnv = e
 		o.cni, err = cni.New(cniOpts...)
 		if err != nil {
 			return nil, err
@@ -252,14 +271,20 @@ type handlerOpts struct {
 	rootfs            string
 	ports             []cni.PortMapping
 	cni               cni.CNI
+	cniEnv            *netutil.CNIEnv
 	cniNames          []string
+	cniNetworks       []*netutil.NetworkConfig
 	fullID            string
 	rootlessKitClient rlkclient.Client
 	bypassClient      b4nndclient.Client
-	extraHosts        map[string]string // host:ip
+	extraHosts        map[string]string   // host:ip
+	networkAliases    map[string][]string // network:aliases
+	links             map[string]string   // alias:name
 	containerIP       string
 	containerMAC      string
 	containerIP6      string
+	nerdctlCmd        string
+	nerdctlArgs       []string
 }
 
 // hookSpec is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/containerd/command/oci-hook.go#L59-L64
@@ -299,6 +324,37 @@ func getExtraHosts(state *specs.State) (map[string]string, error) {
 	return hosts, nil
 }
 
+func getNetworkAliases(state *specs.State) (map[string][]string, error) {
+	networkAliasesJSON := state.Annotations[labels.NetworkAliases]
+	if networkAliasesJSON == "" {
+		return nil, nil
+	}
+	var networkAliases map[string][]string
+	if err := json.Unmarshal([]byte(networkAliasesJSON), &networkAliases); err != nil {
+		return nil, err
+	}
+	return networkAliases, nil
+}
+
+func getLinks(state *specs.State) (map[string]string, error) {
+	linksJSON := state.Annotations[labels.Links]
+	if linksJSON == "" {
+		return nil, nil
+	}
+	var links []string
+	if err := json.Unmarshal([]byte(linksJSON), &links); err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]string)
+	for _, link := range links {
+		if name, alias, ok := strings.Cut(link, ":"); ok {
+			aliases[alias] = name
+		}
+	}
+	return aliases, nil
+}
+
 func getNetNSPath(state *specs.State) (string, error) {
 	// If we have a network-namespace annotation we use it over the passed Pid.
 	netNsPath, netNsFound := state.Annotations[NetworkNamespace]
@@ -323,9 +379,18 @@ func getNetNSPath(state *specs.State) (string, error) {
 }
 
 func getPortMapOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
+	ports, err := getPortMappings(opts)
+	if err != nil || len(ports) == 0 {
+		return nil, err
+	}
+	return []cni.NamespaceOpts{cni.WithCapabilityPortMap(ports)}, nil
+}
+
+// getPortMappings returns the port mappings to be passed to the CNI plugins.
+func getPortMappings(opts *handlerOpts) ([]cni.PortMapping, error) {
 	if len(opts.ports) > 0 {
 		if !rootlessutil.IsRootlessChild() {
-			return []cni.NamespaceOpts{cni.WithCapabilityPortMap(opts.ports)}, nil
+			return opts.ports, nil
 		}
 		var (
 			childIP                            net.IP
@@ -361,7 +426,7 @@ func getPortMapOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
 			}
 			ports[i] = p
 		}
-		return []cni.NamespaceOpts{cni.WithCapabilityPortMap(ports)}, nil
+		return ports, nil
 	}
 	return nil, nil
 }
@@ -460,6 +525,9 @@ func applyNetworkSettings(opts *handlerOpts) error {
 		Domainname: opts.state.Annotations[labels.Domainname],
 		ExtraHosts: opts.extraHosts,
 		Name:       opts.state.Annotations[labels.Name],
+		Aliases:    opts.networkAliases,
+		Links:      opts.links,
+		Interfaces: make(map[string]string, len(opts.cniNames)),
 	}
 
 	// When containerd gets bounced, containers that were previously running and that are restarted will go again
@@ -480,6 +548,7 @@ func applyNetworkSettings(opts *handlerOpts) error {
 	cniResRaw := cniRes.Raw()
 	for i, cniName := range opts.cniNames {
 		hsMeta.Networks[cniName] = cniResRaw[i]
+		hsMeta.Interfaces[cniName] = sandboxInterfaceName(cniResRaw[i], i)
 	}
 
 	b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
@@ -543,6 +612,12 @@ func onCreateRuntime(opts *handlerOpts) error {
 		return err
 	}
 
+	if netError == nil {
+		ensureNetworkIsolation(opts)
+		startDNSServer(opts)
+		startHealthCheck(opts)
+	}
+
 	return netError
 }
 
@@ -572,6 +647,11 @@ func onPostStop(opts *handlerOpts) error {
 
 	ctx := context.Background()
 	ns := opts.state.Annotations[labels.Namespace]
+	if opts.state.Annotations[labels.HealthCheck] != "" {
+		if err := healthcheck.RemoveTimer(ctx, ns, opts.state.ID); err != nil {
+			log.L.WithError(err).Warn("failed to remove the healthcheck timer")
+		}
+	}
 	if opts.cni != nil {
 		var err error
 		b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
@@ -616,14 +696,22 @@ func onPostStop(opts *handlerOpts) error {
 		namespaceOpts = append(namespaceOpts, ipAddressOpts...)
 		namespaceOpts = append(namespaceOpts, macAddressOpts...)
 		namespaceOpts = append(namespaceOpts, ip6AddressOpts...)
-		if err := opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...); err != nil {
-			log.L.WithError(err).Errorf("failed to call cni.Remove")
-			return err
-		}
+		namespaceOpts = append(namespaceOpts,
+			cni.WithLabels(map[string]string{
+				"IgnoreUnknown": "1",
+			}),
+			cni.WithArgs("NERDCTL_CNI_DHCP_HOSTNAME", opts.state.Annotations[labels.Hostname]),
+		)
 		hs, err := hostsstore.New(opts.dataStore, ns)
 		if err != nil {
 			return err
 		}
+		// The network namespace is usually gone by now, the plugins then only release what they allocated on the host
+		nsPath, _ := getNetNSPath(opts.state)
+		if err := removeNetworks(ctx, opts, hs, nsPath, namespaceOpts); err != nil {
+			log.L.WithError(err).Errorf("failed to call cni.Remove")
+			return err
+		}
 		if err := hs.Release(opts.state.ID); err != nil {
 			return err
 		}
@@ -640,6 +728,141 @@ func onPostStop(opts *handlerOpts) error {
 	return nil
 }
 
+// sandboxInterfaceName returns the name of the interface created in the network namespace of the container.
+// The name chosen by go-cni ("eth" followed by the index of the network) is returned when the result has none.
+func sandboxInterfaceName(res *types100.Result, index int) string {
+	for _, intf := range res.Interfaces {
+		if intf.Sandbox != "" {
+			return intf.Name
+		}
+	}
+	return fmt.Sprintf("eth%d", index)
+}
+
+// removeNetworks calls CNI DEL for the networks the container is attached to.
+// The attachments recorded in the hostsstore are used rather than the spec annotations, as the latter are
+// not updated when `nerdctl network connect` and `nerdctl network disconnect` modify a running container.
+// nsPath is empty when the network namespace of the container is already gone.
+func removeNetworks(ctx context.Context, opts *handlerOpts, hs hostsstore.Store, nsPath string, namespaceOpts []cni.NamespaceOpts) error {
+	meta, err := hs.GetMeta(opts.state.ID)
+	if err != nil || len(meta.Interfaces) == 0 {
+		// The container was started by a previous version of nerdctl, which did not record the interfaces
+		return opts.cni.Remove(ctx, opts.fullID, nsPath, namespaceOpts...)
+	}
+	ports, err := getPortMappings(opts)
+	if err != nil {
+		return err
+	}
+	cniConfig := libcni.NewCNIConfig([]string{opts.cniEnv.Path}, nil)
+	var errs []error
+	for name, ifName := range meta.Interfaces {
+		netw, err := opts.cniEnv.NetworkByNameOrID(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rt := networkRuntimeConf(opts, name, ifName, nsPath, ports)
+		err = cniConfig.DelNetworkList(ctx, netw.NetworkConfigList, rt)
+		if err != nil && !isCNIAlreadyRemoved(err) {
+			errs = append(errs, fmt.Errorf("failed to remove network %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// networkRuntimeConf returns the runtime configuration of the CNI DEL of a network, with the same arguments as its CNI ADD.
+// The networks of the container spec were added by applyNetworkSettings, with the address and port arguments of the container,
+// while the networks attached by `nerdctl network connect` were added with the hostname argument only.
+func networkRuntimeConf(opts *handlerOpts, name, ifName, nsPath string, ports []cni.PortMapping) *libcni.RuntimeConf {
+	rt := &libcni.RuntimeConf{
+		ContainerID: opts.fullID,
+		NetNS:       nsPath,
+		IfName:      ifName,
+		Args: [][2]string{
+			{"IgnoreUnknown", "1"},
+			{"NERDCTL_CNI_DHCP_HOSTNAME", opts.state.Annotations[labels.Hostname]},
+		},
+	}
+	if !slices.Contains(opts.cniNames, name) {
+		return rt
+	}
+	if opts.containerIP != "" {
+		rt.Args = append(rt.Args, [2]string{"IP", opts.containerIP})
+	}
+	if opts.containerMAC != "" {
+		rt.Args = append(rt.Args, [2]string{"MAC", opts.containerMAC})
+	}
+	capabilityArgs := make(map[string]interface{})
+	if len(ports) > 0 {
+		capabilityArgs["portMappings"] = ports
+	}
+	if opts.containerIP6 != "" {
+		capabilityArgs["ips"] = []string{opts.containerIP6}
+	}
+	if len(capabilityArgs) > 0 {
+		rt.CapabilityArgs = capabilityArgs
+	}
+	return rt
+}
+
+// isCNIAlreadyRemoved returns true if the error of a CNI DEL means that there is nothing left to remove,
+// e.g., the network namespace or a plugin of the network is gone.
+func isCNIAlreadyRemoved(err error) bool {
+	var cniErr *cnitypes.Error
+	if errors.As(err, &cniErr) {
+		return cniErr.Code == cnitypes.ErrUnknownContainer || cniErr.Code == cnitypes.ErrInvalidNetNS
+	}
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// ensureNetworkIsolation (re-)installs the isolation rules of the bridge networks of the container.
+// This has to be done after setting up the CNI networks, as the CNI plugins insert their rules on the top of the FORWARD chain.
+func ensureNetworkIsolation(opts *handlerOpts) {
+	for _, netw := range opts.cniNetworks {
+		if err := netw.EnsureIsolation(); err != nil {
+			log.L.WithError(err).Warnf("failed to isolate network %q", netw.Name)
+		}
+	}
+}
+
+// startDNSServer starts the embedded DNS server if the container is connected to a user-defined network.
+// Failures are not fatal, as the containers can still resolve each other through /etc/hosts.
+func startDNSServer(opts *handlerOpts) {
+	if opts.nerdctlCmd == "" || !slices.ContainsFunc(opts.cniNames, dnsserver.Enabled) {
+		return
+	}
+	if err := dnsserver.EnsureRunning(opts.dataStore, opts.nerdctlCmd, opts.nerdctlArgs); err != nil {
+		log.L.WithError(err).Warn("failed to start the embedded DNS server")
+		if err := fallbackToHostNameservers(opts); err != nil {
+			log.L.WithError(err).Warn("failed to fall back to the nameservers of the host")
+		}
+	}
+}
+
+// startHealthCheck resets the health state of the container, and schedules its health checks.
+// Failures are not fatal, as the container can still run without health checks.
+func startHealthCheck(opts *handlerOpts) {
+	hcLabel := opts.state.Annotations[labels.HealthCheck]
+	if hcLabel == "" {
+		return
+	}
+	if err := healthcheck.ResetHealth(opts.state.Annotations[labels.StateDir]); err != nil {
+		log.L.WithError(err).Warn("failed to reset the health state")
+	}
+	hc, err := healthcheck.ParseLabel(hcLabel)
+	if err != nil {
+		log.L.WithError(err).Warn("failed to parse the healthcheck label")
+		return
+	}
+	if hc.Disabled() || opts.nerdctlCmd == "" {
+		return
+	}
+	ns := opts.state.Annotations[labels.Namespace]
+	if err := healthcheck.CreateTimer(context.Background(), hc, ns, opts.state.ID, opts.nerdctlCmd, opts.nerdctlArgs); err != nil {
+		log.L.WithError(err).Warn("failed to schedule the health checks")
+	}
+}
+
 // writePidFile writes the pid atomically to a file.
 // From https://github.com/containerd/containerd/blob/v1.7.0-rc.2/cmd/ctr/commands/commands.go#L265-L282
 func writePidFile(path string, pid int) error {
@@ -17,11 +17,21 @@
 package ocihook
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
 	"github.com/containerd/containerd/v2/contrib/apparmor"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/apparmorutil"
 	"github.com/containerd/nerdctl/v2/pkg/defaults"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
 func loadAppArmor() {
@@ -37,3 +47,46 @@ func loadAppArmor() {
 		// but the profile was not actually loaded, runc will fail.
 	}
 }
+
+// fallbackToHostNameservers replaces the embedded DNS server in the resolv.conf of the container
+// with the nameservers of the host, when the server cannot be started.
+// The resolv.conf is left untouched when it does not point to the embedded DNS server only, i.e.,
+// when the nameservers were set with --dns, or when the first network of the container is internal,
+// as internal networks must not reach outside through DNS.
+func fallbackToHostNameservers(opts *handlerOpts) error {
+	if len(opts.cniNetworks) == 0 || opts.cniNetworks[0].Internal() {
+		return nil
+	}
+	gateway := opts.cniNetworks[0].Gateway()
+	if gateway == nil {
+		return nil
+	}
+	resolvConfPath := filepath.Join(opts.state.Annotations[labels.StateDir], "resolv.conf")
+	current, err := os.ReadFile(resolvConfPath)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(resolvconf.GetNameservers(current, resolvconf.IP), []string{gateway.String()}) {
+		return nil
+	}
+
+	var nameServers []string
+	if rootlessutil.IsRootlessChild() {
+		if nameServers, err = dnsutil.GetSlirp4netnsDNS(); err != nil {
+			return err
+		}
+	}
+	host, err := resolvconf.Get()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		host = &resolvconf.File{}
+	}
+	if host, err = resolvconf.FilterResolvDNS(host.Content, true); err != nil {
+		return err
+	}
+	nameServers = append(nameServers, resolvconf.GetNameservers(host.Content, resolvconf.IPv4)...)
+	_, err = resolvconf.Build(resolvConfPath, nameServers, resolvconf.GetSearchDomains(current), resolvconf.GetOptions(current))
+	return err
+}
@@ -21,3 +21,7 @@ package ocihook
 func loadAppArmor() {
 	//noop
 }
+
+func fallbackToHostNameservers(opts *handlerOpts) error {
+	return nil
+}
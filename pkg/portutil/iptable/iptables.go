@@ -22,6 +22,36 @@ import (
 	"strings"
 )
 
+const (
+	// Chains used for isolating the bridge networks from each other, like Docker's DOCKER-ISOLATION-STAGE-1 and DOCKER-ISOLATION-STAGE-2.
+	// The first stage matches the packets leaving a bridge for another interface, and the second stage drops them
+	// if the other interface is a bridge too.
+	IsolationStage1Chain = "NERDCTL-ISOLATION-STAGE-1"
+	IsolationStage2Chain = "NERDCTL-ISOLATION-STAGE-2"
+)
+
+// Rule is an iptables rule in the filter table.
+type Rule struct {
+	Chain    string
+	Rulespec []string
+}
+
+// IsolationRules returns the rules that isolate the bridge from the other bridges.
+// When internal is true, the rules drop all the packets between the bridge and the other interfaces,
+// so that the containers in the bridge cannot reach the outside, and cannot be reached from the outside.
+func IsolationRules(bridge string, internal bool) []Rule {
+	if internal {
+		return []Rule{
+			{Chain: IsolationStage1Chain, Rulespec: []string{"-i", bridge, "!", "-o", bridge, "-j", "DROP"}},
+			{Chain: IsolationStage1Chain, Rulespec: []string{"!", "-i", bridge, "-o", bridge, "-j", "DROP"}},
+		}
+	}
+	return []Rule{
+		{Chain: IsolationStage1Chain, Rulespec: []string{"-i", bridge, "!", "-o", bridge, "-j", IsolationStage2Chain}},
+		{Chain: IsolationStage2Chain, Rulespec: []string{"-o", bridge, "-j", "DROP"}},
+	}
+}
+
 // ParseIPTableRules takes a slice of iptables rules as input and returns a slice of
 // uint64 containing the parsed destination port numbers from the rules.
 func ParseIPTableRules(rules []string) []uint64 {
@@ -17,11 +17,19 @@
 package iptable
 
 import (
+	"fmt"
+
 	"github.com/coreos/go-iptables/iptables"
 )
 
-// Chain used for port forwarding rules: https://www.cni.dev/plugins/current/meta/portmap/#dnat
-const cniDnatChain = "CNI-HOSTPORT-DNAT"
+const (
+	// Chain used for port forwarding rules: https://www.cni.dev/plugins/current/meta/portmap/#dnat
+	cniDnatChain = "CNI-HOSTPORT-DNAT"
+
+	filterTable   = "filter"
+	forwardChain  = "FORWARD"
+	isolationJump = "-A " + forwardChain + " -j " + IsolationStage1Chain
+)
 
 func ReadIPTables(table string) ([]string, error) {
 	ipt, err := iptables.New()
@@ -40,3 +48,93 @@ func ReadIPTables(table string) ([]string, error) {
 
 	return rules, nil
 }
+
+func newIPTables(ipv6 bool) ([]*iptables.IPTables, error) {
+	ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+	if err != nil {
+		return nil, err
+	}
+	res := []*iptables.IPTables{ipt}
+	if ipv6 {
+		ip6t, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ip6t)
+	}
+	return res, nil
+}
+
+// ensureIsolationChains creates the isolation chains, and makes sure that the FORWARD chain jumps to them
+// before any other rule, as the CNI plugins insert their own ACCEPT rules on the top of the FORWARD chain.
+func ensureIsolationChains(ipt *iptables.IPTables) error {
+	for _, chain := range []string{IsolationStage1Chain, IsolationStage2Chain} {
+		exists, err := ipt.ChainExists(filterTable, chain)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := ipt.NewChain(filterTable, chain); err != nil {
+				return err
+			}
+		}
+	}
+	rules, err := ipt.List(filterTable, forwardChain)
+	if err != nil {
+		return err
+	}
+	// rules[0] is the policy of the chain, e.g. "-P FORWARD ACCEPT"
+	if len(rules) > 1 && rules[1] == isolationJump {
+		return nil
+	}
+	if err := ipt.DeleteIfExists(filterTable, forwardChain, "-j", IsolationStage1Chain); err != nil {
+		return err
+	}
+	return ipt.Insert(filterTable, forwardChain, 1, "-j", IsolationStage1Chain)
+}
+
+// SetupBridgeIsolation installs the rules returned by IsolationRules.
+// It is idempotent, and has to be called again after the CNI plugins set up the bridge, as they may insert rules
+// in front of the isolation rules.
+func SetupBridgeIsolation(bridge string, internal, ipv6 bool) error {
+	ipts, err := newIPTables(ipv6)
+	if err != nil {
+		return err
+	}
+	for _, ipt := range ipts {
+		if err := ensureIsolationChains(ipt); err != nil {
+			return fmt.Errorf("failed to set up the isolation chains: %w", err)
+		}
+		for _, r := range IsolationRules(bridge, internal) {
+			if err := ipt.AppendUnique(filterTable, r.Chain, r.Rulespec...); err != nil {
+				return fmt.Errorf("failed to add the isolation rule %v: %w", r.Rulespec, err)
+			}
+		}
+	}
+	return nil
+}
+
+// TeardownBridgeIsolation removes the rules installed by SetupBridgeIsolation.
+func TeardownBridgeIsolation(bridge string, ipv6 bool) error {
+	ipts, err := newIPTables(ipv6)
+	if err != nil {
+		return err
+	}
+	for _, ipt := range ipts {
+		for _, internal := range []bool{false, true} {
+			for _, r := range IsolationRules(bridge, internal) {
+				exists, err := ipt.ChainExists(filterTable, r.Chain)
+				if err != nil {
+					return err
+				}
+				if !exists {
+					continue
+				}
+				if err := ipt.DeleteIfExists(filterTable, r.Chain, r.Rulespec...); err != nil {
+					return fmt.Errorf("failed to remove the isolation rule %v: %w", r.Rulespec, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -17,6 +17,7 @@
 package iptable
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -58,6 +59,39 @@ func TestParseIPTableRules(t *testing.T) {
 	}
 }
 
+func TestIsolationRules(t *testing.T) {
+	testCases := []struct {
+		name     string
+		internal bool
+		want     []Rule
+	}{
+		{
+			name: "Isolated bridge",
+			want: []Rule{
+				{Chain: IsolationStage1Chain, Rulespec: []string{"-i", "br-test", "!", "-o", "br-test", "-j", IsolationStage2Chain}},
+				{Chain: IsolationStage2Chain, Rulespec: []string{"-o", "br-test", "-j", "DROP"}},
+			},
+		},
+		{
+			name:     "Internal bridge",
+			internal: true,
+			want: []Rule{
+				{Chain: IsolationStage1Chain, Rulespec: []string{"-i", "br-test", "!", "-o", "br-test", "-j", "DROP"}},
+				{Chain: IsolationStage1Chain, Rulespec: []string{"!", "-i", "br-test", "-o", "br-test", "-j", "DROP"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IsolationRules("br-test", tc.internal)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("IsolationRules(%q, %v) = %v; want %v", "br-test", tc.internal, got, tc.want)
+			}
+		})
+	}
+}
+
 func equal(a, b []uint64) bool {
 	if len(a) != len(b) {
 		return false
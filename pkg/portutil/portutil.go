@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/docker/go-connections/nat"
@@ -129,6 +131,50 @@ func ParseFlagP(s string) ([]cni.PortMapping, error) {
 	return mr, nil
 }
 
+// ParseFlagExpose parses a port or a range of ports to expose, like "80", "8000-8010/udp",
+// and returns them as "<port>/<proto>".
+func ParseFlagExpose(s string) ([]string, error) {
+	portRange, proto, ok := strings.Cut(s, "/")
+	if !ok {
+		proto = "tcp"
+	}
+	proto = strings.ToLower(proto)
+	switch proto {
+	case "tcp", "udp", "sctp":
+	default:
+		return nil, fmt.Errorf("invalid protocol %q", proto)
+	}
+	startPort, endPort, err := nat.ParsePortRange(portRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", s, err)
+	}
+	ports := make([]string, 0, endPort-startPort+1)
+	for port := startPort; port <= endPort; port++ {
+		ports = append(ports, fmt.Sprintf("%d/%s", port, proto))
+	}
+	return ports, nil
+}
+
+// PublishAll returns the published port mappings, completed with the exposed ports ("<port>/<proto>")
+// that are not published yet, mapped onto free host ports.
+func PublishAll(exposed []string, published []cni.PortMapping) ([]cni.PortMapping, error) {
+	res := slices.Clone(published)
+	for _, e := range exposed {
+		port, proto, _ := strings.Cut(e, "/")
+		if slices.ContainsFunc(published, func(pm cni.PortMapping) bool {
+			return strconv.Itoa(int(pm.ContainerPort)) == port && pm.Protocol == proto
+		}) {
+			continue
+		}
+		pm, err := ParseFlagP(e)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pm...)
+	}
+	return res, nil
+}
+
 // ParsePortsLabel parses JSON-marshalled string from label map
 // (under `labels.Ports` key) and returns []cni.PortMapping.
 func ParsePortsLabel(labelMap map[string]string) ([]cni.PortMapping, error) {
@@ -456,3 +456,44 @@ func TestParseFlagP(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlagExpose(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{s: "80", want: []string{"80/tcp"}},
+		{s: "53/UDP", want: []string{"53/udp"}},
+		{s: "8000-8002/sctp", want: []string{"8000/sctp", "8001/sctp", "8002/sctp"}},
+		{s: "80/icmp", wantErr: true},
+		{s: "http", wantErr: true},
+		{s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseFlagExpose(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFlagExpose() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFlagExpose() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishAllAlreadyPublished(t *testing.T) {
+	published := []cni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "0.0.0.0"},
+	}
+	got, err := PublishAll([]string{"80/tcp", "53/udp"}, published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, published) {
+		t.Errorf("PublishAll() = %v, want %v", got, published)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package restartsupervisor implements the Docker-compatible restart policies of containers
+// supervised by nerdctl rather than by the containerd restart monitor.
+// The policy is stored in the "nerdctl/restart-policy" container label, while the restart state
+// (restart count, backoff) is stored in the container state directory.
+// Containers are restarted by `nerdctl system restart-supervisor`, which watches task exit events.
+package restartsupervisor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy names, compatible with Docker.
+const (
+	PolicyNo            = "no"
+	PolicyAlways        = "always"
+	PolicyOnFailure     = "on-failure"
+	PolicyUnlessStopped = "unless-stopped"
+)
+
+// EventTopic is the topic of the containerd event published when a container is restarted by the supervisor.
+// The event is a ContainerUpdate carrying the new restart count in the "restart_count" label.
+const EventTopic = "/nerdctl/container/restart"
+
+// RestartCountEventLabel is the label of the restart event carrying the restart count.
+const RestartCountEventLabel = "restart_count"
+
+// Backoff parameters, compatible with Docker.
+const (
+	// MinBackoff is the delay before the first restart
+	MinBackoff = 100 * time.Millisecond
+	// MaxBackoff is the maximum delay between two restarts
+	MaxBackoff = time.Minute
+	// ResetBackoffAfter is the execution duration after which the backoff is reset
+	ResetBackoffAfter = 10 * time.Second
+)
+
+// Policy is a restart policy.
+type Policy struct {
+	Name string
+	// MaximumRetryCount is the maximum number of restarts for the "on-failure" policy, 0 means unlimited
+	MaximumRetryCount int
+}
+
+// ParsePolicy parses a restart policy such as "always" or "on-failure:3".
+func ParsePolicy(s string) (*Policy, error) {
+	name, count, hasCount := strings.Cut(s, ":")
+	p := &Policy{Name: name}
+	switch name {
+	case "", PolicyNo:
+		p.Name = PolicyNo
+	case PolicyAlways, PolicyUnlessStopped:
+	case PolicyOnFailure:
+		if hasCount {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid maximum retry count %q for restart policy %q", count, name)
+			}
+			p.MaximumRetryCount = n
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("invalid restart policy %q", s)
+	}
+	if hasCount {
+		return nil, fmt.Errorf("maximum retry count cannot be used with restart policy %q", name)
+	}
+	return p, nil
+}
+
+// String returns the policy in the format accepted by ParsePolicy.
+func (p *Policy) String() string {
+	if p.Name == PolicyOnFailure && p.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", p.Name, p.MaximumRetryCount)
+	}
+	return p.Name
+}
+
+// ShouldRestart returns true if a container which init process exited with exitCode has to be restarted.
+// explicitlyStopped is true when the container was stopped by the user, in which case it is never restarted.
+func (p *Policy) ShouldRestart(exitCode uint32, explicitlyStopped bool, restartCount int) bool {
+	if explicitlyStopped {
+		return false
+	}
+	switch p.Name {
+	case PolicyAlways, PolicyUnlessStopped:
+		return true
+	case PolicyOnFailure:
+		return exitCode != 0 && (p.MaximumRetryCount == 0 || restartCount < p.MaximumRetryCount)
+	default:
+		return false
+	}
+}
+
+// ShouldStartOnReconcile returns true if a stopped container has to be started when the supervisor starts,
+// which corresponds to Docker starting containers when the daemon starts.
+// Unlike "unless-stopped", "always" starts the container even if it was explicitly stopped.
+func (p *Policy) ShouldStartOnReconcile(exitCode uint32, explicitlyStopped bool, restartCount int) bool {
+	if p.Name == PolicyAlways {
+		return true
+	}
+	return p.ShouldRestart(exitCode, explicitlyStopped, restartCount)
+}
+
+// NextBackoff returns the delay to wait before restarting a container that ran for ranFor,
+// given the delay applied before its previous restart.
+func NextBackoff(previous, ranFor time.Duration) time.Duration {
+	if ranFor >= ResetBackoffAfter {
+		previous = 0
+	}
+	next := previous * 2
+	if previous == 0 {
+		next = MinBackoff
+	}
+	if next > MaxBackoff {
+		next = MaxBackoff
+	}
+	return next
+}
@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package restartsupervisor
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("on-failure:3")
+	assert.NilError(t, err)
+	assert.Equal(t, p.Name, PolicyOnFailure)
+	assert.Equal(t, p.MaximumRetryCount, 3)
+	assert.Equal(t, p.String(), "on-failure:3")
+
+	p, err = ParsePolicy("")
+	assert.NilError(t, err)
+	assert.Equal(t, p.Name, PolicyNo)
+
+	_, err = ParsePolicy("on-failure:foo")
+	assert.ErrorContains(t, err, "invalid maximum retry count")
+	_, err = ParsePolicy("always:3")
+	assert.ErrorContains(t, err, "cannot be used")
+	_, err = ParsePolicy("sometimes")
+	assert.ErrorContains(t, err, "invalid restart policy")
+}
+
+func TestShouldRestart(t *testing.T) {
+	onFailure := &Policy{Name: PolicyOnFailure, MaximumRetryCount: 2}
+	assert.Assert(t, !onFailure.ShouldRestart(0, false, 0))
+	assert.Assert(t, onFailure.ShouldRestart(1, false, 1))
+	assert.Assert(t, !onFailure.ShouldRestart(1, false, 2))
+	assert.Assert(t, !onFailure.ShouldRestart(1, true, 0))
+
+	always := &Policy{Name: PolicyAlways}
+	unlessStopped := &Policy{Name: PolicyUnlessStopped}
+	assert.Assert(t, always.ShouldRestart(0, false, 100))
+	assert.Assert(t, !always.ShouldRestart(0, true, 0))
+	assert.Assert(t, always.ShouldStartOnReconcile(0, true, 0))
+	assert.Assert(t, !unlessStopped.ShouldStartOnReconcile(0, true, 0))
+	assert.Assert(t, unlessStopped.ShouldStartOnReconcile(0, false, 0))
+	assert.Assert(t, !(&Policy{Name: PolicyNo}).ShouldRestart(1, false, 0))
+}
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, NextBackoff(0, 0), MinBackoff)
+	assert.Equal(t, NextBackoff(MinBackoff, time.Second), 2*MinBackoff)
+	assert.Equal(t, NextBackoff(40*time.Second, time.Second), MaxBackoff)
+	assert.Equal(t, NextBackoff(MaxBackoff, time.Second), MaxBackoff)
+	assert.Equal(t, NextBackoff(MaxBackoff, ResetBackoffAfter), MinBackoff)
+}
+
+func TestState(t *testing.T) {
+	dir := t.TempDir()
+	state, err := ReadState(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, state.RestartCount, 0)
+
+	err = UpdateState(dir, func(s *State) error {
+		s.RestartCount++
+		s.Backoff = MinBackoff
+		return nil
+	})
+	assert.NilError(t, err)
+	state, err = ReadState(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, state.RestartCount, 1)
+	assert.Equal(t, state.Backoff, MinBackoff)
+
+	assert.NilError(t, ResetState(dir))
+	state, err = ReadState(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, state.RestartCount, 0)
+}
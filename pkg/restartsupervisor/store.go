@@ -0,0 +1,135 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package restartsupervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// stateFile is the name of the file carrying the restart state, relative to the container state dir
+const stateFile = "restart.json"
+
+// ErrRestartStore will wrap all errors here
+var ErrRestartStore = errors.New("restart-store error")
+
+// State is the restart state of a container.
+type State struct {
+	// RestartCount is the number of restarts since the container was last started by the user
+	RestartCount int
+	// Backoff is the delay applied before the last restart
+	Backoff time.Duration
+	// Restarting is true while the supervisor waits for the backoff before restarting the container
+	Restarting bool
+	// StartedAt is the time of the last restart
+	StartedAt time.Time `json:",omitempty"`
+}
+
+// ReadState returns the restart state stored in the container state dir.
+func ReadState(stateDir string) (state *State, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrRestartStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	state = &State{}
+	err = st.WithLock(func() error {
+		return load(st, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ContainerState returns the restart state of the container which labels are passed as argument.
+// nil is returned if the container is not supervised by nerdctl.
+func ContainerState(containerLabels map[string]string) (*State, error) {
+	if containerLabels[labels.RestartPolicy] == "" || containerLabels[labels.StateDir] == "" {
+		return nil, nil
+	}
+	return ReadState(containerLabels[labels.StateDir])
+}
+
+// UpdateState atomically applies fun to the restart state stored in the container state dir.
+func UpdateState(stateDir string, fun func(*State) error) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrRestartStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return err
+	}
+	return st.WithLock(func() error {
+		state := &State{}
+		if err := load(st, state); err != nil {
+			return err
+		}
+		if err := fun(state); err != nil {
+			return err
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return st.Set(data, stateFile)
+	})
+}
+
+// ResetState removes the restart state from the container state dir.
+// It is called every time the container is started by the user.
+func ResetState(stateDir string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrRestartStore, err)
+		}
+	}()
+
+	st, err := store.New(stateDir, 0, 0)
+	if err != nil {
+		return err
+	}
+	return st.WithLock(func() error {
+		if err := st.Delete(stateFile); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		return nil
+	})
+}
+
+func load(st store.Store, state *State) error {
+	data, err := st.Get(stateFile)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, state)
+}
@@ -0,0 +1,149 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package statsutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format written by WritePrometheus.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type prometheusMetric struct {
+	name  string
+	help  string
+	typ   string
+	value func(*StatsEntry) float64
+}
+
+var prometheusMetrics = []prometheusMetric{
+	{
+		name:  "nerdctl_container_cpu_usage_seconds_total",
+		help:  "Cumulative CPU time consumed by the container in seconds.",
+		typ:   "counter",
+		value: func(e *StatsEntry) float64 { return e.CPUUsage },
+	},
+	{
+		name:  "nerdctl_container_cpu_usage_percent",
+		help:  "CPU usage of the container in percent, as shown by nerdctl stats.",
+		typ:   "gauge",
+		value: func(e *StatsEntry) float64 { return e.CPUPercentage },
+	},
+	{
+		name:  "nerdctl_container_memory_usage_bytes",
+		help:  "Memory usage of the container in bytes, excluding the inactive file cache.",
+		typ:   "gauge",
+		value: func(e *StatsEntry) float64 { return e.Memory },
+	},
+	{
+		name:  "nerdctl_container_memory_limit_bytes",
+		help:  "Memory limit of the container in bytes.",
+		typ:   "gauge",
+		value: func(e *StatsEntry) float64 { return e.MemoryLimit },
+	},
+	{
+		name:  "nerdctl_container_memory_usage_percent",
+		help:  "Memory usage of the container in percent of its limit.",
+		typ:   "gauge",
+		value: func(e *StatsEntry) float64 { return e.MemoryPercentage },
+	},
+	{
+		name:  "nerdctl_container_network_receive_bytes_total",
+		help:  "Cumulative count of bytes received by the container.",
+		typ:   "counter",
+		value: func(e *StatsEntry) float64 { return e.NetworkRx },
+	},
+	{
+		name:  "nerdctl_container_network_transmit_bytes_total",
+		help:  "Cumulative count of bytes transmitted by the container.",
+		typ:   "counter",
+		value: func(e *StatsEntry) float64 { return e.NetworkTx },
+	},
+	{
+		name:  "nerdctl_container_blkio_read_bytes_total",
+		help:  "Cumulative count of bytes read from block devices by the container.",
+		typ:   "counter",
+		value: func(e *StatsEntry) float64 { return e.BlockRead },
+	},
+	{
+		name:  "nerdctl_container_blkio_write_bytes_total",
+		help:  "Cumulative count of bytes written to block devices by the container.",
+		typ:   "counter",
+		value: func(e *StatsEntry) float64 { return e.BlockWrite },
+	},
+	{
+		name:  "nerdctl_container_pids",
+		help:  "Number of processes running in the container.",
+		typ:   "gauge",
+		value: func(e *StatsEntry) float64 { return float64(e.PidsCurrent) },
+	},
+}
+
+// WritePrometheus writes the statistics of the containers in the Prometheus text exposition format.
+// The samples are labeled with the name, ID, image, namespace and compose project/service of the containers.
+// Containers which statistics are not valid (e.g., not collected yet) are skipped.
+func WritePrometheus(w io.Writer, stats []*Stats) error {
+	type sample struct {
+		entry  StatsEntry
+		labels string
+	}
+	var samples []sample
+	for _, s := range stats {
+		entry := s.GetStatistics()
+		if entry.ID == "" || entry.IsInvalid {
+			continue
+		}
+		samples = append(samples, sample{entry: entry, labels: prometheusLabels(&entry, s.Metadata)})
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, m := range prometheusMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, m.typ)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s{%s} %s\n", m.name, s.labels, strconv.FormatFloat(m.value(&s.entry), 'g', -1, 64))
+		}
+	}
+	return bw.Flush()
+}
+
+func prometheusLabels(entry *StatsEntry, meta Metadata) string {
+	labels := [][2]string{
+		{"name", entry.Name},
+		{"id", entry.ID},
+		{"image", meta.Image},
+		{"namespace", meta.Namespace},
+		{"compose_project", meta.ComposeProject},
+		{"compose_service", meta.ComposeService},
+	}
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		pairs[i] = l[0] + `="` + escapePrometheusLabelValue(l[1]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapePrometheusLabelValue escapes backslashes, double quotes and line feeds, as required by the text exposition format.
+func escapePrometheusLabelValue(s string) string {
+	return prometheusLabelValueReplacer.Replace(s)
+}
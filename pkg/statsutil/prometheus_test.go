@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package statsutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	s := NewStats("abcdef0123456789", "web-1")
+	s.Metadata = Metadata{
+		Namespace:      "default",
+		Image:          "docker.io/library/nginx:alpine",
+		ComposeProject: "proj",
+		ComposeService: "web",
+	}
+	s.SetStatistics(StatsEntry{
+		CPUPercentage:    12.5,
+		CPUUsage:         3.25,
+		Memory:           1024,
+		MemoryLimit:      4096,
+		MemoryPercentage: 25,
+		NetworkRx:        100,
+		NetworkTx:        200,
+		BlockRead:        300,
+		BlockWrite:       400,
+		PidsCurrent:      3,
+	})
+	odd := NewStats("0123456789abcdef", `we"ird\name`)
+	odd.SetStatistics(StatsEntry{PidsCurrent: 1})
+	invalid := NewStats("fedcba9876543210", "invalid")
+	invalid.SetErrorAndReset(errors.New("timeout waiting for stats"))
+
+	var buf bytes.Buffer
+	assert.NilError(t, WritePrometheus(&buf, []*Stats{s, odd, invalid}))
+	out := buf.String()
+
+	labels := `{name="web-1",id="abcdef0123456789",image="docker.io/library/nginx:alpine",namespace="default",compose_project="proj",compose_service="web"}`
+	for _, line := range []string{
+		"# TYPE nerdctl_container_cpu_usage_seconds_total counter",
+		"nerdctl_container_cpu_usage_seconds_total" + labels + " 3.25",
+		"# TYPE nerdctl_container_memory_usage_bytes gauge",
+		"nerdctl_container_memory_usage_bytes" + labels + " 1024",
+		"nerdctl_container_memory_limit_bytes" + labels + " 4096",
+		"nerdctl_container_network_receive_bytes_total" + labels + " 100",
+		"nerdctl_container_blkio_write_bytes_total" + labels + " 400",
+		"nerdctl_container_pids" + labels + " 3",
+		`nerdctl_container_pids{name="we\"ird\\name",id="0123456789abcdef",image="",namespace="",compose_project="",compose_service=""} 1`,
+	} {
+		assert.Assert(t, strings.Contains(out, line+"\n"), "missing %q in:\n%s", line, out)
+	}
+	assert.Assert(t, !strings.Contains(out, "invalid"))
+	assert.Equal(t, strings.Count(out, "# HELP "), len(prometheusMetrics))
+}
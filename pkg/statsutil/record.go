@@ -0,0 +1,356 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package statsutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	units "github.com/docker/go-units"
+)
+
+// Formats of the files written by `nerdctl stats --record`.
+const (
+	RecordFormatJSONL = "jsonl"
+	RecordFormatCSV   = "csv"
+)
+
+// Sample is a StatsEntry recorded at a given time.
+type Sample struct {
+	Time time.Time
+	StatsEntry
+}
+
+var csvHeader = []string{
+	"Time", "ID", "Name", "CPUPercentage", "CPUUsage", "Memory", "MemoryLimit", "MemoryPercentage",
+	"NetworkRx", "NetworkTx", "BlockRead", "BlockWrite", "PidsCurrent",
+}
+
+// Recorder writes samples to a JSONL or CSV stream.
+type Recorder struct {
+	w      io.Writer
+	csv    *csv.Writer
+	format string
+}
+
+// NewRecorder returns a Recorder writing samples to w in the given format.
+// The CSV header is written immediately.
+func NewRecorder(w io.Writer, format string) (*Recorder, error) {
+	r := &Recorder{w: w, format: format}
+	switch format {
+	case RecordFormatJSONL:
+	case RecordFormatCSV:
+		r.csv = csv.NewWriter(w)
+		if err := r.csv.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		r.csv.Flush()
+		if err := r.csv.Error(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record format %q, must be %q or %q", format, RecordFormatJSONL, RecordFormatCSV)
+	}
+	return r, nil
+}
+
+// Record writes a sample for each valid entry, all recorded at t.
+func (r *Recorder) Record(t time.Time, entries []StatsEntry) error {
+	for _, e := range entries {
+		if e.ID == "" || e.IsInvalid {
+			continue
+		}
+		s := Sample{Time: t, StatsEntry: e}
+		if r.csv != nil {
+			if err := r.csv.Write(s.csvRecord()); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if _, err := r.w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	if r.csv != nil {
+		r.csv.Flush()
+		return r.csv.Error()
+	}
+	return nil
+}
+
+func (s *Sample) csvRecord() []string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return []string{
+		s.Time.Format(time.RFC3339Nano), s.ID, s.Name, f(s.CPUPercentage), f(s.CPUUsage), f(s.Memory), f(s.MemoryLimit), f(s.MemoryPercentage),
+		f(s.NetworkRx), f(s.NetworkTx), f(s.BlockRead), f(s.BlockWrite), strconv.FormatUint(s.PidsCurrent, 10),
+	}
+}
+
+// ReadSamples reads the samples written by a Recorder. The format is detected from the content.
+func ReadSamples(r io.Reader) ([]Sample, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(1)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if first[0] == '{' {
+		return readJSONLSamples(br)
+	}
+	return readCSVSamples(br)
+}
+
+func readJSONLSamples(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		var s Sample
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, fmt.Errorf("invalid sample at line %d: %w", line, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+func readCSVSamples(r io.Reader) ([]Sample, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(header, csvHeader) {
+		return nil, fmt.Errorf("invalid CSV header %v, expected %v", header, csvHeader)
+	}
+	var samples []Sample
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		s, err := parseCSVSample(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample at line %d: %w", line, err)
+		}
+		samples = append(samples, s)
+	}
+}
+
+func parseCSVSample(record []string) (Sample, error) {
+	var (
+		s   Sample
+		err error
+	)
+	if s.Time, err = time.Parse(time.RFC3339Nano, record[0]); err != nil {
+		return s, err
+	}
+	s.ID, s.Name = record[1], record[2]
+	for i, p := range []*float64{
+		&s.CPUPercentage, &s.CPUUsage, &s.Memory, &s.MemoryLimit, &s.MemoryPercentage,
+		&s.NetworkRx, &s.NetworkTx, &s.BlockRead, &s.BlockWrite,
+	} {
+		if *p, err = strconv.ParseFloat(record[3+i], 64); err != nil {
+			return s, err
+		}
+	}
+	if s.PidsCurrent, err = strconv.ParseUint(record[12], 10, 64); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Aggregate summarizes the values of a metric over time.
+type Aggregate struct {
+	Min float64
+	Avg float64
+	P95 float64
+	Max float64
+}
+
+func aggregate(values []float64) Aggregate {
+	if len(values) == 0 {
+		return Aggregate{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	// nearest-rank percentile
+	p95 := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return Aggregate{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		P95: sorted[p95],
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// Summary summarizes the samples recorded for a container.
+type Summary struct {
+	ID      string
+	Name    string
+	Samples int
+	First   time.Time
+	Last    time.Time
+
+	CPUPercentage    Aggregate
+	Memory           Aggregate
+	MemoryPercentage Aggregate
+	// PeakMemoryLimit is the memory limit at the time of the peak memory usage
+	PeakMemoryLimit float64
+	PidsCurrent     Aggregate
+
+	// The I/O totals are the amount of bytes transferred during the recording,
+	// taking into account the counter resets caused by container restarts.
+	NetworkRx  float64
+	NetworkTx  float64
+	BlockRead  float64
+	BlockWrite float64
+}
+
+// Summarize returns the summary of the samples of each container, in the order of their first sample.
+func Summarize(samples []Sample) []Summary {
+	type series struct {
+		summary               Summary
+		cpu, mem, memPct, pid []float64
+		peak                  float64
+		last                  *Sample
+	}
+	var (
+		order []string
+		byID  = make(map[string]*series)
+	)
+	for i := range samples {
+		s := &samples[i]
+		sr, ok := byID[s.ID]
+		if !ok {
+			sr = &series{summary: Summary{ID: s.ID, First: s.Time}}
+			byID[s.ID] = sr
+			order = append(order, s.ID)
+		}
+		sum := &sr.summary
+		sum.Name = s.Name
+		sum.Samples++
+		if s.Time.Before(sum.First) {
+			sum.First = s.Time
+		}
+		if s.Time.After(sum.Last) {
+			sum.Last = s.Time
+		}
+		sr.cpu = append(sr.cpu, s.CPUPercentage)
+		sr.mem = append(sr.mem, s.Memory)
+		sr.memPct = append(sr.memPct, s.MemoryPercentage)
+		sr.pid = append(sr.pid, float64(s.PidsCurrent))
+		if sr.last == nil || s.Memory > sr.peak {
+			sr.peak = s.Memory
+			sum.PeakMemoryLimit = s.MemoryLimit
+		}
+		if sr.last != nil {
+			sum.NetworkRx += counterDelta(sr.last.NetworkRx, s.NetworkRx)
+			sum.NetworkTx += counterDelta(sr.last.NetworkTx, s.NetworkTx)
+			sum.BlockRead += counterDelta(sr.last.BlockRead, s.BlockRead)
+			sum.BlockWrite += counterDelta(sr.last.BlockWrite, s.BlockWrite)
+		}
+		sr.last = s
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, id := range order {
+		sr := byID[id]
+		sr.summary.CPUPercentage = aggregate(sr.cpu)
+		sr.summary.Memory = aggregate(sr.mem)
+		sr.summary.MemoryPercentage = aggregate(sr.memPct)
+		sr.summary.PidsCurrent = aggregate(sr.pid)
+		summaries = append(summaries, sr.summary)
+	}
+	return summaries
+}
+
+// counterDelta returns the increase of a cumulative counter between two samples.
+// A decrease means that the counter was reset, e.g. because the container was restarted.
+func counterDelta(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// FormattedSummary represents a formatted Summary
+type FormattedSummary struct {
+	ID         string
+	Name       string
+	Samples    string
+	Duration   string
+	CPUPerc    string
+	MemUsage   string
+	PeakMemory string
+	NetIO      string
+	BlockIO    string
+	PIDs       string
+}
+
+// RenderSummary renders a FormattedSummary from a Summary.
+func RenderSummary(in *Summary, noTrunc bool) FormattedSummary {
+	entry := StatsEntry{ID: in.ID, Name: in.Name}
+	percents := func(a Aggregate) string {
+		return fmt.Sprintf("%.2f%% / %.2f%% / %.2f%% / %.2f%%", a.Min, a.Avg, a.P95, a.Max)
+	}
+	bytesSizes := func(a Aggregate) string {
+		return fmt.Sprintf("%s / %s / %s / %s", units.BytesSize(a.Min), units.BytesSize(a.Avg), units.BytesSize(a.P95), units.BytesSize(a.Max))
+	}
+	return FormattedSummary{
+		ID:         entry.EntryID(noTrunc),
+		Name:       entry.EntryName(noTrunc),
+		Samples:    strconv.Itoa(in.Samples),
+		Duration:   in.Last.Sub(in.First).Round(time.Second).String(),
+		CPUPerc:    percents(in.CPUPercentage),
+		MemUsage:   bytesSizes(in.Memory),
+		PeakMemory: fmt.Sprintf("%s / %s", units.BytesSize(in.Memory.Max), units.BytesSize(in.PeakMemoryLimit)),
+		NetIO:      fmt.Sprintf("%s / %s", units.HumanSizeWithPrecision(in.NetworkRx, 3), units.HumanSizeWithPrecision(in.NetworkTx, 3)),
+		BlockIO:    fmt.Sprintf("%s / %s", units.HumanSizeWithPrecision(in.BlockRead, 3), units.HumanSizeWithPrecision(in.BlockWrite, 3)),
+		PIDs:       fmt.Sprintf("%.0f", in.PidsCurrent.Max),
+	}
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package statsutil
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRecordAndReadSamples(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []StatsEntry{
+		{ID: "abcdef0123456789", Name: "web", CPUPercentage: 1.5, CPUUsage: 2, Memory: 1024, MemoryLimit: 4096, MemoryPercentage: 25, NetworkRx: 10, NetworkTx: 20, BlockRead: 30, BlockWrite: 40, PidsCurrent: 2},
+		{ID: "fedcba9876543210", Name: "invalid", IsInvalid: true},
+		{ID: "0123456789abcdef", Name: "db, \"primary\"", PidsCurrent: 1},
+	}
+	for _, format := range []string{RecordFormatJSONL, RecordFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			r, err := NewRecorder(&buf, format)
+			assert.NilError(t, err)
+			assert.NilError(t, r.Record(t0, entries))
+			assert.NilError(t, r.Record(t0.Add(time.Second), entries[:1]))
+
+			samples, err := ReadSamples(&buf)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, samples, []Sample{
+				{Time: t0, StatsEntry: entries[0]},
+				{Time: t0, StatsEntry: entries[2]},
+				{Time: t0.Add(time.Second), StatsEntry: entries[0]},
+			})
+		})
+	}
+
+	_, err := NewRecorder(&bytes.Buffer{}, "xml")
+	assert.ErrorContains(t, err, "unsupported record format")
+}
+
+func TestSummarize(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var samples []Sample
+	for i := 0; i < 20; i++ {
+		samples = append(samples, Sample{
+			Time: t0.Add(time.Duration(i) * time.Second),
+			StatsEntry: StatsEntry{
+				ID:            "abcdef0123456789",
+				Name:          "web",
+				CPUPercentage: float64(i + 1),
+				Memory:        float64(100 * (i + 1)),
+				MemoryLimit:   float64(1000 + i),
+				PidsCurrent:   uint64(i % 3),
+				NetworkRx:     float64(10 * i),
+				BlockWrite:    float64(5 * i),
+			},
+		})
+	}
+	// the container restarted, so the counters were reset
+	samples = append(samples, Sample{
+		Time:       t0.Add(20 * time.Second),
+		StatsEntry: StatsEntry{ID: "abcdef0123456789", Name: "web", CPUPercentage: 10, Memory: 50, NetworkRx: 7, BlockWrite: 1},
+	})
+	samples = append(samples, Sample{
+		Time:       t0,
+		StatsEntry: StatsEntry{ID: "0123456789abcdef", Name: "db", CPUPercentage: 3, Memory: 42},
+	})
+
+	summaries := Summarize(samples)
+	assert.Equal(t, len(summaries), 2)
+
+	web := summaries[0]
+	assert.Equal(t, web.ID, "abcdef0123456789")
+	assert.Equal(t, web.Samples, 21)
+	assert.Equal(t, web.Last.Sub(web.First), 20*time.Second)
+	assert.Equal(t, web.CPUPercentage, Aggregate{Min: 1, Avg: 220.0 / 21, P95: 19, Max: 20})
+	assert.Equal(t, web.Memory.Max, 2000.0)
+	assert.Equal(t, web.Memory.Min, 50.0)
+	assert.Equal(t, web.PeakMemoryLimit, 1019.0)
+	assert.Equal(t, web.PidsCurrent.Max, 2.0)
+	assert.Equal(t, web.NetworkRx, 190.0+7)
+	assert.Equal(t, web.BlockWrite, 95.0+1)
+
+	db := summaries[1]
+	assert.Equal(t, db.Samples, 1)
+	assert.Equal(t, db.CPUPercentage, Aggregate{Min: 3, Avg: 3, P95: 3, Max: 3})
+	assert.Equal(t, db.Memory.Max, 42.0)
+
+	rs := RenderSummary(&web, false)
+	assert.Equal(t, rs.ID, "abcdef012345")
+	assert.Equal(t, rs.Duration, "20s")
+	assert.Equal(t, rs.PIDs, "2")
+}
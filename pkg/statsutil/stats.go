@@ -31,6 +31,7 @@ type StatsEntry struct {
 	Name             string
 	ID               string
 	CPUPercentage    float64
+	CPUUsage         float64
 	Memory           float64
 	MemoryLimit      float64
 	MemoryPercentage float64
@@ -54,11 +55,22 @@ type FormattedStatsEntry struct {
 	PIDs     string
 }
 
+// Metadata represents the attributes of a container that are not part of its statistics,
+// such as the labels of the exported metrics
+type Metadata struct {
+	Namespace      string
+	Image          string
+	ComposeProject string
+	ComposeService string
+}
+
 // Stats represents an entity to store containers statistics synchronously
 type Stats struct {
 	mutex sync.RWMutex
 	StatsEntry
-	err error
+	// Metadata is set once when the Stats is created
+	Metadata Metadata
+	err      error
 }
 
 // ContainerStats represents the runtime container stats
@@ -104,6 +116,7 @@ func (cs *Stats) SetErrorAndReset(err error) {
 	cs.mutex.Lock()
 	defer cs.mutex.Unlock()
 	cs.CPUPercentage = 0
+	cs.CPUUsage = 0
 	cs.Memory = 0
 	cs.MemoryPercentage = 0
 	cs.MemoryLimit = 0
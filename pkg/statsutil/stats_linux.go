@@ -40,6 +40,7 @@ func calculateMemPercent(limit float64, usedNo float64) float64 {
 
 func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links []netlink.Link) (StatsEntry, error) {
 	cpuPercent := calculateCgroupCPUPercent(previousStats, data)
+	cpuUsage := float64(data.CPU.Usage.Total) / 1e9
 	blkRead, blkWrite := calculateCgroupBlockIO(data)
 	mem := calculateCgroupMemUsage(data)
 	memLimit := getCgroupMemLimit(float64(data.Memory.Usage.Limit))
@@ -49,6 +50,7 @@ func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links
 
 	return StatsEntry{
 		CPUPercentage:    cpuPercent,
+		CPUUsage:         cpuUsage,
 		Memory:           mem,
 		MemoryPercentage: memPercent,
 		MemoryLimit:      memLimit,
@@ -63,6 +65,7 @@ func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links
 
 func SetCgroup2StatsFields(previousStats *ContainerStats, metrics *v2.Metrics, links []netlink.Link) (StatsEntry, error) {
 	cpuPercent := calculateCgroup2CPUPercent(previousStats, metrics)
+	cpuUsage := float64(metrics.CPU.UsageUsec) / 1e6
 	blkRead, blkWrite := calculateCgroup2IO(metrics)
 	mem := calculateCgroup2MemUsage(metrics)
 	memLimit := getCgroupMemLimit(float64(metrics.Memory.UsageLimit))
@@ -72,6 +75,7 @@ func SetCgroup2StatsFields(previousStats *ContainerStats, metrics *v2.Metrics, l
 
 	return StatsEntry{
 		CPUPercentage:    cpuPercent,
+		CPUUsage:         cpuUsage,
 		Memory:           mem,
 		MemoryPercentage: memPercent,
 		MemoryLimit:      memLimit,